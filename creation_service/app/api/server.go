@@ -1,9 +1,12 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
+	"strings"
 
 	"brainbot/creation_service/app"
 	"brainbot/creation_service/app/services"
@@ -60,24 +63,74 @@ func (s *Server) HandleProcessVideo(w http.ResponseWriter, r *http.Request) {
 
 	log.Printf("Received video processing request: UUID=%s", req.UUID)
 
-	// Process video asynchronously (non-blocking for API response)
-	go func() {
-		if err := s.processor.ProcessVideoInput(req.VideoInput, false); err != nil {
-			log.Printf("Video processing failed for UUID %s: %v", req.UUID, err)
+	// A UUID that already went through the pipeline is not processed again
+	if record, ok := s.processor.Completed(req.UUID); ok {
+		respondWithSuccess(w, "Video already processed", record.VideoID)
+		return
+	}
+
+	// Queue the video (non-blocking for API response); workers log failures.
+	// The video outlives the request, so it isn't cancelled when the response is sent.
+	if err := s.processor.Submit(context.WithoutCancel(r.Context()), req.VideoInput, false); err != nil {
+		switch {
+		case errors.Is(err, services.ErrQueueFull):
+			w.Header().Set("Retry-After", "30")
+			respondWithError(w, http.StatusTooManyRequests, "Video queue is full, retry later", err)
+		case errors.Is(err, services.ErrShuttingDown):
+			respondWithError(w, http.StatusServiceUnavailable, "Service is shutting down", err)
+		case errors.Is(err, services.ErrVideoInProgress):
+			respondWithError(w, http.StatusConflict, "Video is already queued or processing", err)
+		default:
+			respondWithError(w, http.StatusInternalServerError, "Failed to queue video", err)
 		}
-	}()
+		return
+	}
 
-	// Return immediate success response
-	respondWithSuccess(w, "Video processing started", "")
+	// Return immediate success response; progress is at /api/jobs/:uuid
+	respondWithSuccess(w, "Video processing queued", "")
 }
 
-// HandleHealth provides a health check endpoint
+// HandleGetJob reports the status of a video job
+// GET /api/jobs/:uuid
+// Returns: Job JSON
+func (s *Server) HandleGetJob(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	uuid := strings.TrimPrefix(r.URL.Path, "/api/jobs/")
+	if uuid == "" || strings.Contains(uuid, "/") {
+		respondWithError(w, http.StatusBadRequest, "UUID is required", nil)
+		return
+	}
+
+	job, ok := s.processor.Job(uuid)
+	if !ok {
+		respondWithError(w, http.StatusNotFound, "Job not found", nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(job)
+}
+
+// HandleHealth provides a health check endpoint with the video queue's load.
+// It reports 503 while the service drains for shutdown.
 // GET /health
 func (s *Server) HandleHealth(w http.ResponseWriter, r *http.Request) {
+	stats := s.processor.Stats()
+	status, code := "healthy", http.StatusOK
+	if stats.Draining {
+		status, code = "shutting_down", http.StatusServiceUnavailable
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{
-		"status": "healthy",
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": status,
+		"queue":  stats,
 	})
 }
 
@@ -119,6 +172,7 @@ func (s *Server) SetupRoutes() *http.ServeMux {
 
 	mux.HandleFunc("/health", s.HandleHealth)
 	mux.HandleFunc("/api/process-video", s.HandleProcessVideo)
+	mux.HandleFunc("/api/jobs/", s.HandleGetJob)
 
 	return mux
 }
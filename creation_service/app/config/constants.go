@@ -17,10 +17,17 @@ const (
 	BackgroundsDir = "backgroundvids"
 	OutputDir      = "outputs"
 	InputDir       = "inputs"
+	VideoStateDB   = "outputs/.videos.db" // Per-UUID processing state, kept next to the rendered videos
 
 	// Processing configuration
-	MaxConcurrentVideos = 3
-	VideoBatchDelay     = 2 * time.Second
+	MaxConcurrentVideos = 3               // Videos processed at once, in every mode
+	MaxQueuedVideos     = 20              // Videos waiting for a worker before API requests are refused
+	ShutdownTimeout     = 5 * time.Minute // How long in-flight videos may finish after SIGTERM (SHUTDOWN_TIMEOUT)
+
+	// Per-stage timeouts, so a hung voiceover URL, render or upload frees its worker
+	DownloadTimeout = 2 * time.Minute
+	RenderTimeout   = 15 * time.Minute
+	UploadTimeout   = 15 * time.Minute
 
 	// Title generation
 	MaxTitleWords  = 10
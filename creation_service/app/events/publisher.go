@@ -0,0 +1,54 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	sharedKafka "brainbot/shared/kafka"
+	sharedTypes "brainbot/shared/types"
+)
+
+// Publisher announces finished videos on Kafka, keyed by generation UUID
+type Publisher struct {
+	producer *sharedKafka.Producer[sharedTypes.VideoResult]
+}
+
+// NewPublisher connects to the brokers and publishes results to topic
+func NewPublisher(brokers []string, topic string) (*Publisher, error) {
+	producer, err := sharedKafka.NewProducer[sharedTypes.VideoResult](sharedKafka.ProducerConfig{
+		Brokers:     brokers,
+		Topic:       topic,
+		ClientID:    "creation-service",
+		MessageType: sharedTypes.VideoResultType,
+		Version:     sharedTypes.VideoResultVersion,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kafka producer: %w", err)
+	}
+
+	log.Printf("Publishing video results to Kafka topic %s (%s)", topic, strings.Join(brokers, ","))
+	return &Publisher{producer: producer}, nil
+}
+
+// PublishResult sends a video result and waits for the brokers to acknowledge
+// it. A nil publisher discards results.
+func (p *Publisher) PublishResult(result sharedTypes.VideoResult) error {
+	if p == nil {
+		return nil
+	}
+	_, err := p.producer.Send(context.Background(), sharedKafka.Message[sharedTypes.VideoResult]{
+		Key:   result.UUID,
+		Value: result,
+	})
+	return err
+}
+
+// Close closes the producer
+func (p *Publisher) Close() error {
+	if p == nil {
+		return nil
+	}
+	return p.producer.Close()
+}
@@ -5,13 +5,16 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
 	"brainbot/creation_service/app"
+	"brainbot/creation_service/app/config"
 	"brainbot/creation_service/app/services"
 	sharedKafka "brainbot/shared/kafka"
+	sharedTypes "brainbot/shared/types"
 )
 
 // ConsumerConfig holds Kafka consumer configuration
@@ -20,6 +23,17 @@ type ConsumerConfig struct {
 	Topic     string
 	GroupID   string
 	Processor *services.VideoProcessor
+
+	// Concurrency is how many videos of one partition are processed at once;
+	// requests with the same key are processed in order
+	Concurrency   int
+	MaxInFlight   int
+	InitialOffset sharedKafka.InitialOffset
+	LagInterval   time.Duration
+
+	// ShutdownTimeout is how long in-flight videos may take to finish after a
+	// termination signal before they are interrupted
+	ShutdownTimeout time.Duration
 }
 
 // NewConsumer creates a new Kafka consumer using the shared consumer implementation
@@ -44,26 +58,40 @@ func NewConsumer(config ConsumerConfig) (*sharedKafka.Consumer, error) {
 			log.Printf("Processing video: UUID=%s", msg.UUID)
 
 			// Process video
-			if err := config.Processor.ProcessVideoInput(*msg, false); err != nil {
+			// Redelivered UUIDs resume from their last completed stage. The
+			// handler's context ends as soon as the consumer stops fetching, so
+			// the video only stops when the processor's shutdown deadline passes.
+			videoID, err := config.Processor.ProcessVideoInput(context.WithoutCancel(ctx), *msg, false)
+			if err != nil {
 				log.Printf("Failed to process video %s: %v", msg.UUID, err)
 				return err // Return error to prevent marking (allow retry)
 			}
 
-			log.Printf("Successfully processed video: UUID=%s", msg.UUID)
+			log.Printf("Successfully processed video: UUID=%s (video ID: %s)", msg.UUID, videoID)
 			return nil
 		},
 		AlwaysMark: true, // Mark validation failures, but not processing failures
+		Type:       sharedTypes.GenerationResultType,
+		Version:    sharedTypes.GenerationResultVersion,
+		Upgrades:   map[int]sharedKafka.UpgradeFunc{0: sharedTypes.UpgradeGenerationResultV0},
 	}
 
 	return sharedKafka.NewConsumer(sharedKafka.ConsumerConfig{
-		Brokers: config.Brokers,
-		Topic:   config.Topic,
-		GroupID: config.GroupID,
-		Handler: handler,
+		Brokers:           config.Brokers,
+		Topic:             config.Topic,
+		GroupID:           config.GroupID,
+		Handler:           handler,
+		Concurrency:       config.Concurrency,
+		MaxInFlight:       config.MaxInFlight,
+		InitialOffset:     config.InitialOffset,
+		LagReportInterval: config.LagInterval,
 	})
 }
 
-// StartConsumerWithGracefulShutdown starts the Kafka consumer with graceful shutdown handling
+// StartConsumerWithGracefulShutdown starts the Kafka consumer and runs until
+// SIGINT/SIGTERM. It then stops fetching messages, waits up to ShutdownTimeout
+// for in-flight videos, interrupts any that are left (their messages stay
+// unmarked, so they are redelivered) and commits the finished offsets.
 func StartConsumerWithGracefulShutdown(config ConsumerConfig) error {
 	consumer, err := NewConsumer(config)
 	if err != nil {
@@ -89,10 +117,14 @@ func StartConsumerWithGracefulShutdown(config ConsumerConfig) error {
 		log.Println("Context canceled")
 	}
 
+	// Stop fetching; handlers already waiting on a video keep waiting for it
 	cancel()
 
-	// Give some time for in-flight processing to complete
-	time.Sleep(2 * time.Second)
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), config.ShutdownTimeout)
+	defer cancelShutdown()
+	if err := config.Processor.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Shutdown error: %v", err)
+	}
 
 	return consumer.Close()
 }
@@ -123,3 +155,58 @@ func GetKafkaGroupID() string {
 	}
 	return groupID
 }
+
+// GetKafkaResultsTopic returns the topic finished videos are announced on
+func GetKafkaResultsTopic() string {
+	topic := os.Getenv("KAFKA_TOPIC_VIDEO_RESULTS")
+	if topic == "" {
+		topic = sharedTypes.TopicVideoResults
+	}
+	return topic
+}
+
+// GetKafkaConcurrency returns how many videos per partition are processed at once
+func GetKafkaConcurrency() int {
+	return getEnvInt("KAFKA_CONSUMER_CONCURRENCY", 1)
+}
+
+// GetKafkaMaxInFlight returns how many messages per partition may be outstanding
+func GetKafkaMaxInFlight() int {
+	return getEnvInt("KAFKA_MAX_IN_FLIGHT", 0)
+}
+
+// GetKafkaInitialOffset returns where a new consumer group starts reading
+func GetKafkaInitialOffset() sharedKafka.InitialOffset {
+	offset, err := sharedKafka.ParseInitialOffset(os.Getenv("KAFKA_INITIAL_OFFSET"))
+	if err != nil {
+		log.Printf("Invalid KAFKA_INITIAL_OFFSET, using newest: %v", err)
+		return sharedKafka.OffsetNewest
+	}
+	return offset
+}
+
+// GetKafkaLagInterval returns how often consumer lag is logged (0 disables)
+func GetKafkaLagInterval() time.Duration {
+	interval, err := time.ParseDuration(os.Getenv("KAFKA_LAG_REPORT_INTERVAL"))
+	if err != nil {
+		return time.Minute
+	}
+	return interval
+}
+
+// GetShutdownTimeout returns how long in-flight videos may take to finish on shutdown
+func GetShutdownTimeout() time.Duration {
+	timeout, err := time.ParseDuration(os.Getenv("SHUTDOWN_TIMEOUT"))
+	if err != nil || timeout <= 0 {
+		return config.ShutdownTimeout
+	}
+	return timeout
+}
+
+// getEnvInt reads a positive integer from the environment
+func getEnvInt(key string, defaultValue int) int {
+	if n, err := strconv.Atoi(os.Getenv(key)); err == nil && n > 0 {
+		return n
+	}
+	return defaultValue
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"math"
@@ -8,6 +9,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"brainbot/creation_service/app"
 	"brainbot/creation_service/app/config"
@@ -15,19 +17,67 @@ import (
 	ffmpeg "github.com/u2takey/ffmpeg-go"
 )
 
-func CreateVideo(input app.VideoInput, backgroundVideoPath string, outputPath string) error {
-	tmpDir := os.TempDir()
-	audioPath := filepath.Join(tmpDir, fmt.Sprintf("%s_audio.mp3", input.UUID))
-	if err := downloadFile(input.Voiceover, audioPath); err != nil {
-		return fmt.Errorf("failed to download audio: %w", err)
+// ffmpegStopGrace is how long ffmpeg may take to finish after being interrupted
+// before it is killed
+const ffmpegStopGrace = 10 * time.Second
+
+// Pipeline stages reported by StageError
+const (
+	StageDownload = "download"
+	StageRender   = "render"
+	StageUpload   = "upload"
+)
+
+// StageError reports which pipeline stage a video failed in
+type StageError struct {
+	Stage string
+	Err   error
+}
+
+func (e *StageError) Error() string {
+	return fmt.Sprintf("%s failed: %v", e.Stage, e.Err)
+}
+
+func (e *StageError) Unwrap() error {
+	return e.Err
+}
+
+// CreateVideo downloads the voiceover and renders a video into outputPath,
+// failing with a *StageError. The download and render are bounded by
+// config.DownloadTimeout and config.RenderTimeout, and cancelling ctx
+// interrupts ffmpeg; the temporary audio and subtitle files are removed either
+// way, and outputPath is only written once the render succeeds.
+func CreateVideo(ctx context.Context, input app.VideoInput, backgroundVideoPath string, outputPath string) error {
+	tmpDir, err := os.MkdirTemp("", fmt.Sprintf("creation-%s-", input.UUID))
+	if err != nil {
+		return &StageError{Stage: StageRender, Err: fmt.Errorf("failed to create temp directory: %w", err)}
 	}
-	defer os.Remove(audioPath)
+	defer os.RemoveAll(tmpDir)
 
-	assPath := filepath.Join(tmpDir, fmt.Sprintf("%s_subtitles.ass", input.UUID))
+	audioPath := filepath.Join(tmpDir, "audio.mp3")
+	downloadCtx, cancelDownload := context.WithTimeout(ctx, config.DownloadTimeout)
+	err = downloadFile(downloadCtx, input.Voiceover, audioPath)
+	cancelDownload()
+	if err != nil {
+		return &StageError{Stage: StageDownload, Err: fmt.Errorf("failed to download audio: %w", err)}
+	}
+
+	if err := render(ctx, input, backgroundVideoPath, audioPath, tmpDir, outputPath); err != nil {
+		return &StageError{Stage: StageRender, Err: err}
+	}
+	return nil
+}
+
+// render runs ffmpeg, overlaying the subtitles on the background and merging
+// in the downloaded audio
+func render(ctx context.Context, input app.VideoInput, backgroundVideoPath, audioPath, tmpDir, outputPath string) error {
+	ctx, cancel := context.WithTimeout(ctx, config.RenderTimeout)
+	defer cancel()
+
+	assPath := filepath.Join(tmpDir, "subtitles.ass")
 	if err := generateASS(input.SubtitleTimestamps, assPath); err != nil {
 		return fmt.Errorf("failed to generate ASS: %w", err)
 	}
-	defer os.Remove(assPath)
 
 	// Calculate duration: last subtitle end time + padding
 	duration := input.SubtitleTimestamps[len(input.SubtitleTimestamps)-1].End + config.VideoEndPadding
@@ -58,23 +108,49 @@ func CreateVideo(input app.VideoInput, backgroundVideoPath string, outputPath st
 		[]*ffmpeg.Stream{videoCropped}, "ass", ffmpeg.Args{assPathForFFmpeg},
 	)
 
-	err := ffmpeg.Output([]*ffmpeg.Stream{videoWithSubs, audio}, outputPath, ffmpeg.KwArgs{
+	// Render next to the output and rename once done, so an interrupted render
+	// never leaves a truncated video at outputPath
+	ext := filepath.Ext(outputPath)
+	partialPath := strings.TrimSuffix(outputPath, ext) + ".partial" + ext
+	defer os.Remove(partialPath)
+
+	cmd := ffmpeg.OutputContext(ctx, []*ffmpeg.Stream{videoWithSubs, audio}, partialPath, ffmpeg.KwArgs{
 		"c:v":      config.VideoCodec,
 		"c:a":      config.AudioCodec,
 		"b:a":      config.AudioBitrate,
 		"preset":   config.VideoPreset,
 		"shortest": "",
-	}).OverWriteOutput().Run()
+	}).OverWriteOutput().Compile()
 
-	if err != nil {
+	// Ask ffmpeg to stop (it exits cleanly on SIGINT), killing it if it doesn't
+	cmd.Cancel = func() error {
+		if err := cmd.Process.Signal(os.Interrupt); err != nil {
+			return cmd.Process.Kill()
+		}
+		return nil
+	}
+	cmd.WaitDelay = ffmpegStopGrace
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("ffmpeg interrupted: %w", ctx.Err())
+		}
 		return fmt.Errorf("ffmpeg failed: %w", err)
 	}
 
+	if err := os.Rename(partialPath, outputPath); err != nil {
+		return fmt.Errorf("failed to move rendered video: %w", err)
+	}
 	return nil
 }
 
-func downloadFile(url string, filepath string) error {
-	resp, err := http.Get(url)
+func downloadFile(ctx context.Context, url string, filepath string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return err
 	}
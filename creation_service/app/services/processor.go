@@ -1,7 +1,9 @@
 package services
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"math/rand"
@@ -10,23 +12,53 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"brainbot/creation_service/app"
 	"brainbot/creation_service/app/config"
+	"brainbot/creation_service/app/events"
+	"brainbot/creation_service/app/store"
+	sharedTypes "brainbot/shared/types"
 
 	"golang.org/x/net/html"
 )
 
+// ErrVideoInProgress is returned when a UUID is already being processed
+var ErrVideoInProgress = errors.New("video is already being processed")
+
+// maxRetainedJobs is how many recent jobs are kept in memory for status lookups
+const maxRetainedJobs = 100
+
 // VideoProcessor handles the video creation and upload pipeline
 type VideoProcessor struct {
 	uploader    *Uploader
 	backgrounds []string
 	skipUpload  bool
+	videos      *store.VideoStore // Optional; without it every delivery is processed from scratch
+	results     *events.Publisher // Optional; announces each finished video
+
+	// Worker pool shared by every mode
+	queue   chan *task
+	stop    chan struct{} // Closed by Shutdown
+	workers int
+	running atomic.Int32
+	pending sync.WaitGroup     // Admitted videos not yet finished
+	ctx     context.Context    // Parent of every video's context
+	cancel  context.CancelFunc // Interrupts in-flight videos once Shutdown gives up
+
+	mu       sync.Mutex
+	draining bool
+	active   map[string]bool               // Queued or running UUIDs -> whether a worker has started it
+	jobs     map[string]*store.VideoRecord // Recent records, so jobs are visible without a store
+	order    []string                      // UUIDs in jobs, oldest first
 }
 
-// NewVideoProcessor initializes a new video processor
-func NewVideoProcessor(backgroundsDir string) (*VideoProcessor, error) {
+// NewVideoProcessor initializes a new video processor and starts its
+// MaxConcurrentVideos workers. videos records each UUID's progress so
+// redelivered messages resume instead of re-uploading, and results publishes
+// the outcome of every video; both may be nil.
+func NewVideoProcessor(backgroundsDir string, videos *store.VideoStore, results *events.Publisher) (*VideoProcessor, error) {
 	// Try to initialize uploader, but allow it to fail for testing
 	uploader, err := NewUploader()
 	skipUpload := false
@@ -45,15 +77,77 @@ func NewVideoProcessor(backgroundsDir string) (*VideoProcessor, error) {
 	}
 	log.Printf("Found %d background videos", len(backgrounds))
 
-	return &VideoProcessor{
+	p := &VideoProcessor{
 		uploader:    uploader,
 		backgrounds: backgrounds,
 		skipUpload:  skipUpload,
-	}, nil
+		videos:      videos,
+		results:     results,
+		queue:       make(chan *task, config.MaxQueuedVideos),
+		stop:        make(chan struct{}),
+		active:      make(map[string]bool),
+		jobs:        make(map[string]*store.VideoRecord),
+	}
+	p.ctx, p.cancel = context.WithCancel(context.Background())
+	p.startWorkers(config.MaxConcurrentVideos)
+	return p, nil
+}
+
+// Completed returns the record of a UUID the pipeline has already finished
+func (p *VideoProcessor) Completed(uuid string) (*store.VideoRecord, bool) {
+	record, ok := p.lookup(uuid)
+	if !ok || !record.Stage.Done() {
+		return nil, false
+	}
+	return record, true
+}
+
+// Job reports the status of a video by UUID
+func (p *VideoProcessor) Job(uuid string) (app.Job, bool) {
+	p.mu.Lock()
+	started, active := p.active[uuid]
+	p.mu.Unlock()
+
+	record, ok := p.lookup(uuid)
+	if !ok {
+		if !active {
+			return app.Job{}, false
+		}
+		// Queued for the first time; its record is created when a worker starts it
+		record = &store.VideoRecord{UUID: uuid, Stage: store.StageQueued}
+	}
+
+	job := app.Job{
+		UUID:       record.UUID,
+		Stage:      string(record.Stage),
+		Progress:   record.Stage.Progress(),
+		OutputPath: record.OutputPath,
+		VideoID:    record.VideoID,
+		VideoURL:   sharedTypes.YouTubeURL(record.VideoID),
+		Error:      record.Error,
+		Attempts:   record.Attempts,
+		CreatedAt:  record.CreatedAt,
+		UpdatedAt:  record.UpdatedAt,
+		FinishedAt: record.FinishedAt,
+	}
+	switch {
+	case active && !started:
+		job.Status = app.JobQueued
+	case active:
+		job.Status = app.JobRunning
+	case record.Stage.Done():
+		job.Status = app.JobSucceeded
+	default:
+		job.Status = app.JobFailed
+		if job.Error == "" {
+			job.Error = "interrupted before finishing"
+		}
+	}
+	return job, true
 }
 
 // ProcessFromDirectory processes all JSON files in the specified directory
-func (p *VideoProcessor) ProcessFromDirectory(inputDir string) error {
+func (p *VideoProcessor) ProcessFromDirectory(ctx context.Context, inputDir string) error {
 	// Find both .json and .txt files
 	jsonFiles, err := filepath.Glob(filepath.Join(inputDir, "*.json"))
 	if err != nil {
@@ -75,25 +169,17 @@ func (p *VideoProcessor) ProcessFromDirectory(inputDir string) error {
 
 	log.Printf("Found %d videos to process", len(allFiles))
 
+	// The worker pool bounds how many are processed at once
 	var wg sync.WaitGroup
-	semaphore := make(chan struct{}, config.MaxConcurrentVideos)
-
 	for i, jsonFile := range allFiles {
 		wg.Add(1)
 
 		go func(idx int, file string) {
 			defer wg.Done()
 
-			semaphore <- struct{}{}
-			defer func() { <-semaphore }()
-
-			if err := p.ProcessSingleVideo(file, idx+1, len(allFiles)); err != nil {
+			if err := p.ProcessSingleVideo(ctx, file, idx+1, len(allFiles)); err != nil {
 				log.Printf("Failed to process %s: %v", file, err)
 			}
-
-			if idx < len(jsonFiles)-1 {
-				time.Sleep(config.VideoBatchDelay)
-			}
 		}(i, jsonFile)
 	}
 
@@ -103,7 +189,7 @@ func (p *VideoProcessor) ProcessFromDirectory(inputDir string) error {
 }
 
 // ProcessSingleVideo processes a single video from JSON input
-func (p *VideoProcessor) ProcessSingleVideo(jsonFile string, current, total int) error {
+func (p *VideoProcessor) ProcessSingleVideo(ctx context.Context, jsonFile string, current, total int) error {
 	log.Printf("[%d/%d] Processing: %s", current, total, filepath.Base(jsonFile))
 
 	data, err := os.ReadFile(jsonFile)
@@ -120,33 +206,99 @@ func (p *VideoProcessor) ProcessSingleVideo(jsonFile string, current, total int)
 		return fmt.Errorf("input status is not success: %s", input.Status)
 	}
 
-	return p.ProcessVideoInput(input, true)
+	_, err = p.ProcessVideoInput(ctx, input, true)
+	return err
+}
+
+// ProcessVideoInput queues a VideoInput, waiting for a free queue slot, and
+// returns once a worker has processed it (optionally deleting the source file).
+// It returns the YouTube video ID (empty when uploads are disabled). A UUID that
+// was already uploaded returns its existing video ID, and one whose video was
+// already rendered skips straight to the upload. Cancelling ctx abandons the
+// video, whether it is still queued or already being processed.
+func (p *VideoProcessor) ProcessVideoInput(ctx context.Context, input app.VideoInput, cleanup bool) (string, error) {
+	t, err := p.enqueue(ctx, input, cleanup)
+	if err != nil {
+		return "", err
+	}
+	result := <-t.done
+	return result.videoID, result.err
 }
 
-// ProcessVideoInput processes a VideoInput struct and optionally deletes the source file
-func (p *VideoProcessor) ProcessVideoInput(input app.VideoInput, cleanup bool) error {
-	backgroundVideo := p.backgrounds[rand.Intn(len(p.backgrounds))]
-	log.Printf("Using background: %s", filepath.Base(backgroundVideo))
+// processVideo runs the pipeline for a video on the calling worker
+func (p *VideoProcessor) processVideo(ctx context.Context, t *task) (string, error) {
+	input := t.input
+	// Videos still queued when Shutdown interrupts the pool are left for redelivery
+	if p.ctx.Err() != nil {
+		if t.detached {
+			p.drop(input.UUID)
+		}
+		return "", ErrShuttingDown
+	}
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	record := p.begin(input.UUID)
+	if record.Stage.Done() {
+		log.Printf("Video %s already %s (video ID: %s), skipping", input.UUID, record.Stage, record.VideoID)
+		p.publish(record)
+		return record.VideoID, nil
+	}
+
+	videoID, err := p.process(ctx, input, record, t.cleanup)
+	p.finish(record, err, t.detached)
+	return videoID, err
+}
 
+// process runs the pipeline stages a record hasn't completed yet
+func (p *VideoProcessor) process(ctx context.Context, input app.VideoInput, record *store.VideoRecord, cleanup bool) (string, error) {
 	outputPath := filepath.Join(config.OutputDir, fmt.Sprintf("%s.mp4", input.UUID))
-	log.Printf("Creating video...")
-	if err := CreateVideo(input, backgroundVideo, outputPath); err != nil {
-		return fmt.Errorf("video creation failed: %w", err)
+	if record.Stage == store.StageRendered || record.Stage == store.StageUploading {
+		if _, err := os.Stat(record.OutputPath); err == nil {
+			outputPath = record.OutputPath
+			log.Printf("Reusing rendered video: %s", outputPath)
+		} else {
+			log.Printf("Rendered video %s is missing, rendering again", record.OutputPath)
+			record.Stage = store.StageRendering
+		}
+	}
+
+	if record.Stage == store.StageRendering {
+		backgroundVideo := p.backgrounds[rand.Intn(len(p.backgrounds))]
+		log.Printf("Using background: %s", filepath.Base(backgroundVideo))
+
+		log.Printf("Creating video...")
+		if err := CreateVideo(ctx, input, backgroundVideo, outputPath); err != nil {
+			return "", err
+		}
+		log.Printf("Video created: %s", outputPath)
+
+		record.Stage = store.StageRendered
+		record.OutputPath = outputPath
+		p.save(record)
 	}
-	log.Printf("Video created: %s", outputPath)
 
 	// Skip upload if credentials not configured
 	if p.skipUpload {
 		log.Printf("Skipping YouTube upload (no credentials)")
 		log.Printf("SUCCESS! Video saved: %s", outputPath)
-		return nil
+		record.Stage = store.StageSkipped
+		p.save(record)
+		return "", nil
+	}
+
+	if record.Stage == store.StageUploading {
+		// The previous attempt died between starting the upload and recording
+		// its video ID, so it may or may not have reached YouTube
+		log.Printf("Warning: previous upload of %s did not finish, uploading again", input.UUID)
 	}
 
 	// Use title from input, or fetch from first article URL, or generate from subtitles as fallback
 	articleTitle := input.Title
 	if articleTitle == "" && len(input.ArticleURLs) > 0 {
 		log.Printf("  Fetching title from: %s", input.ArticleURLs[0])
-		fetchedTitle, err := fetchTitleFromURL(input.ArticleURLs[0])
+		fetchedTitle, err := fetchTitleFromURL(ctx, input.ArticleURLs[0])
 		if err != nil {
 			log.Printf("  Warning: Failed to fetch title from URL: %v", err)
 		} else {
@@ -166,13 +318,23 @@ func (p *VideoProcessor) ProcessVideoInput(input app.VideoInput, cleanup bool) e
 
 	metadata := GenerateMetadata(input, articleTitle, sourceURL)
 
+	record.Stage = store.StageUploading
+	p.save(record)
+
 	log.Printf("Uploading to YouTube...")
-	videoID, err := p.uploader.UploadVideo(outputPath, metadata)
+	uploadCtx, cancelUpload := context.WithTimeout(ctx, config.UploadTimeout)
+	videoID, err := p.uploader.UploadVideo(uploadCtx, outputPath, metadata)
+	cancelUpload()
 	if err != nil {
-		return fmt.Errorf("upload failed: %w", err)
+		record.Stage = store.StageRendered
+		p.save(record)
+		return "", &StageError{Stage: StageUpload, Err: err}
 	}
 
 	log.Printf("SUCCESS! Video ID: %s", videoID)
+	record.Stage = store.StageUploaded
+	record.VideoID = videoID
+	p.save(record)
 
 	// Optional: cleanup can be disabled for API processing
 	if cleanup {
@@ -181,7 +343,155 @@ func (p *VideoProcessor) ProcessVideoInput(input app.VideoInput, cleanup bool) e
 		// os.Remove(outputPath)
 	}
 
-	return nil
+	return videoID, nil
+}
+
+// begin loads (or starts) the record for a UUID. Store errors are logged and
+// processing continues from scratch rather than failing the video.
+func (p *VideoProcessor) begin(uuid string) *store.VideoRecord {
+	var record *store.VideoRecord
+	if p.videos != nil {
+		var err error
+		if record, err = p.videos.Begin(uuid); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+	}
+	if record == nil {
+		now := time.Now()
+		record = &store.VideoRecord{UUID: uuid, Stage: store.StageRendering, CreatedAt: now}
+		if previous, ok := p.lookup(uuid); ok {
+			*record = *previous
+		}
+		record.Attempts++
+		record.UpdatedAt = now
+	}
+	if record.Attempts > 1 {
+		log.Printf("Video %s seen before (attempt %d, stage: %s)", uuid, record.Attempts, record.Stage)
+	}
+
+	if !record.Stage.Done() {
+		record.Error = ""
+		record.FinishedAt = nil
+	}
+	p.remember(record)
+	return record
+}
+
+// finish records how an attempt ended and announces it. Attempts interrupted
+// by Shutdown aren't announced unless detached, since the video resumes on
+// redelivery.
+func (p *VideoProcessor) finish(record *store.VideoRecord, err error, detached bool) {
+	interrupted := err != nil && p.ctx.Err() != nil && errors.Is(err, context.Canceled)
+	if interrupted && detached {
+		err = fmt.Errorf("interrupted by shutdown at stage %s: %w", record.Stage, err)
+	}
+
+	now := time.Now()
+	record.FinishedAt = &now
+	if err != nil {
+		record.Error = err.Error()
+	}
+	p.save(record)
+
+	if interrupted && !detached {
+		log.Printf("Video %s interrupted by shutdown at stage %s", record.UUID, record.Stage)
+		return
+	}
+	p.publish(record)
+}
+
+// drop records and announces a detached video Shutdown dropped before a worker
+// started it
+func (p *VideoProcessor) drop(uuid string) {
+	record := p.begin(uuid)
+	if !record.Stage.Done() {
+		log.Printf("Video %s dropped by shutdown before it started", uuid)
+		now := time.Now()
+		record.FinishedAt = &now
+		record.Error = "dropped by shutdown before it started"
+		p.save(record)
+	}
+	p.publish(record)
+}
+
+// save persists a record's new stage, logging failures
+func (p *VideoProcessor) save(record *store.VideoRecord) {
+	p.remember(record)
+	if p.videos == nil {
+		return
+	}
+	if err := p.videos.Save(record); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+}
+
+// remember keeps a copy of a record for status lookups, dropping the oldest
+// beyond maxRetainedJobs
+func (p *VideoProcessor) remember(record *store.VideoRecord) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	snapshot := *record
+	if _, ok := p.jobs[record.UUID]; !ok {
+		p.order = append(p.order, record.UUID)
+	}
+	p.jobs[record.UUID] = &snapshot
+
+	for len(p.order) > maxRetainedJobs {
+		delete(p.jobs, p.order[0])
+		p.order = p.order[1:]
+	}
+}
+
+// lookup returns a copy of the latest record for a UUID, from memory or the store
+func (p *VideoProcessor) lookup(uuid string) (*store.VideoRecord, bool) {
+	p.mu.Lock()
+	record, ok := p.jobs[uuid]
+	p.mu.Unlock()
+	if ok {
+		snapshot := *record
+		return &snapshot, true
+	}
+
+	if p.videos == nil {
+		return nil, false
+	}
+	record, err := p.videos.Get(uuid)
+	if err != nil {
+		if !errors.Is(err, store.ErrNotFound) {
+			log.Printf("Warning: failed to look up video %s: %v", uuid, err)
+		}
+		return nil, false
+	}
+	return record, true
+}
+
+// publish announces a finished attempt, logging failures
+func (p *VideoProcessor) publish(record *store.VideoRecord) {
+	result := sharedTypes.VideoResult{
+		UUID:       record.UUID,
+		OutputPath: record.OutputPath,
+		VideoID:    record.VideoID,
+		URL:        sharedTypes.YouTubeURL(record.VideoID),
+		Error:      record.Error,
+		Attempts:   record.Attempts,
+		FinishedAt: time.Now(),
+	}
+	if record.FinishedAt != nil {
+		result.FinishedAt = *record.FinishedAt
+	}
+	switch {
+	case record.Stage == store.StageUploaded:
+		result.Status = sharedTypes.VideoUploaded
+	case record.Stage == store.StageSkipped:
+		result.Status = sharedTypes.VideoRendered
+	default:
+		result.Status = sharedTypes.VideoFailed
+	}
+
+	if err := p.results.PublishResult(result); err != nil {
+		log.Printf("Warning: failed to publish result for video %s: %v", record.UUID, err)
+	}
 }
 
 // getBackgroundVideos retrieves all background videos from the specified directory
@@ -199,12 +509,17 @@ func getBackgroundVideos(dir string) ([]string, error) {
 }
 
 // fetchTitleFromURL fetches the HTML title from a given URL
-func fetchTitleFromURL(url string) (string, error) {
+func fetchTitleFromURL(ctx context.Context, url string) (string, error) {
 	client := &http.Client{
 		Timeout: 10 * time.Second,
 	}
 
-	resp, err := client.Get(url)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch URL: %w", err)
+	}
+
+	resp, err := client.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("failed to fetch URL: %w", err)
 	}
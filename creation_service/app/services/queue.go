@@ -0,0 +1,206 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"brainbot/creation_service/app"
+)
+
+var (
+	// ErrQueueFull is returned by Submit when every queue slot is taken
+	ErrQueueFull = errors.New("video queue is full")
+	// ErrShuttingDown is returned once Shutdown has been called
+	ErrShuttingDown = errors.New("video processor is shutting down")
+)
+
+// QueueStats describes the processor's worker pool (GET /health)
+type QueueStats struct {
+	Workers  int  `json:"workers"`
+	Running  int  `json:"running"`  // Videos a worker is processing
+	Queued   int  `json:"queued"`   // Videos waiting for a worker
+	Capacity int  `json:"capacity"` // Videos that may wait before submissions are refused
+	Draining bool `json:"draining"` // Shutdown has been called
+}
+
+// interruptGrace is how long Shutdown waits for interrupted videos to stop
+const interruptGrace = 15 * time.Second
+
+// task is one video waiting for a worker
+type task struct {
+	ctx     context.Context // The submitter's; cancelling it stops the video
+	input   app.VideoInput
+	cleanup bool
+	// Nobody waits for a detached task (API submissions) and nothing redelivers
+	// it, so a shutdown that drops or interrupts it reports it as failed
+	detached bool
+	done     chan taskResult // Buffered, so workers never wait for the submitter
+}
+
+// taskResult is a task's outcome
+type taskResult struct {
+	videoID string
+	err     error
+}
+
+// startWorkers launches the pool's workers. They run until the process exits;
+// Shutdown stops new work from being admitted and, past its deadline, makes
+// the workers skip whatever is still queued.
+func (p *VideoProcessor) startWorkers(workers int) {
+	p.workers = workers
+	for i := 0; i < workers; i++ {
+		go func() {
+			for t := range p.queue {
+				p.run(t)
+			}
+		}()
+	}
+}
+
+// Submit queues a video without waiting for it. It fails with ErrQueueFull
+// when the queue is full, ErrShuttingDown during shutdown and
+// ErrVideoInProgress when the UUID is already queued or being processed.
+func (p *VideoProcessor) Submit(ctx context.Context, input app.VideoInput, cleanup bool) error {
+	t, err := p.admit(ctx, input, cleanup)
+	if err != nil {
+		return err
+	}
+	t.detached = true
+
+	select {
+	case p.queue <- t:
+		return nil
+	default:
+		p.reject(t)
+		return ErrQueueFull
+	}
+}
+
+// enqueue queues a video, waiting for a free queue slot
+func (p *VideoProcessor) enqueue(ctx context.Context, input app.VideoInput, cleanup bool) (*task, error) {
+	t, err := p.admit(ctx, input, cleanup)
+	if err != nil {
+		return nil, err
+	}
+
+	select {
+	case p.queue <- t:
+		return t, nil
+	case <-p.stop:
+		p.reject(t)
+		return nil, ErrShuttingDown
+	case <-ctx.Done():
+		p.reject(t)
+		return nil, ctx.Err()
+	}
+}
+
+// admit claims a video's UUID and counts it as pending work
+func (p *VideoProcessor) admit(ctx context.Context, input app.VideoInput, cleanup bool) (*task, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.draining {
+		return nil, ErrShuttingDown
+	}
+	if _, ok := p.active[input.UUID]; ok {
+		return nil, fmt.Errorf("%s: %w", input.UUID, ErrVideoInProgress)
+	}
+	p.active[input.UUID] = false
+	p.pending.Add(1)
+	return &task{ctx: ctx, input: input, cleanup: cleanup, done: make(chan taskResult, 1)}, nil
+}
+
+// reject undoes admit for a task that never made it into the queue
+func (p *VideoProcessor) reject(t *task) {
+	p.release(t.input.UUID)
+	p.pending.Done()
+}
+
+// run processes a queued video and reports the outcome
+func (p *VideoProcessor) run(t *task) {
+	defer p.pending.Done()
+
+	p.mu.Lock()
+	p.active[t.input.UUID] = true
+	p.mu.Unlock()
+	p.running.Add(1)
+
+	// The video stops when either its submitter or Shutdown cancels it
+	ctx, cancel := context.WithCancel(t.ctx)
+	stop := context.AfterFunc(p.ctx, cancel)
+	videoID, err := p.processVideo(ctx, t)
+	stop()
+	cancel()
+
+	p.running.Add(-1)
+	p.release(t.input.UUID)
+	t.done <- taskResult{videoID: videoID, err: err}
+}
+
+// release forgets a UUID once its task is finished or rejected
+func (p *VideoProcessor) release(uuid string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.active, uuid)
+}
+
+// Stats reports the worker pool's current load
+func (p *VideoProcessor) Stats() QueueStats {
+	p.mu.Lock()
+	draining := p.draining
+	p.mu.Unlock()
+
+	return QueueStats{
+		Workers:  p.workers,
+		Running:  int(p.running.Load()),
+		Queued:   len(p.queue),
+		Capacity: cap(p.queue),
+		Draining: draining,
+	}
+}
+
+// Shutdown stops admitting videos and waits until every queued and running
+// video has finished. If ctx is done first, in-flight renders are interrupted
+// (their temporary files removed) and videos still queued are dropped; Shutdown
+// then returns an error. Kafka redelivers the videos it submitted, while those
+// submitted through the API are recorded and published as failed.
+func (p *VideoProcessor) Shutdown(ctx context.Context) error {
+	p.mu.Lock()
+	if !p.draining {
+		p.draining = true
+		close(p.stop)
+	}
+	p.mu.Unlock()
+
+	stats := p.Stats()
+	log.Printf("Draining video queue (%d running, %d queued)", stats.Running, stats.Queued)
+
+	drained := make(chan struct{})
+	go func() {
+		p.pending.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		log.Println("Video queue drained")
+		return nil
+	case <-ctx.Done():
+	}
+
+	stats = p.Stats()
+	log.Printf("Shutdown deadline reached, interrupting %d running videos (%d queued)", stats.Running, stats.Queued)
+	p.cancel()
+
+	select {
+	case <-drained:
+	case <-time.After(interruptGrace):
+		stats = p.Stats()
+		log.Printf("%d videos still running after interrupt", stats.Running)
+	}
+	return fmt.Errorf("video queue not drained before the deadline (%d running, %d queued): %w", stats.Running, stats.Queued, ctx.Err())
+}
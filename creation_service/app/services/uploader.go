@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"brainbot/creation_service/app"
+	sharedTypes "brainbot/shared/types"
 
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
@@ -119,7 +120,9 @@ func NewUploader() (*Uploader, error) {
 	return &Uploader{service: service}, nil
 }
 
-func (u *Uploader) UploadVideo(videoPath string, metadata app.VideoMetadata) (string, error) {
+// UploadVideo uploads a video to YouTube, returning its ID. Cancelling ctx
+// aborts the media upload.
+func (u *Uploader) UploadVideo(ctx context.Context, videoPath string, metadata app.VideoMetadata) (string, error) {
 	file, err := os.Open(videoPath)
 	if err != nil {
 		return "", fmt.Errorf("failed to open video file: %w", err)
@@ -147,7 +150,7 @@ func (u *Uploader) UploadVideo(videoPath string, metadata app.VideoMetadata) (st
 	}
 
 	call := u.service.Videos.Insert([]string{"snippet", "status"}, video)
-	call = call.Media(file)
+	call = call.Media(file).Context(ctx)
 
 	response, err := call.Do()
 	if err != nil {
@@ -155,7 +158,7 @@ func (u *Uploader) UploadVideo(videoPath string, metadata app.VideoMetadata) (st
 	}
 
 	videoID := response.Id
-	log.Printf("Uploaded! https://youtube.com/shorts/%s", videoID)
+	log.Printf("Uploaded! %s", sharedTypes.YouTubeURL(videoID))
 
 	return videoID, nil
 }
@@ -0,0 +1,158 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// ErrNotFound is returned when a UUID has no record
+var ErrNotFound = errors.New("video not found")
+
+var videosBucket = []byte("videos")
+
+// Stage is how far a video has progressed through the pipeline
+type Stage string
+
+const (
+	StageQueued    Stage = "queued"    // Waiting for a worker; never stored
+	StageRendering Stage = "rendering" // Rendering started but hasn't finished
+	StageRendered  Stage = "rendered"  // The video file is in OutputPath
+	StageUploading Stage = "uploading" // Upload started; a crash here may leave a copy on YouTube
+	StageUploaded  Stage = "uploaded"  // VideoID is set
+	StageSkipped   Stage = "skipped"   // Rendered, upload disabled (no credentials)
+)
+
+// Done reports whether the pipeline has nothing left to do for the video
+func (s Stage) Done() bool {
+	return s == StageUploaded || s == StageSkipped
+}
+
+// Progress estimates how far through the pipeline a stage is, from 0 to 1
+func (s Stage) Progress() float64 {
+	switch s {
+	case StageRendered:
+		return 0.6
+	case StageUploading:
+		return 0.7
+	case StageUploaded, StageSkipped:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// VideoRecord is the processing state of one generation UUID
+type VideoRecord struct {
+	UUID       string     `json:"uuid"`
+	Stage      Stage      `json:"stage"`
+	OutputPath string     `json:"output_path,omitempty"`
+	VideoID    string     `json:"video_id,omitempty"`
+	Error      string     `json:"error,omitempty"` // Why the latest attempt failed
+	Attempts   int        `json:"attempts"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"` // When the latest attempt ended
+}
+
+// VideoStore remembers which stages each UUID has completed, so a message
+// delivered twice resumes where the first delivery stopped
+type VideoStore struct {
+	db *bolt.DB
+}
+
+// Open opens (or creates) the video store at path
+func Open(path string) (*VideoStore, error) {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("create video store directory: %w", err)
+		}
+	}
+
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("open video store: %w", err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(videosBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("initialize video store: %w", err)
+	}
+
+	return &VideoStore{db: db}, nil
+}
+
+// Get returns the record for a UUID
+func (s *VideoStore) Get(uuid string) (*VideoRecord, error) {
+	var record VideoRecord
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(videosBucket).Get([]byte(uuid))
+		if data == nil {
+			return ErrNotFound
+		}
+		return json.Unmarshal(data, &record)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// Begin records a new processing attempt for a UUID and returns its record,
+// creating it if this is the first attempt
+func (s *VideoStore) Begin(uuid string) (*VideoRecord, error) {
+	var record VideoRecord
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(videosBucket)
+		now := time.Now()
+		if data := bucket.Get([]byte(uuid)); data != nil {
+			if err := json.Unmarshal(data, &record); err != nil {
+				return err
+			}
+		} else {
+			record = VideoRecord{UUID: uuid, Stage: StageRendering, CreatedAt: now}
+		}
+		record.Attempts++
+		record.UpdatedAt = now
+		return put(bucket, &record)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("begin video %s: %w", uuid, err)
+	}
+	return &record, nil
+}
+
+// Save stores a record, stamping its update time
+func (s *VideoStore) Save(record *VideoRecord) error {
+	record.UpdatedAt = time.Now()
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		return put(tx.Bucket(videosBucket), record)
+	})
+	if err != nil {
+		return fmt.Errorf("save video %s: %w", record.UUID, err)
+	}
+	return nil
+}
+
+// Close closes the underlying database
+func (s *VideoStore) Close() error {
+	return s.db.Close()
+}
+
+// put writes a record to the bucket
+func put(bucket *bolt.Bucket, record *VideoRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("marshal video %s: %w", record.UUID, err)
+	}
+	return bucket.Put([]byte(record.UUID), data)
+}
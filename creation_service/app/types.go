@@ -1,22 +1,16 @@
 package app
 
-type SubtitleTimestamp struct {
-	Text  string  `json:"text"`
-	Start float64 `json:"start"`
-	End   float64 `json:"end"`
-}
+import (
+	"time"
 
-type VideoInput struct {
-	UUID               string              `json:"uuid"`
-	Voiceover          string              `json:"voiceover"`
-	SubtitleTimestamps []SubtitleTimestamp `json:"subtitle_timestamps"`
-	ResourceTimestamps map[string]any      `json:"resource_timestamps"`
-	Status             string              `json:"status"`
-	Error              *string             `json:"error"`
-	Title              string              `json:"title,omitempty"`
-	SourceURL          string              `json:"source_url,omitempty"`
-	ArticleURLs        []string            `json:"article_urls,omitempty"`
-}
+	sharedTypes "brainbot/shared/types"
+)
+
+// SubtitleTimestamp is a subtitle word or phrase and when it is spoken
+type SubtitleTimestamp = sharedTypes.SubtitleTimestamp
+
+// VideoInput is the generation result a video is rendered from
+type VideoInput = sharedTypes.GenerationResult
 
 type VideoMetadata struct {
 	Title       string
@@ -35,3 +29,29 @@ type ProcessVideoResponse struct {
 	VideoID string `json:"video_id,omitempty"`
 	Error   string `json:"error,omitempty"`
 }
+
+// JobStatus is where a video job stands
+type JobStatus string
+
+const (
+	JobQueued    JobStatus = "queued"
+	JobRunning   JobStatus = "running"
+	JobSucceeded JobStatus = "succeeded"
+	JobFailed    JobStatus = "failed"
+)
+
+// Job is the status of one video (GET /api/jobs/:uuid)
+type Job struct {
+	UUID       string     `json:"uuid"`
+	Status     JobStatus  `json:"status"`
+	Stage      string     `json:"stage"`    // Last stage reached: queued, rendering, rendered, uploading, uploaded or skipped
+	Progress   float64    `json:"progress"` // 0 to 1, estimated from the stage
+	OutputPath string     `json:"output_path,omitempty"`
+	VideoID    string     `json:"video_id,omitempty"`
+	VideoURL   string     `json:"video_url,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	Attempts   int        `json:"attempts"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
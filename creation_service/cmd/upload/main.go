@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
@@ -59,7 +60,7 @@ func main() {
 		CategoryID:  *categoryID,
 	}
 
-	videoID, err := uploader.UploadVideo(*videoPath, metadata)
+	videoID, err := uploader.UploadVideo(context.Background(), *videoPath, metadata)
 	if err != nil {
 		log.Fatalf("upload failed: %v", err)
 	}
@@ -1,17 +1,23 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"flag"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"syscall"
+	"time"
 
 	"brainbot/creation_service/app/api"
 	"brainbot/creation_service/app/config"
+	"brainbot/creation_service/app/events"
 	"brainbot/creation_service/app/kafka"
 	"brainbot/creation_service/app/services"
+	"brainbot/creation_service/app/store"
 
 	"github.com/joho/godotenv"
 )
@@ -32,8 +38,26 @@ func main() {
 
 	log.Println("Video Creation Service - Starting...")
 
+	// Open the video state store so redelivered videos aren't uploaded twice
+	videos, err := store.Open(getEnv("VIDEO_STATE_DB", config.VideoStateDB))
+	if err != nil {
+		log.Printf("Video state store unavailable, duplicate deliveries will be reprocessed: %v", err)
+		videos = nil
+	} else {
+		defer videos.Close()
+	}
+
+	// Announce finished videos so the orchestrator can record them
+	results, err := events.NewPublisher(kafka.GetKafkaBrokers(), kafka.GetKafkaResultsTopic())
+	if err != nil {
+		log.Printf("Video results will not be published: %v", err)
+		results = nil
+	} else {
+		defer results.Close()
+	}
+
 	// Initialize video processor
-	proc, err := services.NewVideoProcessor(config.BackgroundsDir)
+	proc, err := services.NewVideoProcessor(config.BackgroundsDir, videos, results)
 	if err != nil {
 		log.Fatalf("Failed to initialize processor: %v", err)
 	}
@@ -41,10 +65,13 @@ func main() {
 	if *batchMode {
 		// Batch mode: Process all files in input/ directory
 		log.Println("Running in BATCH mode")
-		if err := proc.ProcessFromDirectory(config.InputDir); err != nil {
+		// SIGINT/SIGTERM interrupts the videos in progress
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+		if err := proc.ProcessFromDirectory(ctx, config.InputDir); err != nil {
 			log.Fatalf("Batch processing failed: %v", err)
 		}
-		os.Exit(0)
+		return
 	}
 
 	if *kafkaMode {
@@ -56,16 +83,24 @@ func main() {
 			Topic:     kafka.GetKafkaTopic(),
 			GroupID:   kafka.GetKafkaGroupID(),
 			Processor: proc,
+
+			Concurrency:   kafka.GetKafkaConcurrency(),
+			MaxInFlight:   kafka.GetKafkaMaxInFlight(),
+			InitialOffset: kafka.GetKafkaInitialOffset(),
+			LagInterval:   kafka.GetKafkaLagInterval(),
+
+			ShutdownTimeout: kafka.GetShutdownTimeout(),
 		}
 
 		log.Printf("Kafka Brokers: %v", kafkaConfig.Brokers)
 		log.Printf("Topic: %s", kafkaConfig.Topic)
 		log.Printf("Consumer Group: %s", kafkaConfig.GroupID)
+		log.Printf("Concurrency: %d per partition (initial offset: %s)", kafkaConfig.Concurrency, kafkaConfig.InitialOffset)
 
 		if err := kafka.StartConsumerWithGracefulShutdown(kafkaConfig); err != nil {
 			log.Fatalf("Kafka consumer failed: %v", err)
 		}
-		os.Exit(0)
+		return
 	}
 
 	// API mode: Start HTTP server
@@ -77,11 +112,42 @@ func main() {
 	log.Printf("API Server listening on %s", *apiPort)
 	log.Println("Endpoints:")
 	log.Println("   POST /api/process-video  - Process video from JSON")
+	log.Println("   GET  /api/jobs/:uuid     - Video job status")
 	log.Println("   GET  /health             - Health check")
 
-	if err := http.ListenAndServe(*apiPort, mux); err != nil {
-		log.Fatalf("Server failed: %v", err)
+	server := &http.Server{Addr: *apiPort, Handler: mux}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Server failed: %v", err)
+		}
+	}()
+
+	// On SIGINT/SIGTERM stop taking requests, then let the queued videos finish,
+	// interrupting them if they take longer than the shutdown timeout
+	sigterm := make(chan os.Signal, 1)
+	signal.Notify(sigterm, syscall.SIGINT, syscall.SIGTERM)
+	<-sigterm
+	log.Println("Received termination signal")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("Server shutdown error: %v", err)
+	}
+
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), kafka.GetShutdownTimeout())
+	defer cancelShutdown()
+	if err := proc.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Shutdown error: %v", err)
+	}
+}
+
+// getEnv returns the environment variable or a default value
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
 	}
+	return defaultValue
 }
 
 func loadEnvOrFallback() {
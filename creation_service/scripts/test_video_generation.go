@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"log"
 	"math/rand"
@@ -54,7 +55,7 @@ func main() {
 	outputPath := filepath.Join(config.OutputDir, input.UUID+".mp4")
 	log.Printf("Creating video: %s", outputPath)
 
-	if err := services.CreateVideo(input, backgroundVideo, outputPath); err != nil {
+	if err := services.CreateVideo(context.Background(), input, backgroundVideo, outputPath); err != nil {
 		log.Fatalf("Video creation failed: %v", err)
 	}
 
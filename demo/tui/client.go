@@ -1,18 +1,22 @@
 package tui
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 )
 
 // OrchestratorClient is a thin HTTP client for the orchestrator API
 type OrchestratorClient struct {
-	baseURL string
-	client  *http.Client
+	baseURL      string
+	client       *http.Client
+	streamClient *http.Client // No timeout: event streams stay open
 }
 
 // NewOrchestratorClient creates a new orchestrator client
@@ -22,9 +26,60 @@ func NewOrchestratorClient(baseURL string) *OrchestratorClient {
 		client: &http.Client{
 			Timeout: 5 * time.Second,
 		},
+		streamClient: &http.Client{},
 	}
 }
 
+// StreamEvents opens the orchestrator event stream. When resume is set, the
+// stream continues after lastSeq. The returned channel is closed when the
+// stream ends.
+func (c *OrchestratorClient) StreamEvents(lastSeq uint64, resume bool) (<-chan Event, error) {
+	req, err := http.NewRequest(http.MethodGet, c.baseURL+"/api/events", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	if resume {
+		req.Header.Set("Last-Event-ID", strconv.FormatUint(lastSeq, 10))
+	}
+
+	resp, err := c.streamClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open event stream: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		return nil, fmt.Errorf("server returned %d: %s", resp.StatusCode, string(body))
+	}
+
+	events := make(chan Event, 64)
+	go func() {
+		defer resp.Body.Close()
+		defer close(events)
+
+		// Each SSE message is one or more "data:" lines ended by a blank line
+		var data strings.Builder
+		scanner := bufio.NewScanner(resp.Body)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case strings.HasPrefix(line, "data:"):
+				data.WriteString(strings.TrimSpace(strings.TrimPrefix(line, "data:")))
+			case line == "" && data.Len() > 0:
+				var event Event
+				if err := json.Unmarshal([]byte(data.String()), &event); err == nil {
+					events <- event
+				}
+				data.Reset()
+			}
+		}
+	}()
+
+	return events, nil
+}
+
 // GetStatus fetches the current status from the orchestrator
 func (c *OrchestratorClient) GetStatus() (*StatusResponse, error) {
 	resp, err := c.client.Get(c.baseURL + "/api/status")
@@ -79,3 +134,62 @@ func (c *OrchestratorClient) FetchNew(feedPreset string) error {
 
 	return nil
 }
+
+// DryRun previews a workflow run on the orchestrator without side effects
+func (c *OrchestratorClient) DryRun(feedPreset string) error {
+	body := fmt.Sprintf(`{"feed_preset": "%s"}`, feedPreset)
+	resp, err := c.client.Post(c.baseURL+"/api/start?dry_run=true", "application/json", bytes.NewReader([]byte(body)))
+	if err != nil {
+		return fmt.Errorf("failed to start dry run: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusAccepted {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("server returned %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	return nil
+}
+
+// GetCandidates fetches the stories waiting for editorial approval
+func (c *OrchestratorClient) GetCandidates() ([]Candidate, error) {
+	resp, err := c.client.Get(c.baseURL + "/api/candidates")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get candidates: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("server returned %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result CandidatesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return result.Candidates, nil
+}
+
+// DecideCandidate approves or rejects a candidate
+func (c *OrchestratorClient) DecideCandidate(id string, approve bool) error {
+	action := "reject"
+	if approve {
+		action = "approve"
+	}
+
+	resp, err := c.client.Post(fmt.Sprintf("%s/api/candidates/%s/%s", c.baseURL, id, action), "application/json", nil)
+	if err != nil {
+		return fmt.Errorf("failed to %s candidate: %w", action, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("server returned %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	return nil
+}
@@ -33,7 +33,53 @@ func triggerFetchNew(client *OrchestratorClient, feedPreset string) tea.Cmd {
 	}
 }
 
-// tickCmd creates a command that ticks every 500ms for polling
+// triggerDryRun creates a command to preview a run without side effects
+func triggerDryRun(client *OrchestratorClient, feedPreset string) tea.Cmd {
+	return func() tea.Msg {
+		err := client.DryRun(feedPreset)
+		return StartWorkflowMsg{Err: err}
+	}
+}
+
+// connectEvents creates a command to open the orchestrator event stream
+func connectEvents(client *OrchestratorClient, lastSeq uint64, resume bool) tea.Cmd {
+	return func() tea.Msg {
+		events, err := client.StreamEvents(lastSeq, resume)
+		return EventsConnectedMsg{Events: events, Err: err}
+	}
+}
+
+// waitForEvent creates a command that delivers the next streamed event
+func waitForEvent(events <-chan Event) tea.Cmd {
+	return func() tea.Msg {
+		event, ok := <-events
+		if !ok {
+			return EventsClosedMsg{}
+		}
+		return EventMsg{Event: event}
+	}
+}
+
+// pollCandidates creates a command to fetch the approval queue
+func pollCandidates(client *OrchestratorClient) tea.Cmd {
+	return func() tea.Msg {
+		candidates, err := client.GetCandidates()
+		return CandidatesMsg{
+			Candidates: candidates,
+			Err:        err,
+		}
+	}
+}
+
+// decideCandidate creates a command to approve or reject a candidate
+func decideCandidate(client *OrchestratorClient, id string, approve bool) tea.Cmd {
+	return func() tea.Msg {
+		err := client.DecideCandidate(id, approve)
+		return CandidateDecisionMsg{Err: err}
+	}
+}
+
+// tickCmd creates a command that ticks every 500ms for polling and stream reconnects
 func tickCmd() tea.Cmd {
 	return tea.Tick(500*time.Millisecond, func(t time.Time) tea.Msg {
 		return TickMsg{Time: t}
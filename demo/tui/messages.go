@@ -2,7 +2,7 @@ package tui
 
 import "time"
 
-// Messages for the tea program (polling-based)
+// Messages for the tea program (event stream with polling fallback)
 
 // StatusUpdateMsg is sent when we receive status from orchestrator
 type StatusUpdateMsg struct {
@@ -19,3 +19,28 @@ type TickMsg struct {
 type StartWorkflowMsg struct {
 	Err error
 }
+
+// EventsConnectedMsg is sent when an event stream connection attempt completes
+type EventsConnectedMsg struct {
+	Events <-chan Event
+	Err    error
+}
+
+// EventMsg is sent for each event received from the orchestrator stream
+type EventMsg struct {
+	Event Event
+}
+
+// EventsClosedMsg is sent when the event stream ends
+type EventsClosedMsg struct{}
+
+// CandidatesMsg is sent when we receive the approval queue from orchestrator
+type CandidatesMsg struct {
+	Candidates []Candidate
+	Err        error
+}
+
+// CandidateDecisionMsg is sent when an approve/reject request completes
+type CandidateDecisionMsg struct {
+	Err error
+}
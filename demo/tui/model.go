@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -14,14 +15,16 @@ import (
 type State = types.State
 
 const (
-	StateIdle          = types.StateIdle
-	StateClearing      = types.StateClearing
-	StateFetching      = types.StateFetching
-	StateDeduplicating = types.StateDeduplicating
-	StateSending       = types.StateSending
-	StateWaiting       = types.StateWaiting
-	StateComplete      = types.StateComplete
-	StateError         = types.StateError
+	StateIdle             = types.StateIdle
+	StateClearing         = types.StateClearing
+	StateFetching         = types.StateFetching
+	StateDeduplicating    = types.StateDeduplicating
+	StateAwaitingApproval = types.StateAwaitingApproval
+	StateSending          = types.StateSending
+	StateWaiting          = types.StateWaiting
+	StateComplete         = types.StateComplete
+	StateFailed           = types.StateFailed
+	StateError            = types.StateError
 )
 
 // LogEntry represents a single log line with timestamp
@@ -33,12 +36,28 @@ type WebhookPayload = types.WebhookPayload
 // StatusResponse is the JSON response from orchestrator
 type StatusResponse = types.StatusResponse
 
+// Candidate is a story waiting for editorial approval
+type Candidate = types.Candidate
+
+// CandidatesResponse is the JSON response for the approval queue
+type CandidatesResponse = types.CandidatesResponse
+
+// Event is a single message on the orchestrator event stream
+type Event = types.Event
+
+// maxLogs matches the orchestrator's shared log buffer
+const maxLogs = 50
+
+// streamRetryInterval is how often the event stream is retried while polling
+const streamRetryInterval = 5 * time.Second
+
 // Model represents the TUI client state (thin client)
 type Model struct {
 	// Orchestrator client
 	OrchestratorClient *OrchestratorClient
 
 	// Local UI state (synced from orchestrator)
+	RunID          string
 	State          State
 	Logs           []LogEntry
 	ArticleCount   int
@@ -46,14 +65,27 @@ type Model struct {
 	DuplicateCount int
 	GenerationUUID string
 	WebhookPayload *WebhookPayload
+	DryRun         bool
+	Preview        []Candidate // Stories a dry run would have sent
 	Err            error
 
 	// Connection status
 	Connected bool
 
+	// Event stream; the model polls /api/status while it isn't streaming
+	Streaming         bool
+	events            <-chan Event
+	lastEventSeq      uint64
+	lastStreamAttempt time.Time
+
 	// Feed selection
 	AvailableFeeds []rss.FeedConfig
 
+	// Approval view
+	ShowApprovals     bool
+	Candidates        []Candidate
+	SelectedCandidate int
+
 	// Exit code for the application
 	ExitCode int
 }
@@ -81,9 +113,10 @@ func NewModel(orchestratorURL string) Model {
 
 // Init implements tea.Model interface
 func (m Model) Init() tea.Cmd {
-	// Start polling immediately
+	// Load the current status, then follow the event stream
 	return tea.Batch(
 		pollStatus(m.OrchestratorClient),
+		connectEvents(m.OrchestratorClient, 0, false),
 		tickCmd(),
 	)
 }
@@ -97,19 +130,32 @@ func (m Model) getStateText() string {
 	switch m.State {
 	case StateIdle:
 		return HighlightStyle.Render("👋 Ready to start!") + "\n\n" +
-			InfoStyle.Render(TextStartInstruction)
+			InfoStyle.Render(TextStartInstruction) + "\n" +
+			InfoStyle.Render(TextDryRunInstruction)
 	case StateClearing:
 		return StatusStyle.Render("🧹 Clearing ChromaDB cache...")
 	case StateFetching:
 		return StatusStyle.Render("⏳ Fetching RSS feed...")
 	case StateDeduplicating:
 		return StatusStyle.Render("🔍 Deduplicating articles...")
+	case StateAwaitingApproval:
+		return StatusStyle.Render("🗳️  Waiting for editorial approval...") + "\n\n" +
+			InfoStyle.Render(TextApprovalInstruction)
 	case StateSending:
 		return StatusStyle.Render("📤 Sending to generation service...")
 	case StateWaiting:
 		return StatusStyle.Render(fmt.Sprintf("⏰ Waiting for generation service (UUID: %s)...", m.GenerationUUID))
 	case StateComplete:
+		if m.DryRun {
+			return HighlightStyle.Render("✅ DRY RUN COMPLETE (nothing was written or sent)")
+		}
 		return HighlightStyle.Render("✅ COMPLETE")
+	case StateFailed:
+		errMsg := "Generation failed"
+		if m.Err != nil {
+			errMsg = m.Err.Error()
+		}
+		return ErrorStyle.Render(fmt.Sprintf("❌ Failed: %v", errMsg))
 	case StateError:
 		errMsg := "Unknown error"
 		if m.Err != nil {
@@ -121,6 +167,62 @@ func (m Model) getStateText() string {
 	}
 }
 
+// formatCandidates formats the approval queue for display
+func (m Model) formatCandidates() string {
+	var b strings.Builder
+
+	b.WriteString(HighlightStyle.Render(fmt.Sprintf("Approval Queue (%d pending)", len(m.Candidates))))
+	b.WriteString("\n\n")
+
+	if len(m.Candidates) == 0 {
+		b.WriteString(InfoStyle.Render("Nothing waiting for approval."))
+		return b.String()
+	}
+
+	for i, c := range m.Candidates {
+		cursor := "  "
+		title := c.Title
+		if i == m.SelectedCandidate {
+			cursor = "▶ "
+			title = StatusStyle.Render(title)
+		}
+		b.WriteString(fmt.Sprintf("%s%s\n", cursor, title))
+		b.WriteString(InfoStyle.Render(fmt.Sprintf("    %s | %d source(s) | %s", c.Feed, c.SourceCount, c.Explanation)))
+		b.WriteString("\n")
+		if i == m.SelectedCandidate && c.Excerpt != "" {
+			b.WriteString(InfoStyle.Render("    " + c.Excerpt))
+			b.WriteString("\n")
+		}
+	}
+
+	return b.String()
+}
+
+// formatPreview formats the stories a dry run would have sent
+func (m Model) formatPreview() string {
+	var b strings.Builder
+
+	b.WriteString(HighlightStyle.Render(fmt.Sprintf("Dry Run: %d stories would be generated", len(m.Preview))))
+	b.WriteString("\n\n")
+
+	if len(m.Preview) == 0 {
+		b.WriteString(InfoStyle.Render("No new stories would be sent."))
+		return b.String()
+	}
+
+	for _, c := range m.Preview {
+		b.WriteString(fmt.Sprintf("• %s\n", c.Title))
+		details := fmt.Sprintf("    %s | %d source(s)", c.Feed, c.SourceCount)
+		if c.Status == types.CandidatePending {
+			details += " | needs approval"
+		}
+		b.WriteString(InfoStyle.Render(details))
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
 // formatWebhookResult formats the webhook payload for display
 func (m Model) formatWebhookResult() string {
 	payload := m.WebhookPayload
@@ -8,8 +8,11 @@ const (
 	TextDetachInstruction   = "Press 'q' to detach (orchestrator keeps running)"
 	TextShutdownInstruction = "Press 'x' to shutdown orchestrator and quit"
 	TextCronNote            = "Note: Orchestrator runs automatically on a schedule (cron)."
+	TextApprovalInstruction = "Press 'a' to review candidates"
+	TextDryRunInstruction   = "Press 'p' to preview a run (dry run, no side effects)"
 
 	// Footer
-	TextFooterIdle    = "d: Fetch New | r: Reset & Fetch | q: Detach | x: Shutdown"
-	TextFooterRunning = "q: Detach (workflow continues) | x: Shutdown Orchestrator"
+	TextFooterIdle     = "d: Fetch New | r: Reset & Fetch | p: Dry Run | a: Approvals | q: Detach | x: Shutdown"
+	TextFooterRunning  = "a: Approvals | q: Detach (workflow continues) | x: Shutdown Orchestrator"
+	TextFooterApproval = "↑/↓: Select | y: Approve | n: Reject | a/esc: Back"
 )
@@ -1,12 +1,13 @@
 package tui
 
 import (
+	"brainbot/shared/types"
 	"fmt"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
 
-// Update implements tea.Model interface (polling-based version)
+// Update implements tea.Model interface (event stream with polling fallback)
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
@@ -18,12 +19,47 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case StartWorkflowMsg:
 		return m.handleStartWorkflow(msg)
 
+	case CandidatesMsg:
+		return m.handleCandidates(msg)
+
+	case EventsConnectedMsg:
+		if msg.Err != nil {
+			m.Streaming = false
+			return m, nil
+		}
+		m.Streaming = true
+		m.events = msg.Events
+		return m, waitForEvent(m.events)
+
+	case EventsClosedMsg:
+		// Fall back to polling until the stream reconnects
+		m.Streaming = false
+		m.events = nil
+		return m, nil
+
+	case EventMsg:
+		return m.handleEvent(msg.Event)
+
+	case CandidateDecisionMsg:
+		if msg.Err != nil {
+			m.Err = fmt.Errorf("failed to record decision: %w", msg.Err)
+		}
+		return m, pollCandidates(m.OrchestratorClient)
+
 	case TickMsg:
-		// Poll again
-		return m, tea.Batch(
-			pollStatus(m.OrchestratorClient),
-			tickCmd(),
-		)
+		cmds := []tea.Cmd{tickCmd()}
+		if !m.Streaming {
+			// Poll while the stream is down, and retry it now and then
+			cmds = append(cmds, pollStatus(m.OrchestratorClient))
+			if msg.Time.Sub(m.lastStreamAttempt) >= streamRetryInterval {
+				m.lastStreamAttempt = msg.Time
+				cmds = append(cmds, connectEvents(m.OrchestratorClient, m.lastEventSeq, m.lastEventSeq > 0))
+			}
+		}
+		if m.ShowApprovals {
+			cmds = append(cmds, pollCandidates(m.OrchestratorClient))
+		}
+		return m, tea.Batch(cmds...)
 	}
 
 	return m, nil
@@ -31,6 +67,10 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 // handleKeyPress processes keyboard input
 func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.ShowApprovals {
+		return m.handleApprovalKeyPress(msg)
+	}
+
 	switch msg.String() {
 	case "ctrl+c", "q":
 		// Just quit the TUI - orchestrator keeps running
@@ -43,13 +83,27 @@ func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	case "d", "D":
 		// Fetch new articles (incremental)
-		if m.Connected && (m.State == StateIdle || m.State == StateComplete || m.State == StateError) {
+		if m.Connected && (m.State == StateIdle || m.State == StateComplete || m.State == StateFailed || m.State == StateError) {
 			return m, triggerFetchNew(m.OrchestratorClient, "")
 		}
 
+	case "p", "P":
+		// Preview a run without clearing, writing or sending anything
+		if m.Connected && (m.State == StateIdle || m.State == StateComplete || m.State == StateFailed || m.State == StateError) {
+			return m, triggerDryRun(m.OrchestratorClient, "")
+		}
+
+	case "a", "A":
+		// Open the approval queue
+		if m.Connected {
+			m.ShowApprovals = true
+			m.SelectedCandidate = 0
+			return m, pollCandidates(m.OrchestratorClient)
+		}
+
 	case "r", "R":
 		// Reset and fetch (clears cache)
-		if m.Connected && (m.State == StateIdle || m.State == StateComplete || m.State == StateError) {
+		if m.Connected && (m.State == StateIdle || m.State == StateComplete || m.State == StateFailed || m.State == StateError) {
 			return m, triggerResetAndFetch(m.OrchestratorClient, "")
 		}
 	}
@@ -70,6 +124,7 @@ func (m Model) handleStatusUpdate(msg StatusUpdateMsg) (tea.Model, tea.Cmd) {
 
 	// Update local state from orchestrator
 	status := msg.Status
+	m.RunID = status.RunID
 	m.State = status.State
 	m.Logs = status.Logs
 	m.ArticleCount = status.ArticleCount
@@ -77,6 +132,8 @@ func (m Model) handleStatusUpdate(msg StatusUpdateMsg) (tea.Model, tea.Cmd) {
 	m.DuplicateCount = status.DuplicateCount
 	m.GenerationUUID = status.GenerationUUID
 	m.WebhookPayload = status.WebhookPayload
+	m.DryRun = status.DryRun
+	m.Preview = status.Preview
 
 	if status.Error != "" {
 		m.Err = fmt.Errorf("%s", status.Error)
@@ -87,6 +144,53 @@ func (m Model) handleStatusUpdate(msg StatusUpdateMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// handleEvent applies a streamed event to the model
+func (m Model) handleEvent(event Event) (tea.Model, tea.Cmd) {
+	m.lastEventSeq = event.Seq
+	next := waitForEvent(m.events)
+
+	switch event.Type {
+	case types.EventResync:
+		// Events were missed; reload the full status
+		return m, tea.Batch(next, pollStatus(m.OrchestratorClient))
+
+	case types.EventLog:
+		if event.Log != nil {
+			m.Logs = append(m.Logs, *event.Log)
+			if len(m.Logs) > maxLogs {
+				m.Logs = m.Logs[len(m.Logs)-maxLogs:]
+			}
+		}
+
+	case types.EventState:
+		if event.RunID != m.RunID || event.State.IsTerminal() {
+			// A new run started, or this one finished: reload counts and results
+			return m, tea.Batch(next, pollStatus(m.OrchestratorClient))
+		}
+		m.State = event.State
+
+	case types.EventArticle:
+		if event.RunID == m.RunID && event.Article != nil {
+			switch event.Article.Status {
+			case "new":
+				m.NewCount++
+			case "duplicate":
+				m.DuplicateCount++
+			}
+		}
+
+	case types.EventGeneration:
+		if event.RunID == m.RunID && event.Generation != nil {
+			m.GenerationUUID = event.Generation.UUID
+			if event.Generation.WebhookPayload != nil {
+				m.WebhookPayload = event.Generation.WebhookPayload
+			}
+		}
+	}
+
+	return m, next
+}
+
 // handleStartWorkflow processes workflow start response
 func (m Model) handleStartWorkflow(msg StartWorkflowMsg) (tea.Model, tea.Cmd) {
 	if msg.Err != nil {
@@ -95,3 +199,50 @@ func (m Model) handleStartWorkflow(msg StartWorkflowMsg) (tea.Model, tea.Cmd) {
 	// Status will be updated via next poll
 	return m, nil
 }
+
+// handleApprovalKeyPress processes keyboard input in the approval view
+func (m Model) handleApprovalKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c", "q":
+		return m, tea.Quit
+
+	case "a", "A", "esc":
+		m.ShowApprovals = false
+
+	case "up", "k":
+		if m.SelectedCandidate > 0 {
+			m.SelectedCandidate--
+		}
+
+	case "down", "j":
+		if m.SelectedCandidate < len(m.Candidates)-1 {
+			m.SelectedCandidate++
+		}
+
+	case "y", "Y", "n", "N":
+		if m.SelectedCandidate < len(m.Candidates) {
+			candidate := m.Candidates[m.SelectedCandidate]
+			approve := msg.String() == "y" || msg.String() == "Y"
+			return m, decideCandidate(m.OrchestratorClient, candidate.ID, approve)
+		}
+	}
+
+	return m, nil
+}
+
+// handleCandidates processes the approval queue from orchestrator
+func (m Model) handleCandidates(msg CandidatesMsg) (tea.Model, tea.Cmd) {
+	if msg.Err != nil {
+		m.Err = fmt.Errorf("failed to load candidates: %w", msg.Err)
+		return m, nil
+	}
+
+	m.Candidates = msg.Candidates
+	if m.SelectedCandidate >= len(m.Candidates) {
+		m.SelectedCandidate = len(m.Candidates) - 1
+	}
+	if m.SelectedCandidate < 0 {
+		m.SelectedCandidate = 0
+	}
+	return m, nil
+}
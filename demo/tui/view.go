@@ -15,7 +15,11 @@ func (m Model) View() string {
 
 	// Connection status
 	if m.Connected {
-		b.WriteString(InfoStyle.Render("🟢 Connected to orchestrator"))
+		if m.Streaming {
+			b.WriteString(InfoStyle.Render("🟢 Connected to orchestrator (live)"))
+		} else {
+			b.WriteString(InfoStyle.Render("🟢 Connected to orchestrator (polling)"))
+		}
 		b.WriteString("\n")
 		b.WriteString(InfoStyle.Render(TextCronNote))
 	} else {
@@ -73,15 +77,26 @@ func (m Model) View() string {
 		b.WriteString("\n")
 	}
 
+	// Approval queue replaces the results box while open
+	if m.ShowApprovals {
+		b.WriteString(BoxStyle.Render(m.formatCandidates()))
+		b.WriteString("\n\n")
+		b.WriteString(InfoStyle.Render(TextFooterApproval))
+		return b.String()
+	}
+
 	// Results
-	if m.State == StateComplete && m.WebhookPayload != nil {
+	if m.State == StateComplete && m.DryRun {
+		b.WriteString(BoxStyle.Render(m.formatPreview()))
+		b.WriteString("\n\n")
+	} else if m.State == StateComplete && m.WebhookPayload != nil {
 		resultBox := m.formatWebhookResult()
 		b.WriteString(BoxStyle.Render(resultBox))
 		b.WriteString("\n\n")
 	}
 
 	// Help text
-	if m.State == StateIdle || m.State == StateComplete || m.State == StateError {
+	if m.State == StateIdle || m.State == StateComplete || m.State == StateFailed || m.State == StateError {
 		b.WriteString(InfoStyle.Render(TextFooterIdle))
 	} else {
 		b.WriteString(InfoStyle.Render(TextFooterRunning))
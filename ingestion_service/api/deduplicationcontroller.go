@@ -2,9 +2,12 @@ package api
 
 import (
 	"brainbot/ingestion_service/deduplication"
+	"brainbot/ingestion_service/events"
 	"brainbot/ingestion_service/storage"
 	"brainbot/ingestion_service/types"
 	"context"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
@@ -15,47 +18,66 @@ import (
 )
 
 // RegisterDeduplicationRoutes registers deduplication service endpoints.
-func RegisterDeduplicationRoutes(r *gin.Engine) {
+// Processing outcomes are announced through publisher (nil disables).
+func RegisterDeduplicationRoutes(r *gin.Engine, publisher *events.Publisher) {
 	g := r.Group("/api/deduplication")
 	g.POST("/check", handleCheckDuplicate)
+	g.POST("/check-batch", handleCheckBatch)
 	g.POST("/add", handleAddArticle)
-	g.POST("/process", handleProcessArticle)
+	g.POST("/process", func(c *gin.Context) { handleProcessArticle(c, publisher) })
 	g.DELETE("/clear", handleClearCache)
 	g.GET("/count", handleGetCount)
 }
 
 // CheckDuplicateRequest represents the request to check for duplicates
 type CheckDuplicateRequest struct {
-	Article *types.Article `json:"article" binding:"required"`
+	Article   *types.Article `json:"article" binding:"required"`
+	Namespace string         `json:"namespace,omitempty"`
 }
 
 // CheckDuplicateResponse represents the response from duplicate check
 type CheckDuplicateResponse struct {
-	IsDuplicate     bool      `json:"is_duplicate"`
-	MatchingID      string    `json:"matching_id,omitempty"`
-	SimilarityScore float32   `json:"similarity_score,omitempty"`
-	CheckedAt       time.Time `json:"checked_at"`
+	IsDuplicate      bool      `json:"is_duplicate"`
+	IsExactDuplicate bool      `json:"is_exact_duplicate,omitempty"`
+	MatchingID       string    `json:"matching_id,omitempty"`
+	SimilarityScore  float32   `json:"similarity_score,omitempty"`
+	CheckedAt        time.Time `json:"checked_at"`
+}
+
+// CheckBatchRequest represents the request to check a batch of articles
+type CheckBatchRequest struct {
+	Articles  []*types.Article `json:"articles" binding:"required"`
+	Namespace string           `json:"namespace,omitempty"`
+}
+
+// CheckBatchResponse represents the response from a batch check, one result per article in order
+type CheckBatchResponse struct {
+	Namespace string                `json:"namespace"`
+	Results   []types.ArticleResult `json:"results"`
 }
 
 // AddArticleRequest represents the request to add an article
 type AddArticleRequest struct {
-	Article *types.Article `json:"article" binding:"required"`
+	Article   *types.Article `json:"article" binding:"required"`
+	Namespace string         `json:"namespace,omitempty"`
 }
 
 // ProcessArticleRequest represents the request to process (check + add if new)
 type ProcessArticleRequest struct {
-	Article *types.Article `json:"article" binding:"required"`
+	Article   *types.Article `json:"article" binding:"required"`
+	Namespace string         `json:"namespace,omitempty"`
 }
 
 // ProcessArticleResponse represents the response from processing an article
 type ProcessArticleResponse struct {
 	Status              string                             `json:"status"` // "new", "duplicate", "error"
+	Namespace           string                             `json:"namespace,omitempty"`
 	DeduplicationResult *deduplication.DeduplicationResult `json:"deduplication_result,omitempty"`
 	PresignedURL        string                             `json:"presigned_url,omitempty"`
 	Error               string                             `json:"error,omitempty"`
 }
 
-// handleCheckDuplicate checks if an article is a duplicate
+// handleCheckDuplicate checks if an article is a duplicate without modifying any dedup state
 func handleCheckDuplicate(c *gin.Context) {
 	var req CheckDuplicateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -63,29 +85,96 @@ func handleCheckDuplicate(c *gin.Context) {
 		return
 	}
 
-	deduplicator, err := initializeDeduplicator()
+	ns, err := requestNamespace(c, req.Namespace)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to initialize deduplicator: " + err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	deduplicator, err := initializeReadOnlyDeduplicator(ns)
+	if err != nil {
+		respondDeduplicatorInitError(c, err)
 		return
 	}
 	defer deduplicator.Close()
 
-	result, err := deduplicator.CheckForDuplicates(req.Article)
+	result, err := deduplicator.CheckArticle(c.Request.Context(), req.Article)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check duplicates: " + err.Error()})
 		return
 	}
 
 	response := CheckDuplicateResponse{
-		IsDuplicate:     result.IsDuplicate,
-		MatchingID:      result.MatchingID,
-		SimilarityScore: result.SimilarityScore,
-		CheckedAt:       result.CheckedAt,
+		IsDuplicate:      result.IsDuplicate,
+		IsExactDuplicate: result.IsExactDuplicate,
+		MatchingID:       result.MatchingID,
+		SimilarityScore:  result.SimilarityScore,
+		CheckedAt:        result.CheckedAt,
 	}
 
 	c.JSON(http.StatusOK, response)
 }
 
+// handleCheckBatch reports what processing a batch of articles in order would
+// decide, without modifying any dedup state. Articles are also compared with the
+// earlier articles of the batch, as processing would have stored those.
+func handleCheckBatch(c *gin.Context) {
+	var req CheckBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ns, err := requestNamespace(c, req.Namespace)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	deduplicator, err := initializeReadOnlyDeduplicator(ns)
+	if err != nil {
+		respondDeduplicatorInitError(c, err)
+		return
+	}
+	defer deduplicator.Close()
+
+	batch := deduplicator.NewBatchCheck()
+	results := make([]types.ArticleResult, 0, len(req.Articles))
+	for _, article := range req.Articles {
+		// Skip articles that failed extraction
+		if article.ExtractionError != "" {
+			results = append(results, types.ArticleResult{
+				Article: article,
+				Status:  "failed",
+				Error:   article.ExtractionError,
+			})
+			continue
+		}
+
+		result, err := batch.CheckArticle(c.Request.Context(), article)
+		if err != nil {
+			results = append(results, types.ArticleResult{
+				Article: article,
+				Status:  "error",
+				Error:   "failed to check duplicates: " + err.Error(),
+			})
+			continue
+		}
+
+		status := "new"
+		if result.IsDuplicate {
+			status = "duplicate"
+		}
+		results = append(results, types.ArticleResult{
+			Article:             article,
+			Status:              status,
+			DeduplicationResult: result,
+		})
+	}
+
+	c.JSON(http.StatusOK, CheckBatchResponse{Namespace: ns.Name, Results: results})
+}
+
 // handleAddArticle adds an article to the vector database
 func handleAddArticle(c *gin.Context) {
 	var req AddArticleRequest
@@ -94,9 +183,15 @@ func handleAddArticle(c *gin.Context) {
 		return
 	}
 
-	deduplicator, err := initializeDeduplicator()
+	ns, err := requestNamespace(c, req.Namespace)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to initialize deduplicator: " + err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	deduplicator, err := initializeDeduplicator(ns)
+	if err != nil {
+		respondDeduplicatorInitError(c, err)
 		return
 	}
 	defer deduplicator.Close()
@@ -110,53 +205,81 @@ func handleAddArticle(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"status":     "added",
 		"article_id": req.Article.ID,
+		"namespace":  ns.Name,
 	})
 }
 
 // handleProcessArticle processes an article (checks for duplicates and adds if new)
-func handleProcessArticle(c *gin.Context) {
+func handleProcessArticle(c *gin.Context, publisher *events.Publisher) {
 	var req ProcessArticleRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	deduplicator, err := initializeDeduplicator()
+	ns, err := requestNamespace(c, req.Namespace)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	origin := events.Origin{RequestID: requestID(c), Namespace: ns.Name}
+	response, status, err := processArticle(c.Request.Context(), ns, req.Article, origin, publisher)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to initialize deduplicator: " + err.Error()})
+		if response.Status == "error" {
+			c.JSON(status, response)
+		} else {
+			c.JSON(status, gin.H{"error": err.Error()})
+		}
 		return
 	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// processArticle checks an article for duplicates, adds it if new and stores
+// it in S3, then announces the outcome on the article topics. On failure the
+// returned status is the HTTP status describing it; a response with status
+// "error" means deduplication itself failed.
+func processArticle(ctx context.Context, ns deduplication.Namespace, article *types.Article, origin events.Origin, publisher *events.Publisher) (ProcessArticleResponse, int, error) {
+	deduplicator, err := initializeDeduplicator(ns)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, deduplication.ErrEmbeddingModelMismatch) {
+			status = http.StatusConflict
+		}
+		return ProcessArticleResponse{}, status, fmt.Errorf("failed to initialize deduplicator: %w", err)
+	}
 	defer deduplicator.Close()
 
-	s3Client, err := initializeS3(c.Request.Context())
+	s3Client, err := initializeS3(ctx)
 	if err != nil {
-		log.Printf("Warning: Failed to initialize S3: %v", err)
+		log.Printf("Warning: Failed to initialize S3 (request %s): %v", origin.RequestID, err)
 		// Proceeding without S3 might be critical failure depending on requirements.
 		// User said "we create a new s3 object", so it seems required.
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to initialize S3: " + err.Error()})
-		return
+		return ProcessArticleResponse{}, http.StatusInternalServerError, fmt.Errorf("failed to initialize S3: %w", err)
 	}
 
-	result, err := deduplicator.ProcessArticle(c.Request.Context(), req.Article)
+	result, err := deduplicator.ProcessArticle(ctx, article)
 	if err != nil {
 		response := ProcessArticleResponse{
-			Status: "error",
-			Error:  err.Error(),
+			Status:    "error",
+			Namespace: ns.Name,
+			Error:     err.Error(),
 		}
-		c.JSON(http.StatusInternalServerError, response)
-		return
+		return response, http.StatusInternalServerError, err
 	}
 
 	status := "new"
 	var presignedURL string
 
 	// Determine content to use
-	content := req.Article.FullContentText
+	content := article.FullContentText
 	if content == "" {
-		content = req.Article.FullContent
+		content = article.FullContent
 	}
 	if content == "" {
-		content = req.Article.Summary
+		content = article.Summary
 	}
 
 	if result.IsExactDuplicate {
@@ -166,9 +289,9 @@ func handleProcessArticle(c *gin.Context) {
 		status = "duplicate"
 		// Similar duplicate: Append to existing S3 object
 		if result.MatchingID != "" {
-			err := s3Client.AppendToArticleObject(c.Request.Context(), result.MatchingID, content)
+			err := s3Client.AppendToArticleObject(ctx, ns.ObjectID(result.MatchingID), content)
 			if err != nil {
-				log.Printf("Error appending to S3 for article %s (match %s): %v", req.Article.ID, result.MatchingID, err)
+				log.Printf("Error appending to S3 for article %s (match %s, request %s): %v", article.ID, result.MatchingID, origin.RequestID, err)
 				// We don't fail the request, but log the error
 			}
 		}
@@ -176,66 +299,65 @@ func handleProcessArticle(c *gin.Context) {
 		// New article
 		status = "new"
 		// Create new S3 object
-		err := s3Client.CreateArticleObject(c.Request.Context(), req.Article.ID, req.Article.Title, content)
+		err := s3Client.CreateArticleObject(ctx, ns.ObjectID(article.ID), article.Title, content)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create S3 object: " + err.Error()})
-			return
+			return ProcessArticleResponse{}, http.StatusInternalServerError, fmt.Errorf("failed to create S3 object: %w", err)
 		}
 
 		// Generate Pre-signed URL
-		presignedURL, err = s3Client.GeneratePresignedURL(c.Request.Context(), req.Article.ID, 12*time.Hour)
+		presignedURL, err = s3Client.GeneratePresignedURL(ctx, ns.ObjectID(article.ID), 12*time.Hour)
 		if err != nil {
-			log.Printf("Error generating presigned URL for article %s: %v", req.Article.ID, err)
+			log.Printf("Error generating presigned URL for article %s (request %s): %v", article.ID, origin.RequestID, err)
 		}
 	}
 
+	if err := publisher.PublishDeduplicated(origin, article, result, presignedURL); err != nil {
+		log.Printf("Failed to announce %s article %s (request %s): %v", status, article.ID, origin.RequestID, err)
+	}
+
 	response := ProcessArticleResponse{
 		Status:              status,
+		Namespace:           ns.Name,
 		DeduplicationResult: result,
 		PresignedURL:        presignedURL,
 	}
-
-	c.JSON(http.StatusOK, response)
+	return response, http.StatusOK, nil
 }
 
-// handleClearCache clears all documents from the ChromaDB collection
+// handleClearCache clears all documents from the namespace's ChromaDB collection and bloom filter
 func handleClearCache(c *gin.Context) {
-	chromaConfig := deduplication.ChromaConfig{
-		Host:           getEnvOrDefault("CHROMA_HOST", "localhost"),
-		Port:           getEnvPortOrDefault("CHROMA_PORT", 8000),
-		CollectionName: getEnvOrDefault("CHROMA_COLLECTION", "brainbot_articles"),
-		EmbeddingModel: "",
-	}
-
-	chroma, err := deduplication.NewChromaReadOnly(chromaConfig)
+	ns, err := requestNamespace(c, c.Query("namespace"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to connect to ChromaDB: " + err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	defer chroma.Close()
 
-	err = chroma.ClearCollection()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to clear cache: " + err.Error()})
+	chromaConfig := namespaceChromaConfig(ns)
+
+	// A missing collection holds nothing to clear; the bloom filter is cleared regardless
+	chroma, err := deduplication.NewChromaReadOnly(chromaConfig)
+	switch {
+	case errors.Is(err, deduplication.ErrCollectionNotFound):
+		log.Printf("Collection %s not found; nothing to clear", chromaConfig.CollectionName)
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to connect to ChromaDB: " + err.Error()})
 		return
+	default:
+		defer chroma.Close()
+		if err := chroma.ClearCollection(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to clear cache: " + err.Error()})
+			return
+		}
 	}
 
 	// Also clear Redis Bloom filter keys
-	redisConfig := deduplication.RedisConfig{
-		Addr:     getEnvOrDefault("REDIS_ADDR", "localhost:6379"),
-		Password: getEnvOrDefault("REDIS_PASSWORD", ""),
-		DB:       getEnvPortOrDefault("REDIS_DB", 0),
-	}
-
 	deduplicator, err := deduplication.NewDeduplicator(deduplication.DeduplicatorConfig{
-		ChromaConfig: chromaConfig,
-		RedisConfig:  redisConfig,
+		ChromaConfig:   chromaConfig,
+		RedisConfig:    redisConfigFromEnv(),
+		BloomKeyPrefix: ns.BloomKeyPrefix,
 	})
 	if err == nil {
 		defer deduplicator.Close()
-		// We need to expose a method to clear Redis keys in Deduplicator, or do it manually here.
-		// Since Deduplicator struct has private redis client, we should add a method there.
-		// For now, let's assume we can add a method ClearBloomFilter to Deduplicator.
 		if err := deduplicator.ClearBloomFilter(c.Request.Context()); err != nil {
 			log.Printf("Warning: Failed to clear Redis Bloom filter: %v", err)
 		}
@@ -244,20 +366,27 @@ func handleClearCache(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"status": "cleared",
+		"status":    "cleared",
+		"namespace": ns.Name,
 	})
 }
 
-// handleGetCount returns the number of documents in the collection
+// handleGetCount returns the number of documents in the namespace's collection
 func handleGetCount(c *gin.Context) {
-	chromaConfig := deduplication.ChromaConfig{
-		Host:           getEnvOrDefault("CHROMA_HOST", "localhost"),
-		Port:           getEnvPortOrDefault("CHROMA_PORT", 8000),
-		CollectionName: getEnvOrDefault("CHROMA_COLLECTION", "brainbot_articles"),
-		EmbeddingModel: "",
+	ns, err := requestNamespace(c, c.Query("namespace"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
-	chroma, err := deduplication.NewChromaReadOnly(chromaConfig)
+	chroma, err := deduplication.NewChromaReadOnly(namespaceChromaConfig(ns))
+	if errors.Is(err, deduplication.ErrCollectionNotFound) {
+		c.JSON(http.StatusOK, gin.H{
+			"count":     0,
+			"namespace": ns.Name,
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to connect to ChromaDB: " + err.Error()})
 		return
@@ -271,35 +400,72 @@ func handleGetCount(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"count": count,
+		"count":     count,
+		"namespace": ns.Name,
 	})
 }
 
-// Helper function to initialize deduplicator with configuration from environment
-func initializeDeduplicator() (*deduplication.Deduplicator, error) {
-	chromaConfig := deduplication.ChromaConfig{
+// requestNamespace resolves the namespace from the request body/query value,
+// falling back to the X-Dedup-Namespace header and then the default namespace.
+func requestNamespace(c *gin.Context, value string) (deduplication.Namespace, error) {
+	if value == "" {
+		value = c.GetHeader(types.NamespaceHeader)
+	}
+	return deduplication.ResolveNamespace(value, getEnvOrDefault("CHROMA_COLLECTION", "brainbot_articles"))
+}
+
+// namespaceChromaConfig builds the Chroma connection settings for a namespace
+func namespaceChromaConfig(ns deduplication.Namespace) deduplication.ChromaConfig {
+	return deduplication.ChromaConfig{
 		Host:           getEnvOrDefault("CHROMA_HOST", "localhost"),
 		Port:           getEnvPortOrDefault("CHROMA_PORT", 8000),
-		CollectionName: getEnvOrDefault("CHROMA_COLLECTION", "brainbot_articles"),
+		Tenant:         ns.Tenant,
+		Database:       ns.Database,
+		CollectionName: ns.Collection,
 		EmbeddingModel: "",
 	}
+}
 
-	redisConfig := deduplication.RedisConfig{
+func redisConfigFromEnv() deduplication.RedisConfig {
+	return deduplication.RedisConfig{
 		Addr:     getEnvOrDefault("REDIS_ADDR", "localhost:6379"),
 		Password: getEnvOrDefault("REDIS_PASSWORD", ""),
 		DB:       getEnvPortOrDefault("REDIS_DB", 0),
 	}
+}
+
+// respondDeduplicatorInitError reports a deduplicator initialization failure.
+// A collection built with a different embedding model is a configuration conflict, not a server fault.
+func respondDeduplicatorInitError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	if errors.Is(err, deduplication.ErrEmbeddingModelMismatch) {
+		status = http.StatusConflict
+	}
+	c.JSON(status, gin.H{"error": "failed to initialize deduplicator: " + err.Error()})
+}
 
+// Helper function to initialize a deduplicator for a namespace with configuration from environment
+func initializeDeduplicator(ns deduplication.Namespace) (*deduplication.Deduplicator, error) {
 	deduplicatorConfig := deduplication.DeduplicatorConfig{
-		ChromaConfig:        chromaConfig,
-		RedisConfig:         redisConfig,
+		ChromaConfig:        namespaceChromaConfig(ns),
+		RedisConfig:         redisConfigFromEnv(),
 		SimilarityThreshold: 0, // Use default
 		MaxSearchResults:    0, // Use default
+		BloomKeyPrefix:      ns.BloomKeyPrefix,
 	}
 
 	return deduplication.NewDeduplicator(deduplicatorConfig)
 }
 
+// initializeReadOnlyDeduplicator initializes a deduplicator for checks that never write dedup state
+func initializeReadOnlyDeduplicator(ns deduplication.Namespace) (*deduplication.Deduplicator, error) {
+	return deduplication.NewReadOnlyDeduplicator(deduplication.DeduplicatorConfig{
+		ChromaConfig:   namespaceChromaConfig(ns),
+		RedisConfig:    redisConfigFromEnv(),
+		BloomKeyPrefix: ns.BloomKeyPrefix,
+	})
+}
+
 func initializeS3(ctx context.Context) (*storage.S3Client, error) {
 	bucket := getEnvOrDefault("S3_BUCKET", "")
 	if bucket == "" {
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"brainbot/ingestion_service/deduplication"
+	"brainbot/ingestion_service/events"
+	"brainbot/ingestion_service/jobs"
+	"brainbot/ingestion_service/rssfeeds"
+	"brainbot/ingestion_service/types"
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterJobRoutes registers the asynchronous job endpoints
+func RegisterJobRoutes(r *gin.Engine, manager *jobs.Manager) {
+	group := r.Group("/api/jobs")
+	group.POST("/fetch", func(c *gin.Context) { handleSubmitFetchJob(c, manager) })
+	group.GET("/:id", func(c *gin.Context) { handleGetJob(c, manager) })
+}
+
+// handleSubmitFetchJob queues a feed fetch plus content extraction (and
+// optionally deduplication) and returns immediately with the job ID
+func handleSubmitFetchJob(c *gin.Context, manager *jobs.Manager) {
+	var req types.FetchJobRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.FeedPreset == "" {
+		req.FeedPreset = rssfeeds.DefaultFeedPreset
+	}
+	if req.Count == 0 {
+		req.Count = rssfeeds.DefaultCount
+	}
+
+	if req.Process && req.DryRun {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "a dry-run job can't process articles"})
+		return
+	}
+
+	if req.Process {
+		ns, err := requestNamespace(c, req.Namespace)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		req.Namespace = ns.Name
+	}
+
+	job := manager.SubmitFetch(req, requestID(c))
+	c.Header("Location", "/api/jobs/"+job.ID)
+	c.JSON(http.StatusAccepted, job)
+}
+
+// handleGetJob reports a job's status and progress, and its articles once it succeeds
+func handleGetJob(c *gin.Context, manager *jobs.Manager) {
+	job, ok := manager.Get(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "job not found"})
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
+
+// processJobArticle deduplicates an article for a process job
+func processJobArticle(publisher *events.Publisher) jobs.ProcessFunc {
+	return func(ctx context.Context, namespace string, article *types.Article, origin events.Origin) types.ArticleResult {
+		ns, err := deduplication.ResolveNamespace(namespace, getEnvOrDefault("CHROMA_COLLECTION", "brainbot_articles"))
+		if err != nil {
+			return types.ArticleResult{Article: article, Status: "error", Error: err.Error()}
+		}
+
+		response, _, err := processArticle(ctx, ns, article, origin, publisher)
+		if err != nil {
+			return types.ArticleResult{Article: article, Status: "error", Error: err.Error()}
+		}
+		return types.ArticleResult{
+			Article:             article,
+			Status:              response.Status,
+			DeduplicationResult: response.DeduplicationResult,
+			PresignedURL:        response.PresignedURL,
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	sharedTypes "brainbot/shared/types"
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// requestIDKey is the gin context key holding the request ID
+const requestIDKey = "request_id"
+
+// requestIDMiddleware tags every request with an X-Request-ID, reusing the
+// caller's ID so orchestrator and ingestion logs can be correlated
+func requestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(sharedTypes.RequestIDHeader)
+		if id == "" {
+			id = uuid.NewString()
+		}
+		c.Set(requestIDKey, id)
+		c.Header(sharedTypes.RequestIDHeader, id)
+		c.Next()
+	}
+}
+
+// requestLogger is gin's default access log line with the request ID appended
+func requestLogger() gin.HandlerFunc {
+	return gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
+		return fmt.Sprintf("[GIN] %v | %3d | %13v | %15s | %-7s %#v | request_id=%v\n%s",
+			param.TimeStamp.Format(time.DateTime),
+			param.StatusCode,
+			param.Latency,
+			param.ClientIP,
+			param.Method,
+			param.Path,
+			param.Keys[requestIDKey],
+			param.ErrorMessage,
+		)
+	})
+}
+
+// requestID returns the current request's ID for log lines
+func requestID(c *gin.Context) string {
+	return c.GetString(requestIDKey)
+}
@@ -1,8 +1,10 @@
 package api
 
 import (
+	"brainbot/ingestion_service/events"
 	"brainbot/ingestion_service/rssfeeds"
 	"brainbot/shared/rss"
+	"log"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -11,10 +13,13 @@ import (
 type FetchRequest struct {
 	FeedPreset string `json:"feed_preset"`
 	Count      int    `json:"count"`
+	DryRun     bool   `json:"dry_run,omitempty"` // Don't announce the articles
 }
 
-func RegisterRSSRoutes(r *gin.Engine) {
-	r.POST("/fetch", FetchArticles)
+// RegisterRSSRoutes registers the feed endpoints. Fetched articles are
+// announced through publisher (nil disables).
+func RegisterRSSRoutes(r *gin.Engine, publisher *events.Publisher) {
+	r.POST("/fetch", func(c *gin.Context) { FetchArticles(c, publisher) })
 	r.GET("/presets", GetPresets)
 }
 
@@ -22,7 +27,7 @@ func GetPresets(c *gin.Context) {
 	c.JSON(http.StatusOK, rss.FeedPresets)
 }
 
-func FetchArticles(c *gin.Context) {
+func FetchArticles(c *gin.Context, publisher *events.Publisher) {
 	var req FetchRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -46,5 +51,12 @@ func FetchArticles(c *gin.Context) {
 	// Extract full content for all articles
 	rssfeeds.ExtractAllContent(articles)
 
+	if !req.DryRun {
+		origin := events.Origin{RequestID: requestID(c), FeedPreset: req.FeedPreset}
+		if err := publisher.PublishFetched(origin, articles); err != nil {
+			log.Printf("Failed to announce fetched articles (request %s): %v", origin.RequestID, err)
+		}
+	}
+
 	c.JSON(http.StatusOK, articles)
 }
@@ -1,19 +1,33 @@
 package api
 
 import (
+	"brainbot/ingestion_service/events"
+	"brainbot/ingestion_service/jobs"
+	"log"
+
 	"github.com/gin-gonic/gin"
 )
 
-// NewRouter constructs a Gin engine with registered routes.
-func NewRouter() *gin.Engine {
+// NewRouter constructs a Gin engine with registered routes. The returned
+// close function flushes and closes the event publisher; call it once the
+// server has shut down.
+func NewRouter() (*gin.Engine, func() error) {
 	r := gin.New()
-	// Minimal middleware: recovery; logger optional to reduce verbosity
-	r.Use(gin.Logger())
+	// Minimal middleware: request IDs, access log and recovery
+	r.Use(requestIDMiddleware())
+	r.Use(requestLogger())
 	r.Use(gin.Recovery())
 
+	// Article and job events are published to Kafka when it is configured
+	publisher, err := events.NewPublisherFromEnv()
+	if err != nil {
+		log.Printf("Warning: %v (events disabled)", err)
+	}
+
 	// Register resource routers
-	RegisterDeduplicationRoutes(r)
+	RegisterDeduplicationRoutes(r, publisher)
 	RegisterHealthRoutes(r)
-	RegisterRSSRoutes(r)
-	return r
+	RegisterRSSRoutes(r, publisher)
+	RegisterJobRoutes(r, jobs.NewManager(jobs.ConfigFromEnv(publisher, processJobArticle(publisher))))
+	return r, publisher.Close
 }
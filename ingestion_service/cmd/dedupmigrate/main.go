@@ -0,0 +1,203 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+
+	"brainbot/ingestion_service/deduplication"
+
+	"github.com/joho/godotenv"
+)
+
+// dedupmigrate exports a deduplication collection to JSONL and re-imports it into a
+// collection tied to the currently configured embeddings provider. Collections
+// created before models were recorded can instead be labelled with the current
+// provider's model when they are known to have been built with it.
+//
+//	go run ./ingestion_service/cmd/dedupmigrate export -out articles.jsonl
+//	go run ./ingestion_service/cmd/dedupmigrate import -in articles.jsonl
+//	go run ./ingestion_service/cmd/dedupmigrate adopt
+func main() {
+	_ = godotenv.Load()
+
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "export":
+		runExport(os.Args[2:])
+	case "import":
+		runImport(os.Args[2:])
+	case "adopt":
+		runAdopt(os.Args[2:])
+	default:
+		usage()
+		os.Exit(2)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: dedupmigrate <export|import|adopt> [flags]")
+	fmt.Fprintln(os.Stderr, "  export  write ids, metadata and documents of a collection to JSONL")
+	fmt.Fprintln(os.Stderr, "  import  re-embed a JSONL export into a model-specific collection")
+	fmt.Fprintln(os.Stderr, "  adopt   record the configured model on a collection that has none")
+}
+
+func runExport(args []string) {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	namespace := fs.String("namespace", "", "Dedup namespace to export (defaults to the default namespace)")
+	collection := fs.String("collection", "", "Collection to export (overrides the namespace's collection)")
+	out := fs.String("out", "", "Output JSONL file (defaults to stdout)")
+	batchSize := fs.Int("batch-size", deduplication.DefaultMigrationBatchSize, "Documents fetched per request")
+	fs.Parse(args)
+
+	ns := resolveNamespace(*namespace)
+	if *collection != "" {
+		ns.Collection = *collection
+	}
+
+	// Read-only wrapper: exporting must work even when the configured provider no longer matches
+	chroma, err := deduplication.NewChromaReadOnly(chromaConfig(ns))
+	if err != nil {
+		log.Fatalf("failed to open collection %s: %v", ns.Collection, err)
+	}
+	defer chroma.Close()
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		file, err := os.Create(*out)
+		if err != nil {
+			log.Fatalf("failed to create %s: %v", *out, err)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	log.Printf("Exporting collection %s (embedding model: %s)", ns.Collection, chroma.GetEmbeddingModel())
+	count, err := deduplication.ExportCollection(chroma, w, *batchSize)
+	if err != nil {
+		log.Fatalf("export failed after %d documents: %v", count, err)
+	}
+}
+
+func runImport(args []string) {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	in := fs.String("in", "", "Input JSONL file produced by export (required)")
+	namespace := fs.String("namespace", "", "Dedup namespace whose tenant/database to import into")
+	collection := fs.String("collection", "", "Target collection (defaults to <namespace collection>_<model>)")
+	batchSize := fs.Int("batch-size", deduplication.DefaultMigrationBatchSize, "Documents re-embedded per request")
+	fs.Parse(args)
+
+	if *in == "" {
+		fs.Usage()
+		log.Fatal("-in is required")
+	}
+
+	embedder := deduplication.NewDefaultEmbeddingsProvider("")
+	if embedder == nil {
+		log.Fatal("no embeddings provider configured: set COHERE_API_KEY or OPENAI_API_KEY")
+	}
+
+	ns := resolveNamespace(*namespace)
+	target := *collection
+	if target == "" {
+		target = deduplication.ModelCollectionName(ns.Collection, embedder.ModelName())
+	}
+	ns.Collection = target
+
+	file, err := os.Open(*in)
+	if err != nil {
+		log.Fatalf("failed to open %s: %v", *in, err)
+	}
+	defer file.Close()
+
+	chroma, err := deduplication.NewChroma(chromaConfig(ns))
+	if err != nil {
+		log.Fatalf("failed to open target collection %s: %v", target, err)
+	}
+	defer chroma.Close()
+
+	log.Printf("Importing into collection %s with %s", target, chroma.GetEmbeddingModel())
+	count, err := deduplication.ImportCollection(chroma, file, *batchSize)
+	if err != nil {
+		log.Fatalf("import failed after %d documents: %v", count, err)
+	}
+
+	if ns.Name == deduplication.DefaultNamespace {
+		log.Printf("Done. Point the ingestion service at it with CHROMA_COLLECTION=%s", target)
+	} else {
+		log.Printf("Done. Point the %s namespace at it with CHROMA_COLLECTION%s=%s",
+			ns.Name, deduplication.EnvSuffix(ns.Name), target)
+	}
+}
+
+func runAdopt(args []string) {
+	fs := flag.NewFlagSet("adopt", flag.ExitOnError)
+	namespace := fs.String("namespace", "", "Dedup namespace to adopt (defaults to the default namespace)")
+	collection := fs.String("collection", "", "Collection to adopt (overrides the namespace's collection)")
+	fs.Parse(args)
+
+	ns := resolveNamespace(*namespace)
+	if *collection != "" {
+		ns.Collection = *collection
+	}
+
+	if deduplication.NewDefaultEmbeddingsProvider("") == nil {
+		log.Fatal("no embeddings provider configured: set COHERE_API_KEY or OPENAI_API_KEY")
+	}
+
+	// The collection must already exist; adopting never creates one
+	if _, err := deduplication.NewChromaReadOnly(chromaConfig(ns)); err != nil {
+		log.Fatalf("failed to open collection %s: %v", ns.Collection, err)
+	}
+
+	config := chromaConfig(ns)
+	config.AdoptUnlabelled = true
+	chroma, err := deduplication.NewChroma(config)
+	if err != nil {
+		log.Fatalf("failed to adopt collection %s: %v", ns.Collection, err)
+	}
+	defer chroma.Close()
+
+	log.Printf("Collection %s uses embedding model %s", ns.Collection, chroma.GetEmbeddingModel())
+}
+
+func resolveNamespace(name string) deduplication.Namespace {
+	ns, err := deduplication.ResolveNamespace(name, getEnvOrDefault("CHROMA_COLLECTION", "brainbot_articles"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	return ns
+}
+
+func chromaConfig(ns deduplication.Namespace) deduplication.ChromaConfig {
+	return deduplication.ChromaConfig{
+		Host:           getEnvOrDefault("CHROMA_HOST", "localhost"),
+		Port:           getEnvIntOrDefault("CHROMA_PORT", 8000),
+		Tenant:         ns.Tenant,
+		Database:       ns.Database,
+		CollectionName: ns.Collection,
+	}
+}
+
+func getEnvOrDefault(key, defaultVal string) string {
+	if val := os.Getenv(key); val != "" {
+		return val
+	}
+	return defaultVal
+}
+
+func getEnvIntOrDefault(key string, defaultVal int) int {
+	if val := os.Getenv(key); val != "" {
+		if n, err := strconv.Atoi(val); err == nil {
+			return n
+		}
+	}
+	return defaultVal
+}
@@ -3,30 +3,54 @@ package deduplication
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 )
 
+// Collection metadata keys used to pin a collection to a single vector space
+const (
+	metadataEmbeddingModel     = "embedding_model"
+	metadataEmbeddingDimension = "embedding_dimension"
+)
+
+// ErrEmbeddingModelMismatch is returned when the configured embeddings provider
+// does not match the model recorded on an existing collection.
+var ErrEmbeddingModelMismatch = errors.New("embedding model mismatch")
+
+// ErrCollectionNotFound is returned when a collection that must already exist is missing
+var ErrCollectionNotFound = errors.New("collection not found")
+
 // Chroma wraps the Chroma vector database REST API
 type Chroma struct {
-	baseURL        string
-	tenant         string
-	database       string
-	collectionName string
-	collectionID   string
-	httpClient     *http.Client
-	embeddingModel string
-	embedder       EmbeddingsProvider
+	baseURL            string
+	tenant             string
+	database           string
+	collectionName     string
+	collectionID       string
+	collectionMetadata map[string]interface{}
+	httpClient         *http.Client
+	embeddingModel     string
+	embeddingDimension int
+	embedder           EmbeddingsProvider
+	adoptUnlabelled    bool
+	missing            bool // Query-only wrapper over a collection that doesn't exist yet
 }
 
 // ChromaConfig holds configuration for Chroma connection
 type ChromaConfig struct {
 	Host           string
 	Port           int
+	Tenant         string // Default: default_tenant
+	Database       string // Default: default_database
 	CollectionName string
 	EmbeddingModel string
+	// AdoptUnlabelled records the configured model on a collection that has
+	// none. Only set it when the collection is known to have been built with
+	// that model (dedupmigrate adopt).
+	AdoptUnlabelled bool
 }
 
 // Document represents a document to be stored in Chroma
@@ -45,6 +69,13 @@ type QueryResults struct {
 	Embeddings interface{}                `json:"embeddings"`
 }
 
+// collectionInfo is the subset of Chroma's collection model used by the wrapper
+type collectionInfo struct {
+	ID       string                 `json:"id"`
+	Name     string                 `json:"name"`
+	Metadata map[string]interface{} `json:"metadata"`
+}
+
 // GetResults represents the response from a get request
 type GetResults struct {
 	IDs        []string                 `json:"ids"`
@@ -57,56 +88,109 @@ type GetResults struct {
 func NewChroma(config ChromaConfig) (*Chroma, error) {
 	baseURL := fmt.Sprintf("http://%s:%d/api/v2", config.Host, config.Port)
 
-	// Use default embedding model if none specified
-	embeddingModel := getDefaultEmbeddingModel(config.EmbeddingModel)
-
 	wrapper := &Chroma{
-		baseURL:        baseURL,
-		tenant:         "default_tenant",
-		database:       "default_database",
-		collectionName: config.CollectionName,
-		httpClient:     &http.Client{},
-		embeddingModel: embeddingModel,
+		baseURL:         baseURL,
+		tenant:          chromaTenant(config),
+		database:        chromaDatabase(config),
+		collectionName:  config.CollectionName,
+		httpClient:      &http.Client{},
+		embeddingModel:  getDefaultEmbeddingModel(config.EmbeddingModel),
+		adoptUnlabelled: config.AdoptUnlabelled,
 	}
 
 	// Initialize an embeddings provider (required for Chroma v2 REST API when adding/querying)
 	// For read-only operations (get/count), embedder is not required.
-	wrapper.embedder = NewDefaultEmbeddingsProvider(wrapper.embeddingModel)
+	// The provider picks its own default model, so pass the raw configured value through.
+	wrapper.embedder = NewDefaultEmbeddingsProvider(config.EmbeddingModel)
 	if wrapper.embedder != nil {
+		wrapper.embeddingModel = wrapper.embedder.ModelName()
 		log.Printf("Using embeddings provider: %s", wrapper.embedder.ModelName())
 	}
 
 	// Get or create collection
-	collectionID, err := wrapper.getOrCreateCollection(config.CollectionName)
+	info, err := wrapper.getOrCreateCollection(config.CollectionName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get or create collection: %w", err)
 	}
+	wrapper.collectionID = info.ID
+	wrapper.collectionMetadata = info.Metadata
+
+	// Refuse to mix vector spaces in a collection built with a different model
+	if err := wrapper.verifyEmbeddingModel(); err != nil {
+		return nil, err
+	}
 
-	wrapper.collectionID = collectionID
 	return wrapper, nil
 }
 
 // NewChromaReadOnly creates a Chroma wrapper instance without requiring an embeddings provider.
 // Useful for read-only endpoints (e.g., listing or getting documents) where embeddings are not needed.
+// The collection must exist; otherwise ErrCollectionNotFound is returned.
 func NewChromaReadOnly(config ChromaConfig) (*Chroma, error) {
 	baseURL := fmt.Sprintf("http://%s:%d/api/v2", config.Host, config.Port)
 
 	wrapper := &Chroma{
 		baseURL:        baseURL,
-		tenant:         "default_tenant",
-		database:       "default_database",
+		tenant:         chromaTenant(config),
+		database:       chromaDatabase(config),
 		collectionName: config.CollectionName,
 		httpClient:     &http.Client{},
 		embeddingModel: getDefaultEmbeddingModel(config.EmbeddingModel),
 		embedder:       nil, // explicitly nil; not needed for read-only methods
 	}
 
-	// Get or create collection
-	collectionID, err := wrapper.getOrCreateCollection(config.CollectionName)
+	info, err := wrapper.getCollection(config.CollectionName)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get or create collection: %w", err)
+		return nil, err
+	}
+	wrapper.collectionID = info.ID
+	wrapper.collectionMetadata = info.Metadata
+
+	// Report the model the collection was built with rather than the configured default
+	if model, ok := info.Metadata[metadataEmbeddingModel].(string); ok && model != "" {
+		wrapper.embeddingModel = model
 	}
-	wrapper.collectionID = collectionID
+	wrapper.embeddingDimension = metadataInt(info.Metadata[metadataEmbeddingDimension])
+	return wrapper, nil
+}
+
+// NewChromaQueryOnly creates a Chroma wrapper that embeds and queries but never
+// writes: the collection isn't created and its metadata isn't touched. A missing
+// collection holds no documents, so queries against it return no matches.
+func NewChromaQueryOnly(config ChromaConfig) (*Chroma, error) {
+	baseURL := fmt.Sprintf("http://%s:%d/api/v2", config.Host, config.Port)
+
+	wrapper := &Chroma{
+		baseURL:        baseURL,
+		tenant:         chromaTenant(config),
+		database:       chromaDatabase(config),
+		collectionName: config.CollectionName,
+		httpClient:     &http.Client{},
+		embeddingModel: getDefaultEmbeddingModel(config.EmbeddingModel),
+		embedder:       NewDefaultEmbeddingsProvider(config.EmbeddingModel),
+	}
+	if wrapper.embedder != nil {
+		wrapper.embeddingModel = wrapper.embedder.ModelName()
+	}
+
+	info, err := wrapper.getCollection(config.CollectionName)
+	if errors.Is(err, ErrCollectionNotFound) {
+		wrapper.missing = true
+		return wrapper, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	wrapper.collectionID = info.ID
+	wrapper.collectionMetadata = info.Metadata
+
+	if wrapper.embedder != nil {
+		recorded, _ := info.Metadata[metadataEmbeddingModel].(string)
+		if err := wrapper.compareEmbeddingModel(recorded); err != nil {
+			return nil, err
+		}
+	}
+	wrapper.embeddingDimension = metadataInt(info.Metadata[metadataEmbeddingDimension])
 	return wrapper, nil
 }
 
@@ -120,6 +204,151 @@ func (c *Chroma) SetEmbeddingModel(model string) {
 	c.embeddingModel = model
 }
 
+// GetEmbeddingDimension returns the vector dimension recorded on the collection (0 if unknown)
+func (c *Chroma) GetEmbeddingDimension() int {
+	return c.embeddingDimension
+}
+
+// GetCollectionName returns the name of the collection backing this wrapper
+func (c *Chroma) GetCollectionName() string {
+	return c.collectionName
+}
+
+// verifyEmbeddingModel compares the configured embeddings provider with the model
+// recorded on the collection. Collections created before models were recorded are
+// only adopted by the current provider when the config asks for it.
+func (c *Chroma) verifyEmbeddingModel() error {
+	if c.embedder == nil {
+		return nil
+	}
+
+	current := c.embedder.ModelName()
+	recorded, _ := c.collectionMetadata[metadataEmbeddingModel].(string)
+	c.embeddingDimension = metadataInt(c.collectionMetadata[metadataEmbeddingDimension])
+
+	if recorded == "" && c.adoptUnlabelled {
+		log.Printf("Collection %s has no recorded embedding model; recording %s", c.collectionName, current)
+		return c.updateCollectionMetadata(map[string]interface{}{metadataEmbeddingModel: current})
+	}
+	return c.compareEmbeddingModel(recorded)
+}
+
+// compareEmbeddingModel checks the model recorded on the collection against the
+// configured provider's. A collection without a recorded model may hold vectors
+// of any model, so it is refused too.
+func (c *Chroma) compareEmbeddingModel(recorded string) error {
+	current := c.embedder.ModelName()
+	if recorded == "" {
+		return fmt.Errorf("%w: collection %q has no recorded embedding model; if it was built with %q, record it with `dedupmigrate adopt`, otherwise export and re-import it",
+			ErrEmbeddingModelMismatch, c.collectionName, current)
+	}
+	if recorded != current {
+		return fmt.Errorf("%w: collection %q was built with %q but the configured provider uses %q; export and re-import it into a new collection instead",
+			ErrEmbeddingModelMismatch, c.collectionName, recorded, current)
+	}
+	return nil
+}
+
+// recordEmbeddingDimension records the dimension of the vectors being added on a
+// collection that has none yet, then checks them against it. Only the add path
+// writes the dimension, so queries never modify the collection.
+func (c *Chroma) recordEmbeddingDimension(embs [][]float32) error {
+	if len(embs) == 0 || c.embeddingDimension != 0 {
+		return c.checkEmbeddingDimension(embs)
+	}
+
+	dimension := len(embs[0])
+	if err := c.updateCollectionMetadata(map[string]interface{}{metadataEmbeddingDimension: dimension}); err != nil {
+		return fmt.Errorf("failed to record embedding dimension: %w", err)
+	}
+	c.embeddingDimension = dimension
+	return nil
+}
+
+// checkEmbeddingDimension ensures vectors match the dimension recorded on the
+// collection. Nothing is checked until a dimension is recorded.
+func (c *Chroma) checkEmbeddingDimension(embs [][]float32) error {
+	if len(embs) == 0 || c.embeddingDimension == 0 {
+		return nil
+	}
+
+	dimension := len(embs[0])
+	if dimension != c.embeddingDimension {
+		return fmt.Errorf("%w: collection %q stores %d-dimensional vectors but %s produced %d",
+			ErrEmbeddingModelMismatch, c.collectionName, c.embeddingDimension, c.embeddingModel, dimension)
+	}
+	return nil
+}
+
+// updateCollectionMetadata merges the given keys into the collection metadata.
+// Chroma replaces metadata wholesale, so the existing keys are sent along.
+func (c *Chroma) updateCollectionMetadata(updates map[string]interface{}) error {
+	merged := make(map[string]interface{}, len(c.collectionMetadata)+len(updates))
+	for k, v := range c.collectionMetadata {
+		merged[k] = v
+	}
+	for k, v := range updates {
+		merged[k] = v
+	}
+
+	payload := map[string]interface{}{
+		"new_metadata": merged,
+	}
+
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPut, c.collectionURL(), bytes.NewBuffer(jsonData))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to update collection metadata: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to update collection metadata (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	c.collectionMetadata = merged
+	return nil
+}
+
+// metadataInt reads an integer metadata value decoded from JSON
+func metadataInt(value interface{}) int {
+	switch v := value.(type) {
+	case float64:
+		return int(v)
+	case int:
+		return v
+	default:
+		return 0
+	}
+}
+
+// chromaTenant returns the configured tenant or Chroma's default
+func chromaTenant(config ChromaConfig) string {
+	if config.Tenant == "" {
+		return defaultTenant
+	}
+	return config.Tenant
+}
+
+// chromaDatabase returns the configured database or Chroma's default
+func chromaDatabase(config ChromaConfig) string {
+	if config.Database == "" {
+		return defaultDatabase
+	}
+	return config.Database
+}
+
 // getDefaultEmbeddingModel returns a default embedding model if none is specified
 func getDefaultEmbeddingModel(model string) string {
 	if model == "" {
@@ -128,60 +357,133 @@ func getDefaultEmbeddingModel(model string) string {
 	return model
 }
 
-// getOrCreateCollection gets an existing collection or creates a new one
-func (c *Chroma) getOrCreateCollection(name string) (string, error) {
-	// Try to get existing collection
+// getCollection gets an existing collection, returning ErrCollectionNotFound if it doesn't exist
+func (c *Chroma) getCollection(name string) (*collectionInfo, error) {
 	url := fmt.Sprintf("%s/tenants/%s/databases/%s/collections/%s", c.baseURL, c.tenant, c.database, name)
 	resp, err := c.httpClient.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collection: %w", err)
+	}
+	defer resp.Body.Close()
 
-	if err == nil && resp.StatusCode == http.StatusOK {
-		defer resp.Body.Close()
-		var result map[string]interface{}
-		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-			return "", err
-		}
-		log.Printf("Using existing collection: %s", name)
-		return result["id"].(string), nil
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %s", ErrCollectionNotFound, name)
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to get collection (status %d): %s", resp.StatusCode, string(body))
 	}
 
-	// Create new collection with embedding function
+	var result collectionInfo
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	log.Printf("Using existing collection: %s", name)
+	return &result, nil
+}
+
+// getOrCreateCollection gets an existing collection or creates a new one
+func (c *Chroma) getOrCreateCollection(name string) (*collectionInfo, error) {
+	if info, err := c.getCollection(name); err == nil {
+		return info, nil
+	}
+
+	// Namespaces may live in their own tenant/database, which Chroma doesn't create implicitly
+	if err := c.ensureDatabase(); err != nil {
+		return nil, err
+	}
+
+	// Create new collection, recording the embedding model when one is configured
 	log.Printf("Creating new collection: %s", name)
 	createURL := fmt.Sprintf("%s/tenants/%s/databases/%s/collections", c.baseURL, c.tenant, c.database)
+	metadata := map[string]interface{}{
+		"description": "BrainBot article deduplication collection",
+	}
+	if c.embedder != nil {
+		metadata[metadataEmbeddingModel] = c.embedder.ModelName()
+	}
 	payload := map[string]interface{}{
-		"name": name,
-		"metadata": map[string]interface{}{
-			"description": "BrainBot article deduplication collection",
-		},
+		"name":          name,
+		"metadata":      metadata,
 		"get_or_create": true,
 	}
 
 	jsonData, err := json.Marshal(payload)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	resp, err = c.httpClient.Post(createURL, "application/json", bytes.NewBuffer(jsonData))
+	resp, err := c.httpClient.Post(createURL, "application/json", bytes.NewBuffer(jsonData))
 	if err != nil {
-		return "", fmt.Errorf("failed to create collection: %w", err)
+		return nil, fmt.Errorf("failed to create collection: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
 		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("failed to create collection (status %d): %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("failed to create collection (status %d): %s", resp.StatusCode, string(body))
 	}
 
-	var result map[string]interface{}
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
+	var result collectionInfo
 	if err := json.Unmarshal(body, &result); err != nil {
-		return "", fmt.Errorf("failed to parse response: %w, body: %s", err, string(body))
+		return nil, fmt.Errorf("failed to parse response: %w, body: %s", err, string(body))
+	}
+	if result.ID == "" {
+		return nil, fmt.Errorf("create collection response missing id: %s", string(body))
+	}
+
+	return &result, nil
+}
+
+// ensureDatabase creates the wrapper's tenant and database when they don't exist yet
+func (c *Chroma) ensureDatabase() error {
+	tenantURL := fmt.Sprintf("%s/tenants/%s", c.baseURL, c.tenant)
+	if err := c.ensureExists(tenantURL, fmt.Sprintf("%s/tenants", c.baseURL), c.tenant); err != nil {
+		return fmt.Errorf("failed to create Chroma tenant %q: %w", c.tenant, err)
+	}
+	databaseURL := fmt.Sprintf("%s/databases/%s", tenantURL, c.database)
+	if err := c.ensureExists(databaseURL, tenantURL+"/databases", c.database); err != nil {
+		return fmt.Errorf("failed to create Chroma database %q in tenant %q: %w", c.database, c.tenant, err)
+	}
+	return nil
+}
+
+// ensureExists creates a named resource by POSTing to createURL unless a GET of url finds it
+func (c *Chroma) ensureExists(url, createURL, name string) error {
+	resp, err := c.httpClient.Get(url)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	if resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	log.Printf("Creating Chroma resource %s", url)
+	jsonData, err := json.Marshal(map[string]string{"name": name})
+	if err != nil {
+		return err
 	}
+	resp, err = c.httpClient.Post(createURL, "application/json", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
 
-	return result["id"].(string), nil
+	// Conflict means another request created it first
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusConflict {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("status %d: %s", resp.StatusCode, string(body))
+	}
+	return nil
 }
 
 // collectionURL returns the base URL for collection operations
@@ -210,6 +512,9 @@ func (c *Chroma) AddDocument(doc Document) error {
 	if err != nil {
 		return fmt.Errorf("failed to generate embeddings: %w", err)
 	}
+	if err := c.recordEmbeddingDimension(embs); err != nil {
+		return err
+	}
 	payload["embeddings"] = embs
 
 	jsonData, err := json.Marshal(payload)
@@ -262,6 +567,9 @@ func (c *Chroma) AddDocuments(docs []Document) error {
 	if err != nil {
 		return fmt.Errorf("failed to generate embeddings: %w", err)
 	}
+	if err := c.recordEmbeddingDimension(embs); err != nil {
+		return err
+	}
 	payload["embeddings"] = embs
 
 	jsonData, err := json.Marshal(payload)
@@ -286,21 +594,44 @@ func (c *Chroma) AddDocuments(docs []Document) error {
 
 // QuerySimilar searches for similar documents
 func (c *Chroma) QuerySimilar(queryText string, nResults int) (*QueryResults, error) {
-	url := fmt.Sprintf("%s/query", c.collectionURL())
-	payload := map[string]interface{}{
-		"n_results": nResults,
-		// Explicitly request fields commonly needed
-		"include": []string{"metadatas", "documents", "distances", "embeddings", "uris"},
+	embedding, err := c.Embed(queryText)
+	if err != nil {
+		return nil, err
 	}
+	return c.QueryEmbedding(embedding, nResults)
+}
 
+// Embed generates the embedding of a text with the configured provider
+func (c *Chroma) Embed(text string) ([]float32, error) {
 	if c.embedder == nil {
 		return nil, fmt.Errorf("embeddings provider not configured")
 	}
-	embs, err := c.embedder.EmbedTexts([]string{queryText})
+	embs, err := c.embedder.EmbedTexts([]string{text})
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate query embeddings: %w", err)
 	}
-	payload["query_embeddings"] = embs
+	if err := c.checkEmbeddingDimension(embs); err != nil {
+		return nil, err
+	}
+	if len(embs) == 0 {
+		return nil, fmt.Errorf("embeddings provider returned no embedding")
+	}
+	return embs[0], nil
+}
+
+// QueryEmbedding searches for documents similar to an embedding
+func (c *Chroma) QueryEmbedding(embedding []float32, nResults int) (*QueryResults, error) {
+	if c.missing {
+		return &QueryResults{}, nil
+	}
+
+	url := fmt.Sprintf("%s/query", c.collectionURL())
+	payload := map[string]interface{}{
+		"n_results": nResults,
+		// Explicitly request fields commonly needed
+		"include":          []string{"metadatas", "documents", "distances", "embeddings", "uris"},
+		"query_embeddings": [][]float32{embedding},
+	}
 
 	jsonData, err := json.Marshal(payload)
 	if err != nil {
@@ -342,6 +673,9 @@ func (c *Chroma) QuerySimilarWithMetadata(queryText string, nResults int, where
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate query embeddings: %w", err)
 	}
+	if err := c.checkEmbeddingDimension(embs); err != nil {
+		return nil, err
+	}
 	payload["query_embeddings"] = embs
 
 	jsonData, err := json.Marshal(payload)
@@ -472,9 +806,9 @@ func parseChromaError(body []byte) error {
 			return fmt.Errorf("%s: %s", probe.Error, probe.Message)
 		}
 		if probe.Error != "" {
-			return fmt.Errorf(probe.Error)
+			return errors.New(probe.Error)
 		}
-		return fmt.Errorf(probe.Message)
+		return errors.New(probe.Message)
 	}
 	return nil
 }
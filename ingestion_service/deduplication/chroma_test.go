@@ -0,0 +1,180 @@
+package deduplication
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeEmbedder returns a fixed-size zero vector per text
+type fakeEmbedder struct{ dimension int }
+
+func (f fakeEmbedder) EmbedTexts(texts []string) ([][]float32, error) {
+	embs := make([][]float32, len(texts))
+	for i := range embs {
+		embs[i] = make([]float32, f.dimension)
+	}
+	return embs, nil
+}
+
+func (f fakeEmbedder) ModelName() string { return "fake-model" }
+
+// fakeChromaServer serves one existing collection and records metadata updates
+type fakeChromaServer struct {
+	mu       sync.Mutex
+	metadata map[string]interface{}
+	updates  int
+}
+
+func (s *fakeChromaServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/collections/articles"):
+		json.NewEncoder(w).Encode(collectionInfo{ID: "col-1", Name: "articles", Metadata: s.metadata})
+	case r.Method == http.MethodGet:
+		http.NotFound(w, r)
+	case r.Method == http.MethodPut:
+		var body struct {
+			NewMetadata map[string]interface{} `json:"new_metadata"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		s.metadata = body.NewMetadata
+		s.updates++
+	case strings.HasSuffix(r.URL.Path, "/query"):
+		json.NewEncoder(w).Encode(QueryResults{})
+	case strings.HasSuffix(r.URL.Path, "/add"):
+		w.WriteHeader(http.StatusCreated)
+	default:
+		http.Error(w, "unexpected request", http.StatusBadRequest)
+	}
+}
+
+// newTestChroma opens the fake server's collection with a fake embedder
+func newTestChroma(t *testing.T, server *fakeChromaServer, adopt bool) (*Chroma, error) {
+	t.Helper()
+	ts := httptest.NewServer(server)
+	t.Cleanup(ts.Close)
+
+	c := &Chroma{
+		baseURL:         ts.URL + "/api/v2",
+		tenant:          defaultTenant,
+		database:        defaultDatabase,
+		collectionName:  "articles",
+		httpClient:      ts.Client(),
+		embedder:        fakeEmbedder{dimension: 4},
+		embeddingModel:  "fake-model",
+		adoptUnlabelled: adopt,
+	}
+	info, err := c.getOrCreateCollection("articles")
+	if err != nil {
+		t.Fatalf("getOrCreateCollection() = %v", err)
+	}
+	c.collectionID = info.ID
+	c.collectionMetadata = info.Metadata
+	return c, c.verifyEmbeddingModel()
+}
+
+func TestUnlabelledCollectionIsOnlyAdoptedOnRequest(t *testing.T) {
+	server := &fakeChromaServer{metadata: map[string]interface{}{"description": "old"}}
+
+	if _, err := newTestChroma(t, server, false); !errors.Is(err, ErrEmbeddingModelMismatch) {
+		t.Fatalf("opening an unlabelled collection = %v, want ErrEmbeddingModelMismatch", err)
+	}
+	if server.updates != 0 {
+		t.Fatalf("refused collection was written %d times", server.updates)
+	}
+
+	if _, err := newTestChroma(t, server, true); err != nil {
+		t.Fatalf("adopting the collection = %v", err)
+	}
+	if got := server.metadata[metadataEmbeddingModel]; got != "fake-model" {
+		t.Errorf("recorded model = %v, want fake-model", got)
+	}
+	if server.metadata["description"] != "old" {
+		t.Errorf("adopting dropped existing metadata: %v", server.metadata)
+	}
+}
+
+func TestOnlyAddsRecordTheEmbeddingDimension(t *testing.T) {
+	server := &fakeChromaServer{metadata: map[string]interface{}{metadataEmbeddingModel: "fake-model"}}
+	c, err := newTestChroma(t, server, false)
+	if err != nil {
+		t.Fatalf("newTestChroma() = %v", err)
+	}
+
+	if _, err := c.QuerySimilar("story", 5); err != nil {
+		t.Fatalf("QuerySimilar() = %v", err)
+	}
+	if server.updates != 0 {
+		t.Fatalf("query wrote collection metadata %d times", server.updates)
+	}
+
+	if err := c.AddDocument(Document{ID: "a", Content: "story"}); err != nil {
+		t.Fatalf("AddDocument() = %v", err)
+	}
+	if got := metadataInt(server.metadata[metadataEmbeddingDimension]); got != 4 {
+		t.Errorf("recorded dimension = %d, want 4", got)
+	}
+}
+
+func TestReadOnlyChromaDoesNotCreateMissingCollection(t *testing.T) {
+	server := &fakeChromaServer{}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	u, _ := url.Parse(ts.URL)
+	port, _ := strconv.Atoi(u.Port())
+
+	_, err := NewChromaReadOnly(ChromaConfig{Host: u.Hostname(), Port: port, CollectionName: "missing"})
+	if !errors.Is(err, ErrCollectionNotFound) {
+		t.Fatalf("NewChromaReadOnly(missing) = %v, want ErrCollectionNotFound", err)
+	}
+}
+
+func TestCreatingCollectionCreatesTenantAndDatabase(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		created []string
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.Method == http.MethodGet:
+			http.NotFound(w, r)
+		case strings.HasSuffix(r.URL.Path, "/collections"):
+			created = append(created, "collection")
+			json.NewEncoder(w).Encode(collectionInfo{ID: "col-1", Name: "articles"})
+		default:
+			var body struct {
+				Name string `json:"name"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			created = append(created, r.URL.Path+"="+body.Name)
+		}
+	}))
+	defer ts.Close()
+
+	c := &Chroma{
+		baseURL:    ts.URL + "/api/v2",
+		tenant:     "news",
+		database:   "us",
+		httpClient: ts.Client(),
+	}
+	if _, err := c.getOrCreateCollection("articles"); err != nil {
+		t.Fatalf("getOrCreateCollection() = %v", err)
+	}
+
+	want := []string{"/api/v2/tenants=news", "/api/v2/tenants/news/databases=us", "collection"}
+	if strings.Join(created, " ") != strings.Join(want, " ") {
+		t.Errorf("created %v, want %v", created, want)
+	}
+}
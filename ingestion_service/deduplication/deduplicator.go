@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"math"
 	"strings"
 	"time"
 
@@ -20,6 +21,8 @@ const (
 // VectorClient describes the minimal Chroma functionality required by the deduplicator.
 type VectorClient interface {
 	QuerySimilar(queryText string, nResults int) (*QueryResults, error)
+	Embed(text string) ([]float32, error)
+	QueryEmbedding(embedding []float32, nResults int) (*QueryResults, error)
 	AddDocument(doc Document) error
 	GetDocument(id string) (*GetResults, error)
 	UpdateDocument(doc Document) error
@@ -38,6 +41,8 @@ type Deduplicator struct {
 	redis               *redis.Client
 	similarityThreshold float32
 	maxSearchResults    int
+	bloomURLKey         string
+	bloomTitleKey       string
 }
 
 // DeduplicatorConfig holds configuration for the deduplicator
@@ -46,6 +51,7 @@ type DeduplicatorConfig struct {
 	RedisConfig         RedisConfig
 	SimilarityThreshold float32 // Default: 0.95 (95%)
 	MaxSearchResults    int     // Default: 5
+	BloomKeyPrefix      string  // Default: articles:bloom
 }
 
 type RedisConfig struct {
@@ -64,6 +70,23 @@ func NewDeduplicator(config DeduplicatorConfig) (*Deduplicator, error) {
 		return nil, fmt.Errorf("failed to initialize Chroma: %w", err)
 	}
 
+	return newDeduplicator(chroma, cfg), nil
+}
+
+// NewReadOnlyDeduplicator creates a deduplicator for checks only. Chroma is opened
+// query-only, so a missing collection isn't created and no metadata is written.
+func NewReadOnlyDeduplicator(config DeduplicatorConfig) (*Deduplicator, error) {
+	cfg := applyConfigDefaults(config)
+
+	chroma, err := NewChromaQueryOnly(cfg.ChromaConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize Chroma: %w", err)
+	}
+	return newDeduplicator(chroma, cfg), nil
+}
+
+// newDeduplicator connects to Redis and wraps the vector client
+func newDeduplicator(vector VectorClient, cfg DeduplicatorConfig) *Deduplicator {
 	// Initialize Redis connection
 	rdb := redis.NewClient(&redis.Options{
 		Addr:     cfg.RedisConfig.Addr,
@@ -79,11 +102,13 @@ func NewDeduplicator(config DeduplicatorConfig) (*Deduplicator, error) {
 	}
 
 	return &Deduplicator{
-		vector:              chroma,
+		vector:              vector,
 		redis:               rdb,
 		similarityThreshold: cfg.SimilarityThreshold,
 		maxSearchResults:    cfg.MaxSearchResults,
-	}, nil
+		bloomURLKey:         cfg.BloomKeyPrefix + ":url",
+		bloomTitleKey:       cfg.BloomKeyPrefix + ":title",
+	}
 }
 
 // NewDeduplicatorWithClient constructs a deduplicator from a preconfigured vector client.
@@ -98,6 +123,8 @@ func NewDeduplicatorWithClient(client VectorClient, config DeduplicatorConfig) (
 		vector:              client,
 		similarityThreshold: cfg.SimilarityThreshold,
 		maxSearchResults:    cfg.MaxSearchResults,
+		bloomURLKey:         cfg.BloomKeyPrefix + ":url",
+		bloomTitleKey:       cfg.BloomKeyPrefix + ":title",
 	}, nil
 }
 
@@ -108,7 +135,7 @@ func (d *Deduplicator) CheckExactDuplicate(ctx context.Context, article *types.A
 	}
 
 	// Check URL
-	existsURL, err := d.redis.Do(ctx, "BF.EXISTS", "articles:bloom:url", article.URL).Bool()
+	existsURL, err := d.redis.Do(ctx, "BF.EXISTS", d.bloomURLKey, article.URL).Bool()
 	if err != nil {
 		return false, fmt.Errorf("failed to check URL in bloom filter: %w", err)
 	}
@@ -117,7 +144,7 @@ func (d *Deduplicator) CheckExactDuplicate(ctx context.Context, article *types.A
 	}
 
 	// Check Title
-	existsTitle, err := d.redis.Do(ctx, "BF.EXISTS", "articles:bloom:title", article.Title).Bool()
+	existsTitle, err := d.redis.Do(ctx, "BF.EXISTS", d.bloomTitleKey, article.Title).Bool()
 	if err != nil {
 		return false, fmt.Errorf("failed to check Title in bloom filter: %w", err)
 	}
@@ -135,30 +162,91 @@ func (d *Deduplicator) AddExactDuplicate(ctx context.Context, article *types.Art
 	}
 
 	// Add URL
-	_, err := d.redis.Do(ctx, "BF.ADD", "articles:bloom:url", article.URL).Result()
+	_, err := d.redis.Do(ctx, "BF.ADD", d.bloomURLKey, article.URL).Result()
 	if err != nil {
 		return fmt.Errorf("failed to add URL to bloom filter: %w", err)
 	}
 
 	// Add Title
-	_, err = d.redis.Do(ctx, "BF.ADD", "articles:bloom:title", article.Title).Result()
+	_, err = d.redis.Do(ctx, "BF.ADD", d.bloomTitleKey, article.Title).Result()
 	if err != nil {
 		return fmt.Errorf("failed to add Title to bloom filter: %w", err)
 	}
 
 	// Refresh TTL on both Bloom filter keys (24 hours)
 	// This ensures the filters expire 24 hours after the last addition
-	d.redis.Expire(ctx, "articles:bloom:url", TTL)
-	d.redis.Expire(ctx, "articles:bloom:title", TTL)
+	d.redis.Expire(ctx, d.bloomURLKey, TTL)
+	d.redis.Expire(ctx, d.bloomTitleKey, TTL)
 
 	return nil
 }
 
-// CheckForDuplicates checks if the given article is a duplicate of existing articles
+// CheckForDuplicates checks if the given article is a duplicate of existing articles.
+// Stale matches are deleted and a match's last retrieval time is refreshed.
 func (d *Deduplicator) CheckForDuplicates(article *types.Article) (*DeduplicationResult, error) {
+	return d.findDuplicate(article, false)
+}
+
+// CheckArticle reports what ProcessArticle would decide for the article without
+// writing anything: no bloom or vector adds, stale matches are skipped rather than
+// deleted, and retrieval times are left untouched.
+func (d *Deduplicator) CheckArticle(ctx context.Context, article *types.Article) (*DeduplicationResult, error) {
+	return d.NewBatchCheck().CheckArticle(ctx, article)
+}
+
+// BatchCheck checks a batch of articles as ProcessArticle would process them in
+// order, without writing anything. Processing stores each article as it goes, so
+// an article is also a duplicate of an earlier article of the batch with the same
+// URL or title, or of an earlier new one it is similar to.
+type BatchCheck struct {
+	d      *Deduplicator
+	urls   map[string]string // URL -> ID of the earlier article processing would add to the bloom filter
+	titles map[string]string
+	stored []batchArticle // Earlier articles processing would add to the vector database
+}
+
+// batchArticle is an earlier article of a batch with its embedding
+type batchArticle struct {
+	id        string
+	embedding []float32
+}
+
+// NewBatchCheck starts checking a batch of articles
+func (d *Deduplicator) NewBatchCheck() *BatchCheck {
+	return &BatchCheck{
+		d:      d,
+		urls:   make(map[string]string),
+		titles: make(map[string]string),
+	}
+}
+
+// CheckArticle reports what ProcessArticle would decide for the article after
+// the earlier articles of the batch were processed
+func (b *BatchCheck) CheckArticle(ctx context.Context, article *types.Article) (*DeduplicationResult, error) {
+	d := b.d
 	checkTime := time.Now()
 
-	// Extract full text content for embedding
+	isExact, err := d.CheckExactDuplicate(ctx, article)
+	if err != nil {
+		log.Printf("Warning: Redis Bloom check failed: %v", err)
+	}
+	if isExact {
+		return &DeduplicationResult{
+			IsDuplicate:      true,
+			IsExactDuplicate: true,
+			MatchingID:       article.ID,
+			CheckedAt:        checkTime,
+		}, nil
+	}
+	if matchingID, ok := b.exactMatch(article); ok {
+		return &DeduplicationResult{
+			IsDuplicate:      true,
+			IsExactDuplicate: true,
+			MatchingID:       matchingID,
+			CheckedAt:        checkTime,
+		}, nil
+	}
+
 	content := d.extractFullText(article)
 	if content == "" {
 		log.Printf("Warning: No content to check for article %s", article.ID)
@@ -168,8 +256,114 @@ func (d *Deduplicator) CheckForDuplicates(article *types.Article) (*Deduplicatio
 		}, nil
 	}
 
+	embedding, err := d.vector.Embed(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query similar articles: %w", err)
+	}
+	result, err := d.matchEmbedding(article, embedding, true)
+	if err != nil {
+		return nil, err
+	}
+
+	// Processing would add the article to the bloom filter either way
+	b.remember(article)
+	if result.IsDuplicate {
+		return result, nil
+	}
+
+	if match := b.similarMatch(embedding); match != nil {
+		log.Printf("Found in-batch duplicate article: %s matches %s with %.2f%% similarity",
+			article.ID, match.MatchingID, match.SimilarityScore*100)
+		match.CheckedAt = checkTime
+		return match, nil
+	}
+	b.stored = append(b.stored, batchArticle{id: article.ID, embedding: embedding})
+	return result, nil
+}
+
+// exactMatch returns the earlier article of the batch with the same URL or title
+func (b *BatchCheck) exactMatch(article *types.Article) (string, bool) {
+	if id, ok := b.urls[article.URL]; ok {
+		return id, true
+	}
+	id, ok := b.titles[article.Title]
+	return id, ok
+}
+
+// remember records an article processing would add to the bloom filter
+func (b *BatchCheck) remember(article *types.Article) {
+	if _, ok := b.urls[article.URL]; !ok {
+		b.urls[article.URL] = article.ID
+	}
+	if _, ok := b.titles[article.Title]; !ok {
+		b.titles[article.Title] = article.ID
+	}
+}
+
+// similarMatch returns the most similar earlier new article of the batch above
+// the similarity threshold, or nil
+func (b *BatchCheck) similarMatch(embedding []float32) *DeduplicationResult {
+	var best *DeduplicationResult
+	for _, stored := range b.stored {
+		similarity := cosineSimilarity(embedding, stored.embedding)
+		if similarity < b.d.similarityThreshold {
+			continue
+		}
+		if best == nil || similarity > best.SimilarityScore {
+			best = &DeduplicationResult{
+				IsDuplicate:     true,
+				MatchingID:      stored.id,
+				SimilarityScore: similarity,
+			}
+		}
+	}
+	return best
+}
+
+// cosineSimilarity returns the cosine similarity of two vectors (0 if either is empty)
+func cosineSimilarity(a, b []float32) float32 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return float32(dot / (math.Sqrt(normA) * math.Sqrt(normB)))
+}
+
+// findDuplicate queries the vector database for the closest match above the
+// similarity threshold. In readOnly mode the database is never modified.
+func (d *Deduplicator) findDuplicate(article *types.Article, readOnly bool) (*DeduplicationResult, error) {
+	// Extract full text content for embedding
+	content := d.extractFullText(article)
+	if content == "" {
+		log.Printf("Warning: No content to check for article %s", article.ID)
+		return &DeduplicationResult{
+			IsDuplicate: false,
+			CheckedAt:   time.Now(),
+		}, nil
+	}
+
+	embedding, err := d.vector.Embed(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query similar articles: %w", err)
+	}
+	return d.matchEmbedding(article, embedding, readOnly)
+}
+
+// matchEmbedding finds the closest stored match of an article's embedding above
+// the similarity threshold. In readOnly mode the database is never modified.
+func (d *Deduplicator) matchEmbedding(article *types.Article, embedding []float32, readOnly bool) (*DeduplicationResult, error) {
+	checkTime := time.Now()
+
 	// Search for similar articles
-	results, err := d.vector.QuerySimilar(content, d.maxSearchResults)
+	results, err := d.vector.QueryEmbedding(embedding, d.maxSearchResults)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query similar articles: %w", err)
 	}
@@ -199,11 +393,16 @@ func (d *Deduplicator) CheckForDuplicates(article *types.Article) (*Deduplicatio
 			lastUpdate, err := resolveLastUpdateTimestamp(metadata)
 			if err != nil {
 				log.Printf("Warning: skipping candidate %s due to metadata issue: %v", matchingID, err)
-				d.deleteDocumentWithLog(matchingID, "invalid or missing TTL metadata")
+				if !readOnly {
+					d.deleteDocumentWithLog(matchingID, "invalid or missing TTL metadata")
+				}
 				continue
 			}
 
 			if lastUpdate.Before(cutoffTime) {
+				if readOnly {
+					continue
+				}
 				log.Printf("Removing stale article %s last updated at %s (cutoff %s)",
 					matchingID, lastUpdate.Format(time.RFC3339), cutoffTime.Format(time.RFC3339))
 				d.deleteDocumentWithLog(matchingID, "exceeded TTL")
@@ -226,9 +425,11 @@ func (d *Deduplicator) CheckForDuplicates(article *types.Article) (*Deduplicatio
 
 	// If we found a match, update last retrieval time and return it
 	if bestMatch != nil {
-		err := d.updateLastRetrievalTime(bestMatch.MatchingID)
-		if err != nil {
-			log.Printf("Warning: failed to update last retrieval time for %s: %v", bestMatch.MatchingID, err)
+		if !readOnly {
+			err := d.updateLastRetrievalTime(bestMatch.MatchingID)
+			if err != nil {
+				log.Printf("Warning: failed to update last retrieval time for %s: %v", bestMatch.MatchingID, err)
+			}
 		}
 
 		log.Printf("Found duplicate article: %s matches %s with %.2f%% similarity",
@@ -461,7 +662,7 @@ func (d *Deduplicator) ClearBloomFilter(ctx context.Context) error {
 	}
 
 	// Delete the keys
-	keys := []string{"articles:bloom:url", "articles:bloom:title"}
+	keys := []string{d.bloomURLKey, d.bloomTitleKey}
 	if err := d.redis.Del(ctx, keys...).Err(); err != nil {
 		return fmt.Errorf("failed to delete bloom filter keys: %w", err)
 	}
@@ -485,5 +686,8 @@ func applyConfigDefaults(config DeduplicatorConfig) DeduplicatorConfig {
 	if config.MaxSearchResults == 0 {
 		config.MaxSearchResults = MaxSearchResults
 	}
+	if config.BloomKeyPrefix == "" {
+		config.BloomKeyPrefix = defaultBloomKeyPrefix
+	}
 	return config
 }
@@ -0,0 +1,86 @@
+package deduplication
+
+import (
+	"brainbot/ingestion_service/types"
+	"context"
+	"testing"
+)
+
+// fakeVector is an empty, read-only vector store whose embeddings are looked up by text
+type fakeVector struct {
+	embeddings map[string][]float32
+	writes     int
+}
+
+func (f *fakeVector) QuerySimilar(queryText string, nResults int) (*QueryResults, error) {
+	return &QueryResults{}, nil
+}
+
+func (f *fakeVector) Embed(text string) ([]float32, error) {
+	return f.embeddings[text], nil
+}
+
+func (f *fakeVector) QueryEmbedding(embedding []float32, nResults int) (*QueryResults, error) {
+	return &QueryResults{}, nil
+}
+
+func (f *fakeVector) AddDocument(doc Document) error    { f.writes++; return nil }
+func (f *fakeVector) UpdateDocument(doc Document) error { f.writes++; return nil }
+func (f *fakeVector) DeleteDocument(id string) error    { f.writes++; return nil }
+func (f *fakeVector) GetDocument(id string) (*GetResults, error) {
+	return &GetResults{}, nil
+}
+func (f *fakeVector) Count() (int, error)       { return 0, nil }
+func (f *fakeVector) GetEmbeddingModel() string { return "fake" }
+func (f *fakeVector) Close() error              { return nil }
+
+func TestBatchCheckFindsDuplicatesWithinTheBatch(t *testing.T) {
+	vector := &fakeVector{embeddings: map[string][]float32{
+		"original story":  {1, 0, 0},
+		"reworded story":  {0.99, 0.05, 0},
+		"unrelated story": {0, 1, 0},
+		"copied story":    {0, 0, 1},
+	}}
+	d, err := NewDeduplicatorWithClient(vector, DeduplicatorConfig{})
+	if err != nil {
+		t.Fatalf("NewDeduplicatorWithClient() = %v", err)
+	}
+
+	articles := []*types.Article{
+		{ID: "a", Title: "A", URL: "https://example.com/a", FullContentText: "original story"},
+		{ID: "b", Title: "B", URL: "https://example.com/b", FullContentText: "reworded story"},
+		{ID: "c", Title: "C", URL: "https://example.com/c", FullContentText: "unrelated story"},
+		{ID: "d", Title: "A", URL: "https://example.com/d", FullContentText: "copied story"},
+	}
+	want := []struct {
+		duplicate bool
+		exact     bool
+		matching  string
+	}{
+		{false, false, ""},
+		{true, false, "a"},
+		{false, false, ""},
+		{true, true, "a"},
+	}
+
+	batch := d.NewBatchCheck()
+	for i, article := range articles {
+		result, err := batch.CheckArticle(context.Background(), article)
+		if err != nil {
+			t.Fatalf("CheckArticle(%s) = %v", article.ID, err)
+		}
+		if result.IsDuplicate != want[i].duplicate || result.IsExactDuplicate != want[i].exact || result.MatchingID != want[i].matching {
+			t.Errorf("article %s: duplicate=%v exact=%v matching=%q, want %+v",
+				article.ID, result.IsDuplicate, result.IsExactDuplicate, result.MatchingID, want[i])
+		}
+	}
+
+	// A fresh check has no batch to compare with
+	result, err := d.CheckArticle(context.Background(), articles[1])
+	if err != nil || result.IsDuplicate {
+		t.Errorf("CheckArticle(b) alone = %+v, %v; want new", result, err)
+	}
+	if vector.writes != 0 {
+		t.Errorf("checks wrote to the vector store %d times", vector.writes)
+	}
+}
@@ -0,0 +1,141 @@
+package deduplication
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"regexp"
+	"strings"
+)
+
+// DefaultMigrationBatchSize is the number of documents read or re-embedded per request
+const DefaultMigrationBatchSize = 100
+
+// ExportedDocument is a single JSONL line produced by ExportCollection.
+// Embeddings are deliberately omitted: imports always re-embed the document text.
+type ExportedDocument struct {
+	ID       string                 `json:"id"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+	Document string                 `json:"document"`
+}
+
+var collectionNameInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// ModelCollectionName derives a model-specific collection name, e.g.
+// "brainbot_articles" + "embed-english-v3.0" -> "brainbot_articles_embed-english-v3.0".
+// Characters Chroma rejects in collection names are replaced with underscores.
+func ModelCollectionName(base, model string) string {
+	suffix := collectionNameInvalidChars.ReplaceAllString(model, "_")
+	suffix = strings.Trim(suffix, "._-")
+	if suffix == "" {
+		return base
+	}
+	return base + "_" + suffix
+}
+
+// ExportCollection writes every document in the collection to w as JSONL.
+// It returns the number of documents written.
+func ExportCollection(c *Chroma, w io.Writer, batchSize int) (int, error) {
+	if batchSize <= 0 {
+		batchSize = DefaultMigrationBatchSize
+	}
+
+	encoder := json.NewEncoder(w)
+	exported := 0
+
+	for offset := 0; ; offset += batchSize {
+		results, err := c.ListDocuments(batchSize, offset)
+		if err != nil {
+			return exported, fmt.Errorf("failed to list documents at offset %d: %w", offset, err)
+		}
+
+		for i, id := range results.IDs {
+			doc := ExportedDocument{ID: id}
+			if i < len(results.Metadatas) {
+				doc.Metadata = results.Metadatas[i]
+			}
+			if i < len(results.Documents) {
+				doc.Document = results.Documents[i]
+			}
+			if err := encoder.Encode(doc); err != nil {
+				return exported, fmt.Errorf("failed to write document %s: %w", id, err)
+			}
+			exported++
+		}
+
+		if len(results.IDs) < batchSize {
+			break
+		}
+	}
+
+	log.Printf("Exported %d documents from collection %s", exported, c.GetCollectionName())
+	return exported, nil
+}
+
+// ImportCollection reads JSONL produced by ExportCollection and adds each document to
+// the collection, generating fresh embeddings with the collection's provider.
+// It returns the number of documents imported.
+func ImportCollection(c *Chroma, r io.Reader, batchSize int) (int, error) {
+	if batchSize <= 0 {
+		batchSize = DefaultMigrationBatchSize
+	}
+
+	scanner := bufio.NewScanner(r)
+	// Full article text can easily exceed bufio's default 64KB line limit
+	scanner.Buffer(make([]byte, 0, 1024*1024), 16*1024*1024)
+
+	imported := 0
+	batch := make([]Document, 0, batchSize)
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := c.AddDocuments(batch); err != nil {
+			return err
+		}
+		imported += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		raw := strings.TrimSpace(scanner.Text())
+		if raw == "" {
+			continue
+		}
+
+		var doc ExportedDocument
+		if err := json.Unmarshal([]byte(raw), &doc); err != nil {
+			return imported, fmt.Errorf("line %d: invalid document: %w", line, err)
+		}
+		if doc.ID == "" || doc.Document == "" {
+			log.Printf("Warning: skipping line %d: missing id or document text", line)
+			continue
+		}
+
+		batch = append(batch, Document{
+			ID:       doc.ID,
+			Content:  doc.Document,
+			Metadata: doc.Metadata,
+		})
+		if len(batch) >= batchSize {
+			if err := flush(); err != nil {
+				return imported, fmt.Errorf("line %d: %w", line, err)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return imported, fmt.Errorf("failed to read input: %w", err)
+	}
+	if err := flush(); err != nil {
+		return imported, err
+	}
+
+	log.Printf("Imported %d documents into collection %s", imported, c.GetCollectionName())
+	return imported, nil
+}
@@ -0,0 +1,88 @@
+package deduplication
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+const (
+	// DefaultNamespace keeps the original collection and bloom keys for callers that don't pick one
+	DefaultNamespace = "default"
+
+	defaultTenant         = "default_tenant"
+	defaultDatabase       = "default_database"
+	defaultBloomKeyPrefix = "articles:bloom"
+)
+
+// Names are restricted to [a-z0-9-] so each maps onto distinct CHROMA_*_<NAME>
+// variables; with '_' allowed, news-us and news_us would share them.
+var namespacePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+
+// Namespace isolates deduplication state (Chroma location and bloom filter keys)
+// so the same story can be new for one channel and a duplicate for another.
+type Namespace struct {
+	Name           string
+	Tenant         string
+	Database       string
+	Collection     string
+	BloomKeyPrefix string
+}
+
+// ResolveNamespace maps a namespace name onto its Chroma tenant/database/collection
+// and bloom key prefix. An empty name resolves to DefaultNamespace, which uses
+// baseCollection and the legacy "articles:bloom" keys unchanged.
+//
+// Other namespaces default to "<baseCollection>_<name>" and "articles:<name>:bloom".
+// Locations can be overridden per namespace via CHROMA_TENANT_<NAME>,
+// CHROMA_DATABASE_<NAME> and CHROMA_COLLECTION_<NAME>, falling back to
+// CHROMA_TENANT / CHROMA_DATABASE for every namespace.
+func ResolveNamespace(name, baseCollection string) (Namespace, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		name = DefaultNamespace
+	}
+	if !namespacePattern.MatchString(name) {
+		return Namespace{}, fmt.Errorf("invalid namespace %q: use lowercase letters, digits or '-'", name)
+	}
+
+	ns := Namespace{
+		Name:           name,
+		Tenant:         envOrDefault("CHROMA_TENANT", defaultTenant),
+		Database:       envOrDefault("CHROMA_DATABASE", defaultDatabase),
+		Collection:     baseCollection,
+		BloomKeyPrefix: defaultBloomKeyPrefix,
+	}
+	if name == DefaultNamespace {
+		return ns, nil
+	}
+
+	suffix := EnvSuffix(name)
+	ns.Tenant = envOrDefault("CHROMA_TENANT"+suffix, ns.Tenant)
+	ns.Database = envOrDefault("CHROMA_DATABASE"+suffix, ns.Database)
+	ns.Collection = envOrDefault("CHROMA_COLLECTION"+suffix, baseCollection+"_"+name)
+	ns.BloomKeyPrefix = fmt.Sprintf("articles:%s:bloom", name)
+	return ns, nil
+}
+
+// EnvSuffix returns the suffix of a namespace's CHROMA_*_<NAME> override variables
+func EnvSuffix(name string) string {
+	return "_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// ObjectID returns the storage key for an article within the namespace so that
+// similar-article appends in one namespace don't leak into another.
+func (ns Namespace) ObjectID(articleID string) string {
+	if ns.Name == DefaultNamespace {
+		return articleID
+	}
+	return ns.Name + "/" + articleID
+}
+
+func envOrDefault(key, defaultVal string) string {
+	if val := os.Getenv(key); val != "" {
+		return val
+	}
+	return defaultVal
+}
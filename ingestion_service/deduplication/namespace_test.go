@@ -0,0 +1,38 @@
+package deduplication
+
+import "testing"
+
+func TestResolveNamespace(t *testing.T) {
+	t.Setenv("CHROMA_COLLECTION_NEWS_US", "us_articles")
+
+	tests := []struct {
+		name       string
+		want       string
+		collection string
+		wantErr    bool
+	}{
+		{name: "", want: DefaultNamespace, collection: "articles"},
+		{name: "tech", want: "tech", collection: "articles_tech"},
+		{name: " News-US ", want: "news-us", collection: "us_articles"},
+		// '_' would share news-us's CHROMA_*_NEWS_US variables
+		{name: "news_us", wantErr: true},
+		{name: "NEWS_US", wantErr: true},
+		{name: "-news", wantErr: true},
+	}
+	for _, tt := range tests {
+		ns, err := ResolveNamespace(tt.name, "articles")
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ResolveNamespace(%q) = %+v, want an error", tt.name, ns)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ResolveNamespace(%q) = %v", tt.name, err)
+			continue
+		}
+		if ns.Name != tt.want || ns.Collection != tt.collection {
+			t.Errorf("ResolveNamespace(%q) = %s/%s, want %s/%s", tt.name, ns.Name, ns.Collection, tt.want, tt.collection)
+		}
+	}
+}
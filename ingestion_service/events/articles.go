@@ -0,0 +1,89 @@
+package events
+
+import (
+	"brainbot/ingestion_service/types"
+	sharedKafka "brainbot/shared/kafka"
+	sharedTypes "brainbot/shared/types"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Origin describes what caused an article event
+type Origin struct {
+	RequestID  string
+	JobID      string
+	FeedPreset string
+	Namespace  string
+}
+
+// PublishFetched announces articles returned by a feed fetch
+func (p *Publisher) PublishFetched(origin Origin, articles []*types.Article) error {
+	for _, article := range articles {
+		if err := p.publishArticle(sharedTypes.ArticleFetched, origin, article, nil, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PublishDeduplicated announces an article's deduplication outcome on the new or duplicate topic
+func (p *Publisher) PublishDeduplicated(origin Origin, article *types.Article, result *types.DeduplicationResult, presignedURL string) error {
+	eventType := sharedTypes.ArticleNew
+	if result.IsDuplicate {
+		eventType = sharedTypes.ArticleDuplicate
+	}
+	return p.publishArticle(eventType, origin, article, result, presignedURL)
+}
+
+// publishArticle builds and sends one article event
+func (p *Publisher) publishArticle(eventType sharedTypes.ArticleEventType, origin Origin, article *types.Article, result *types.DeduplicationResult, presignedURL string) error {
+	if p == nil || article == nil {
+		return nil
+	}
+
+	event := sharedTypes.ArticleEvent{
+		EventID:    uuid.NewString(),
+		Type:       eventType,
+		Version:    sharedTypes.ArticleEventVersion,
+		ProducedAt: time.Now(),
+		RequestID:  origin.RequestID,
+		JobID:      origin.JobID,
+		FeedPreset: origin.FeedPreset,
+		Namespace:  origin.Namespace,
+		Article: sharedTypes.ArticlePayload{
+			ID:              article.ID,
+			Title:           article.Title,
+			URL:             article.URL,
+			PublishedAt:     article.PublishedAt,
+			FetchedAt:       article.FetchedAt,
+			Summary:         article.Summary,
+			Author:          article.Author,
+			Categories:      article.Categories,
+			Excerpt:         article.Excerpt,
+			ImageURL:        article.ImageURL,
+			ContentText:     article.FullContentText,
+			ExtractionError: article.ExtractionError,
+		},
+		PresignedURL: presignedURL,
+	}
+	if result != nil {
+		event.IsExactDuplicate = result.IsExactDuplicate
+		event.MatchingID = result.MatchingID
+		event.SimilarityScore = result.SimilarityScore
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return ErrClosed
+	}
+	_, err := p.articles.Send(context.Background(), sharedKafka.Message[sharedTypes.ArticleEvent]{
+		Topic:   string(eventType),
+		Key:     article.ID,
+		Value:   event,
+		Headers: requestHeaders(origin.RequestID),
+	})
+	return err
+}
@@ -0,0 +1,106 @@
+package events
+
+import (
+	"brainbot/ingestion_service/types"
+	sharedKafka "brainbot/shared/kafka"
+	sharedTypes "brainbot/shared/types"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+)
+
+// ErrClosed is returned for events published after Close
+var ErrClosed = errors.New("event publisher closed")
+
+// Publisher sends ingestion events to Kafka as JSON. Article events are sent
+// asynchronously, with failed deliveries logged; job events wait for the
+// brokers' acknowledgement.
+type Publisher struct {
+	articles *sharedKafka.Producer[sharedTypes.ArticleEvent]
+	jobs     *sharedKafka.Producer[types.FetchJob]
+
+	// Background fetch jobs can outlive the server; mu keeps their sends
+	// from racing with Close
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewPublisherFromEnv connects to the brokers in KAFKA_BOOTSTRAP_SERVERS
+// (comma-separated). It returns nil without error when Kafka isn't configured,
+// in which case events are not published.
+func NewPublisherFromEnv() (*Publisher, error) {
+	var brokers []string
+	for _, broker := range strings.Split(os.Getenv("KAFKA_BOOTSTRAP_SERVERS"), ",") {
+		if broker = strings.TrimSpace(broker); broker != "" {
+			brokers = append(brokers, broker)
+		}
+	}
+	if len(brokers) == 0 {
+		return nil, nil
+	}
+
+	articles, err := sharedKafka.NewProducer[sharedTypes.ArticleEvent](sharedKafka.ProducerConfig{
+		Brokers:  brokers,
+		ClientID: "ingestion-service",
+		Async:    true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kafka producer: %w", err)
+	}
+	jobs, err := sharedKafka.NewProducer[types.FetchJob](sharedKafka.ProducerConfig{
+		Brokers:  brokers,
+		ClientID: "ingestion-service",
+	})
+	if err != nil {
+		articles.Close()
+		return nil, fmt.Errorf("failed to create Kafka producer: %w", err)
+	}
+
+	log.Printf("Publishing ingestion events to Kafka (%s)", strings.Join(brokers, ","))
+	return &Publisher{articles: articles, jobs: jobs}, nil
+}
+
+// PublishJob sends a job event to topic, keyed by job ID. A nil publisher discards events.
+func (p *Publisher) PublishJob(topic string, job types.FetchJob) error {
+	if p == nil {
+		return nil
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return ErrClosed
+	}
+	_, err := p.jobs.Send(context.Background(), sharedKafka.Message[types.FetchJob]{
+		Topic:   topic,
+		Key:     job.ID,
+		Value:   job,
+		Headers: requestHeaders(job.RequestID),
+	})
+	return err
+}
+
+// Close flushes queued events and closes the producers. Later events fail with ErrClosed.
+func (p *Publisher) Close() error {
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return nil
+	}
+	p.closed = true
+	return errors.Join(p.articles.Close(), p.jobs.Close())
+}
+
+// requestHeaders carries the request ID that caused an event, if any
+func requestHeaders(requestID string) map[string]string {
+	if requestID == "" {
+		return nil
+	}
+	return map[string]string{sharedTypes.RequestIDHeader: requestID}
+}
@@ -0,0 +1,237 @@
+package jobs
+
+import (
+	"brainbot/ingestion_service/events"
+	"brainbot/ingestion_service/rssfeeds"
+	"brainbot/ingestion_service/types"
+	sharedTypes "brainbot/shared/types"
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultWorkers is how many fetch jobs run at once unless FETCH_JOB_WORKERS is set
+	DefaultWorkers = 2
+	// DefaultTopic receives job completion events unless KAFKA_TOPIC_INGESTION_JOBS is set
+	DefaultTopic = sharedTypes.TopicIngestionJobs
+	// retention is how long finished jobs stay queryable
+	retention = time.Hour
+)
+
+// ProcessFunc deduplicates one article in a namespace
+type ProcessFunc func(ctx context.Context, namespace string, article *types.Article, origin events.Origin) types.ArticleResult
+
+// Config controls job execution
+type Config struct {
+	Workers   int               // Fetch jobs running at once
+	Publisher *events.Publisher // Announces fetched articles and finished jobs (nil disables)
+	Topic     string            // Topic for job completion events
+	Process   ProcessFunc       // Deduplicates articles for process jobs
+}
+
+// ConfigFromEnv reads FETCH_JOB_WORKERS and KAFKA_TOPIC_INGESTION_JOBS
+func ConfigFromEnv(publisher *events.Publisher, process ProcessFunc) Config {
+	config := Config{
+		Workers:   DefaultWorkers,
+		Publisher: publisher,
+		Topic:     DefaultTopic,
+		Process:   process,
+	}
+	if n, err := strconv.Atoi(os.Getenv("FETCH_JOB_WORKERS")); err == nil && n > 0 {
+		config.Workers = n
+	}
+	if topic := os.Getenv("KAFKA_TOPIC_INGESTION_JOBS"); topic != "" {
+		config.Topic = topic
+	}
+	return config
+}
+
+// Manager runs fetch jobs in the background and keeps their results in memory
+type Manager struct {
+	config Config
+	slots  chan struct{}
+
+	mu   sync.Mutex
+	jobs map[string]*types.FetchJob
+}
+
+// NewManager creates a job manager
+func NewManager(config Config) *Manager {
+	if config.Workers <= 0 {
+		config.Workers = DefaultWorkers
+	}
+	if config.Topic == "" {
+		config.Topic = DefaultTopic
+	}
+	return &Manager{
+		config: config,
+		slots:  make(chan struct{}, config.Workers),
+		jobs:   make(map[string]*types.FetchJob),
+	}
+}
+
+// SubmitFetch queues a feed fetch and returns the queued job (thread-safe)
+func (m *Manager) SubmitFetch(req types.FetchJobRequest, requestID string) types.FetchJob {
+	job := &types.FetchJob{
+		ID:         uuid.NewString(),
+		Status:     types.JobQueued,
+		FeedPreset: req.FeedPreset,
+		Count:      req.Count,
+		Process:    req.Process && m.config.Process != nil,
+		Namespace:  req.Namespace,
+		DryRun:     req.DryRun,
+		RequestID:  requestID,
+		CreatedAt:  time.Now(),
+	}
+
+	m.mu.Lock()
+	m.prune()
+	m.jobs[job.ID] = job
+	snapshot := *job
+	m.mu.Unlock()
+
+	go m.runFetch(job)
+	return snapshot
+}
+
+// Get returns a copy of a job (thread-safe)
+func (m *Manager) Get(id string) (types.FetchJob, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, ok := m.jobs[id]
+	if !ok {
+		return types.FetchJob{}, false
+	}
+	return *job, true
+}
+
+// runFetch waits for a worker slot, then fetches the feed, extracts every
+// article and, for process jobs, deduplicates them
+func (m *Manager) runFetch(job *types.FetchJob) {
+	m.slots <- struct{}{}
+	defer func() { <-m.slots }()
+
+	m.update(job, func(j *types.FetchJob) {
+		now := time.Now()
+		j.Status = types.JobRunning
+		j.StartedAt = &now
+	})
+	log.Printf("Fetch job %s started: feed %s (request %s)", job.ID, job.FeedPreset, job.RequestID)
+
+	feedURL := rssfeeds.ResolveFeedURL(job.FeedPreset)
+	articles, err := rssfeeds.FetchFeed(feedURL, job.Count)
+	if err != nil {
+		m.finish(job, nil, nil, "Failed to fetch feed: "+err.Error())
+		return
+	}
+
+	m.update(job, func(j *types.FetchJob) { j.Progress.Total = len(articles) })
+	rssfeeds.ExtractAllContentWithProgress(articles, func(article *types.Article) {
+		m.update(job, func(j *types.FetchJob) {
+			if article.ExtractionError != "" {
+				j.Progress.Failed++
+			} else {
+				j.Progress.Extracted++
+			}
+		})
+	})
+
+	origin := events.Origin{RequestID: job.RequestID, JobID: job.ID, FeedPreset: job.FeedPreset, Namespace: job.Namespace}
+	if !job.DryRun {
+		if err := m.config.Publisher.PublishFetched(origin, articles); err != nil {
+			log.Printf("Failed to announce articles of fetch job %s: %v", job.ID, err)
+		}
+	}
+
+	var results []types.ArticleResult
+	if job.Process {
+		results = m.processArticles(job, articles, origin)
+	}
+
+	m.finish(job, articles, results, "")
+}
+
+// processArticles deduplicates a job's extracted articles one at a time
+func (m *Manager) processArticles(job *types.FetchJob, articles []*types.Article, origin events.Origin) []types.ArticleResult {
+	results := make([]types.ArticleResult, 0, len(articles))
+	for _, article := range articles {
+		// Skip articles that failed extraction
+		if article.ExtractionError != "" {
+			results = append(results, types.ArticleResult{
+				Article: article,
+				Status:  "failed",
+				Error:   article.ExtractionError,
+			})
+			continue
+		}
+
+		result := m.config.Process(context.Background(), job.Namespace, article, origin)
+		results = append(results, result)
+		m.update(job, func(j *types.FetchJob) {
+			switch result.Status {
+			case "new":
+				j.Progress.New++
+			case "duplicate":
+				j.Progress.Duplicates++
+			default:
+				j.Progress.Errors++
+			}
+		})
+	}
+	return results
+}
+
+// finish records a job's outcome and announces it, unless it is a dry run
+func (m *Manager) finish(job *types.FetchJob, articles []*types.Article, results []types.ArticleResult, errMsg string) {
+	var snapshot types.FetchJob
+	m.update(job, func(j *types.FetchJob) {
+		now := time.Now()
+		j.FinishedAt = &now
+		j.Articles = articles
+		j.Results = results
+		j.Error = errMsg
+		j.Status = types.JobSucceeded
+		if errMsg != "" {
+			j.Status = types.JobFailed
+		}
+		snapshot = *j
+	})
+
+	if errMsg != "" {
+		log.Printf("Fetch job %s failed: %s", job.ID, errMsg)
+	} else {
+		log.Printf("Fetch job %s finished: %d extracted, %d failed", job.ID, snapshot.Progress.Extracted, snapshot.Progress.Failed)
+	}
+
+	if job.DryRun {
+		return
+	}
+
+	// Consumers fetch the articles and results with GET /api/jobs/:id
+	if err := m.config.Publisher.PublishJob(m.config.Topic, snapshot.Summary()); err != nil {
+		log.Printf("Failed to announce fetch job %s: %v", job.ID, err)
+	}
+}
+
+// update applies fn to a job under the lock
+func (m *Manager) update(job *types.FetchJob, fn func(*types.FetchJob)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	fn(job)
+}
+
+// prune drops finished jobs past their retention (must hold lock)
+func (m *Manager) prune() {
+	for id, job := range m.jobs {
+		if job.FinishedAt != nil && time.Since(*job.FinishedAt) > retention {
+			delete(m.jobs, id)
+		}
+	}
+}
@@ -1,9 +1,14 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"brainbot/ingestion_service/api"
 
@@ -19,10 +24,30 @@ func main() {
 		addr = ":" + v
 	}
 
-	r := api.NewRouter()
+	r, closeEvents := api.NewRouter()
+	server := &http.Server{Addr: addr, Handler: r}
 
-	if err := http.ListenAndServe(addr, r); err != nil {
-		log.Fatalf("server error: %v", err)
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("server error: %v", err)
+		}
+	}()
+
+	// Handle graceful shutdown
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	<-sigChan
+
+	log.Println("Shutting down...")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("Shutdown error: %v", err)
+	}
+	// Flush events queued by the requests that just drained
+	if err := closeEvents(); err != nil {
+		log.Printf("Event publisher close error: %v", err)
 	}
 }
 
@@ -17,6 +17,13 @@ const (
 
 // ExtractAllContent fetches and extracts full content for all articles using a worker pool
 func ExtractAllContent(articles []*types.Article) {
+	ExtractAllContentWithProgress(articles, nil)
+}
+
+// ExtractAllContentWithProgress is ExtractAllContent calling onDone after each
+// article's extraction finishes (successfully or not). onDone is called from
+// the worker goroutines.
+func ExtractAllContentWithProgress(articles []*types.Article, onDone func(article *types.Article)) {
 	var wg sync.WaitGroup
 	articleChan := make(chan *types.Article, len(articles))
 
@@ -28,6 +35,9 @@ func ExtractAllContent(articles []*types.Article) {
 					article.ExtractionError = err.Error()
 					log.Printf("[Worker %d] Failed to extract %s: %v", workerID, article.URL, err)
 				}
+				if onDone != nil {
+					onDone(article)
+				}
 				wg.Done()
 			}
 		}(i)
@@ -2,6 +2,9 @@ package types
 
 import "time"
 
+// NamespaceHeader selects the deduplication namespace for a request when the body doesn't.
+const NamespaceHeader = "X-Dedup-Namespace"
+
 // ArticleResult represents the processing result for a single article
 type ArticleResult struct {
 	Article             *Article             `json:"article"`
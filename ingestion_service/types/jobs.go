@@ -0,0 +1,68 @@
+package types
+
+import "time"
+
+// JobStatus is the lifecycle state of an asynchronous ingestion job
+type JobStatus string
+
+const (
+	JobQueued    JobStatus = "queued"
+	JobRunning   JobStatus = "running"
+	JobSucceeded JobStatus = "succeeded"
+	JobFailed    JobStatus = "failed"
+)
+
+// Done reports whether the job has finished
+func (s JobStatus) Done() bool {
+	return s == JobSucceeded || s == JobFailed
+}
+
+// JobProgress counts a fetch job's articles as extraction proceeds
+type JobProgress struct {
+	Total      int `json:"total"`                // Articles found in the feed (0 until the feed is parsed)
+	Extracted  int `json:"extracted"`            // Articles whose content was extracted
+	Failed     int `json:"failed"`               // Articles whose extraction failed
+	New        int `json:"new,omitempty"`        // Processed articles that were new (process jobs)
+	Duplicates int `json:"duplicates,omitempty"` // Processed articles that were duplicates (process jobs)
+	Errors     int `json:"errors,omitempty"`     // Articles deduplication failed on (process jobs)
+}
+
+// FetchJob is an asynchronous feed fetch plus content extraction
+// (POST /api/jobs/fetch, GET /api/jobs/:id)
+type FetchJob struct {
+	ID         string          `json:"id"`
+	Status     JobStatus       `json:"status"`
+	FeedPreset string          `json:"feed_preset"`
+	Count      int             `json:"count"`
+	Process    bool            `json:"process,omitempty"`   // Also deduplicate the extracted articles
+	Namespace  string          `json:"namespace,omitempty"` // Dedup namespace of a process job
+	DryRun     bool            `json:"dry_run,omitempty"`   // Fetch for a preview only; no events are published
+	Progress   JobProgress     `json:"progress"`
+	Articles   []*Article      `json:"articles,omitempty"` // Set once the job succeeds
+	Results    []ArticleResult `json:"results,omitempty"`  // Dedup results of a process job, once it succeeds
+	Error      string          `json:"error,omitempty"`
+	RequestID  string          `json:"request_id,omitempty"` // Request ID of the submitting call
+	CreatedAt  time.Time       `json:"created_at"`
+	StartedAt  *time.Time      `json:"started_at,omitempty"`
+	FinishedAt *time.Time      `json:"finished_at,omitempty"`
+}
+
+// Summary returns a copy of the job without its articles and results
+func (j FetchJob) Summary() FetchJob {
+	j.Articles = nil
+	j.Results = nil
+	return j
+}
+
+// FetchJobRequest is the body of POST /api/jobs/fetch
+type FetchJobRequest struct {
+	FeedPreset string `json:"feed_preset"`
+	Count      int    `json:"count"`
+	// Process also deduplicates the extracted articles in Namespace, announcing
+	// each outcome on the article.new and article.duplicate topics
+	Process   bool   `json:"process,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	// DryRun fetches for a preview only: nothing is announced on article.fetched
+	// or the jobs topic. It can't be combined with Process.
+	DryRun bool `json:"dry_run,omitempty"`
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"orchestrator/types"
+	"strconv"
+	"time"
+)
+
+// eventsHeartbeat keeps idle SSE connections from being closed by proxies
+const eventsHeartbeat = 15 * time.Second
+
+// handleEvents handles GET /api/events, streaming orchestrator events as
+// server-sent events. Clients resume with the Last-Event-ID header (or
+// ?last_event_id=); a client that has missed events, or connects without an
+// ID, first receives a resync event and should reload GET /api/status.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("last_event_id")
+	}
+	afterSeq, err := strconv.ParseUint(lastEventID, 10, 64)
+	resume := lastEventID != "" && err == nil
+
+	replay, complete, head, events, cancel := s.stateManager.Events().Subscribe(afterSeq, resume)
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	if !complete {
+		resync := types.Event{Seq: head, Type: types.EventResync, Time: time.Now()}
+		if err := writeEvent(w, resync); err != nil {
+			return
+		}
+	}
+	for _, event := range replay {
+		if err := writeEvent(w, event); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(eventsHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				// Dropped for falling behind; the client reconnects and resumes
+				log.Printf("Event subscriber dropped after falling behind")
+				return
+			}
+			if err := writeEvent(w, event); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		case <-s.shutdown:
+			return
+		}
+	}
+}
+
+// writeEvent writes one event in SSE wire format
+func writeEvent(w http.ResponseWriter, event types.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.Seq, event.Type, data)
+	return err
+}
@@ -3,20 +3,33 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"orchestrator/state"
+	"orchestrator/store"
 	"orchestrator/types"
+	"orchestrator/workflow"
+	"strconv"
+	"strings"
 )
 
 // handleStatus handles GET /api/status
+// An optional ?run_id= selects a specific run; otherwise the latest run is shown.
 func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	status := s.stateManager.GetStatus()
+	status, err := s.stateManager.GetStatus(r.URL.Query().Get("run_id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	status.Schedules = s.Schedules()
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(status)
 }
@@ -24,6 +37,21 @@ func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
 // StartRequest represents the request body for start/refresh
 type StartRequest struct {
 	FeedPreset string `json:"feed_preset"`
+	Namespace  string `json:"namespace,omitempty"` // Falls back to the X-Dedup-Namespace header
+	DryRun     bool   `json:"dry_run,omitempty"`   // Also set by ?dry_run=true
+}
+
+// decodeStartRequest decodes the optional start/refresh body and resolves the namespace
+func decodeStartRequest(r *http.Request) StartRequest {
+	var req StartRequest
+	_ = json.NewDecoder(r.Body).Decode(&req)
+	if req.Namespace == "" {
+		req.Namespace = r.Header.Get(types.NamespaceHeader)
+	}
+	if dryRun, err := strconv.ParseBool(r.URL.Query().Get("dry_run")); err == nil && dryRun {
+		req.DryRun = true
+	}
+	return req
 }
 
 // handleStart handles POST /api/start
@@ -33,30 +61,7 @@ func (s *Server) handleStart(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var req StartRequest
-	// Decode optional body
-	_ = json.NewDecoder(r.Body).Decode(&req)
-
-	// Check if already running
-	currentState := s.stateManager.GetState()
-	if currentState != types.StateIdle && currentState != types.StateComplete && currentState != types.StateError {
-		http.Error(w, fmt.Sprintf("Workflow already running (state=%s)", currentState), http.StatusConflict)
-		return
-	}
-
-	// Start workflow asynchronously
-	go func() {
-		ctx := context.Background()
-		if err := s.workflowRunner.Run(ctx, req.FeedPreset); err != nil {
-			log.Printf("Workflow error: %v", err)
-		}
-	}()
-
-	w.WriteHeader(http.StatusAccepted)
-	json.NewEncoder(w).Encode(map[string]string{
-		"status":  "started",
-		"message": "Workflow initiated",
-	})
+	s.startRun(w, r, types.TriggerManual, true, "Workflow initiated")
 }
 
 // handleRefresh handles POST /api/refresh
@@ -66,29 +71,46 @@ func (s *Server) handleRefresh(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var req StartRequest
+	s.startRun(w, r, types.TriggerRefresh, false, "Refresh workflow initiated")
+}
+
+// startRun schedules a run for the request and reports its ID
+func (s *Server) startRun(w http.ResponseWriter, r *http.Request, trigger types.RunTrigger, clearCache bool, message string) {
 	// Decode optional body
-	_ = json.NewDecoder(r.Body).Decode(&req)
+	req := decodeStartRequest(r)
 
-	// Check if already running
-	currentState := s.stateManager.GetState()
-	if currentState != types.StateIdle && currentState != types.StateComplete && currentState != types.StateError {
-		http.Error(w, fmt.Sprintf("Workflow already running (state=%s)", currentState), http.StatusConflict)
+	// Runs outlive the request, so they don't inherit its context
+	run, err := s.workflowRunner.Start(context.Background(), workflow.RunOptions{
+		Trigger:    trigger,
+		FeedPreset: req.FeedPreset,
+		Namespace:  req.Namespace,
+		ClearCache: clearCache,
+		DryRun:     req.DryRun,
+	})
+	switch {
+	case errors.Is(err, state.ErrFeedBusy), errors.Is(err, state.ErrNamespaceBusy):
+		http.Error(w, fmt.Sprintf("Workflow already running: %v", err), http.StatusConflict)
+		return
+	case errors.Is(err, state.ErrConcurrencyLimit):
+		http.Error(w, fmt.Sprintf("Too many workflows running: %v", err), http.StatusTooManyRequests)
+		return
+	case err != nil:
+		log.Printf("Workflow start error: %v", err)
+		http.Error(w, fmt.Sprintf("Failed to start workflow: %v", err), http.StatusBadGateway)
 		return
 	}
 
-	// Start workflow asynchronously
-	go func() {
-		ctx := context.Background()
-		if err := s.workflowRunner.RunRefresh(ctx, req.FeedPreset); err != nil {
-			log.Printf("Workflow error: %v", err)
-		}
-	}()
+	if req.DryRun {
+		message = "Dry run initiated"
+	}
 
 	w.WriteHeader(http.StatusAccepted)
-	json.NewEncoder(w).Encode(map[string]string{
+	json.NewEncoder(w).Encode(map[string]interface{}{
 		"status":  "started",
-		"message": "Refresh workflow initiated",
+		"message": message,
+		"run_id":  run.ID(),
+		"feeds":   run.Feeds(),
+		"dry_run": req.DryRun,
 	})
 }
 
@@ -104,16 +126,147 @@ func (s *Server) handleWebhook(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Invalid JSON", http.StatusBadRequest)
 		return
 	}
+	if payload.UUID == "" {
+		http.Error(w, "Missing UUID", http.StatusBadRequest)
+		return
+	}
 
-	s.stateManager.SetWebhookPayload(&payload)
-	log.Printf("Webhook received: UUID=%s Status=%s", payload.UUID, payload.Status)
+	outcome := s.stateManager.HandleGenerationResult(&payload)
+	log.Printf("Webhook received: UUID=%s Status=%s Outcome=%s", payload.UUID, payload.Status, outcome)
 
+	// Parked and duplicate results are accepted so the sender doesn't retry them
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]string{
-		"status": "received",
+		"status":  "received",
+		"outcome": string(outcome),
 	})
 }
 
+// handleRuns handles GET /api/runs
+func (s *Server) handleRuns(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	limit := 20
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	runs, err := s.stateManager.ListRuns(limit)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to list runs: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"runs": runs,
+	})
+}
+
+// handleRun handles GET /api/runs/{id}
+func (s *Server) handleRun(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := strings.TrimPrefix(r.URL.Path, "/api/runs/")
+	if id == "" || strings.Contains(id, "/") {
+		http.NotFound(w, r)
+		return
+	}
+
+	run, err := s.stateManager.GetRun(id)
+	if errors.Is(err, store.ErrNotFound) {
+		http.Error(w, fmt.Sprintf("Run %s not found", id), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to load run: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(run)
+}
+
+// handleCandidates handles GET /api/candidates
+// ?status= filters by decision (default "pending"; "all" returns every candidate).
+func (s *Server) handleCandidates(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	status := types.CandidateStatus(r.URL.Query().Get("status"))
+	switch status {
+	case "":
+		status = types.CandidatePending
+	case "all":
+		status = ""
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(types.CandidatesResponse{
+		Candidates: s.stateManager.ListCandidates(status),
+	})
+}
+
+// handleCandidateDecision handles POST /api/candidates/{id}/approve and /api/candidates/{id}/reject
+func (s *Server) handleCandidateDecision(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/candidates/"), "/")
+	if len(parts) != 2 || parts[0] == "" || (parts[1] != "approve" && parts[1] != "reject") {
+		http.NotFound(w, r)
+		return
+	}
+	id, action := parts[0], parts[1]
+
+	// Body is optional
+	var decision types.CandidateDecision
+	_ = json.NewDecoder(r.Body).Decode(&decision)
+
+	candidate, err := s.stateManager.DecideCandidate(id, action == "approve", decision.Reason)
+	switch {
+	case errors.Is(err, state.ErrCandidateNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case errors.Is(err, state.ErrCandidateDecided):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(candidate)
+}
+
+// handleConfig handles GET /api/config, returning the effective configuration
+// with credentials redacted
+func (s *Server) handleConfig(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.config.Redacted())
+}
+
 // handleHealth handles GET /health
 func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
@@ -0,0 +1,268 @@
+package api
+
+import (
+	"brainbot/shared/rss"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"orchestrator/config"
+	"orchestrator/state"
+	"orchestrator/types"
+	"orchestrator/workflow"
+	"sort"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+const (
+	// maxQuietHoursLookahead bounds the search for the next run outside quiet hours
+	maxQuietHoursLookahead = 10000
+	// cronSlotRetries is how often a scheduled run waits for a free concurrency slot
+	cronSlotRetries       = 5
+	cronSlotRetryInterval = 30 * time.Second
+)
+
+// scheduledFeed is a feed's schedule and its cron entry (zero while disabled)
+type scheduledFeed struct {
+	schedule types.FeedSchedule
+	entryID  cron.EntryID
+}
+
+// StartCron schedules automated runs for every feed and starts the cron scheduler.
+// Feeds use their preset's schedule, falling back to the configured default.
+func (s *Server) StartCron() error {
+	defaults := s.config.Schedule
+	quietHours, err := config.ParseQuietHours(defaults.QuietHours)
+	if err != nil {
+		return fmt.Errorf("invalid quiet hours: %w", err)
+	}
+
+	presets, err := s.stateManager.GetIngestionClient().GetPresets(context.Background())
+	if err != nil {
+		log.Printf("Failed to load feed presets from ingestion service, using built-in presets: %v", err)
+		presets = rss.FeedPresets
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for feed, preset := range presets {
+		schedule := types.FeedSchedule{
+			Feed:          feed,
+			Cron:          preset.Schedule,
+			Enabled:       true,
+			QuietHours:    quietHours,
+			JitterSeconds: int(time.Duration(defaults.Jitter).Seconds()),
+		}
+		if schedule.Cron == "" {
+			schedule.Cron = defaults.Cron
+		}
+
+		parsed, err := parseSchedule(schedule)
+		if err != nil {
+			return fmt.Errorf("failed to schedule feed %s: %w", feed, err)
+		}
+		s.applySchedule(schedule, parsed)
+		log.Printf("Scheduled feed %s: %s", feed, schedule.Cron)
+	}
+
+	s.cron.Start()
+	return nil
+}
+
+// parseSchedule validates a feed schedule and parses its cron expression
+func parseSchedule(schedule types.FeedSchedule) (cron.Schedule, error) {
+	parsed, err := cron.ParseStandard(schedule.Cron)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cron %q: %w", schedule.Cron, err)
+	}
+	if schedule.QuietHours != nil {
+		if err := schedule.QuietHours.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	if schedule.JitterSeconds < 0 {
+		return nil, fmt.Errorf("jitter_seconds must not be negative")
+	}
+	return parsed, nil
+}
+
+// applySchedule replaces a feed's cron entry (must hold lock)
+func (s *Server) applySchedule(schedule types.FeedSchedule, parsed cron.Schedule) {
+	if existing, ok := s.schedules[schedule.Feed]; ok && existing.entryID != 0 {
+		s.cron.Remove(existing.entryID)
+	}
+
+	entry := &scheduledFeed{schedule: schedule}
+	if schedule.Enabled {
+		feed := schedule.Feed
+		entry.entryID = s.cron.Schedule(parsed, cron.FuncJob(func() { s.runScheduled(feed) }))
+	}
+	s.schedules[schedule.Feed] = entry
+}
+
+// runScheduled starts a cron run for one feed unless it is in quiet hours
+func (s *Server) runScheduled(feed string) {
+	s.mu.Lock()
+	entry, ok := s.schedules[feed]
+	s.mu.Unlock()
+	if !ok {
+		return
+	}
+	schedule := entry.schedule
+
+	if schedule.QuietHours != nil {
+		if quiet, _ := schedule.QuietHours.Contains(time.Now()); quiet {
+			log.Printf("Cron skipped %s: quiet hours %s-%s", feed, schedule.QuietHours.Start, schedule.QuietHours.End)
+			return
+		}
+	}
+
+	if schedule.JitterSeconds > 0 {
+		delay := time.Duration(rand.Int64N(int64(schedule.JitterSeconds) * int64(time.Second)))
+		select {
+		case <-time.After(delay):
+		case <-s.shutdown:
+			return
+		}
+	}
+
+	log.Printf("Cron triggered: starting automated workflow for feed %s", feed)
+
+	// Feeds scheduled at the same minute can briefly exceed the concurrency
+	// limit, so wait for a free slot; a feed already being processed is skipped
+	for attempt := 0; ; attempt++ {
+		run, err := s.workflowRunner.Start(context.Background(), workflow.RunOptions{
+			Trigger:    types.TriggerCron,
+			FeedPreset: feed,
+		})
+		switch {
+		case errors.Is(err, state.ErrConcurrencyLimit) && attempt < cronSlotRetries:
+			select {
+			case <-time.After(cronSlotRetryInterval):
+				continue
+			case <-s.shutdown:
+				return
+			}
+		case errors.Is(err, state.ErrFeedBusy), errors.Is(err, state.ErrNamespaceBusy), errors.Is(err, state.ErrConcurrencyLimit):
+			log.Printf("Cron skipped %s: %v", feed, err)
+		case err != nil:
+			log.Printf("Cron workflow error for %s: %v", feed, err)
+		default:
+			log.Printf("Cron started run %s for feed %s", run.ID(), feed)
+		}
+		return
+	}
+}
+
+// Schedules returns every feed's schedule with its next run time, sorted by feed (thread-safe)
+func (s *Server) Schedules() []types.FeedSchedule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	schedules := make([]types.FeedSchedule, 0, len(s.schedules))
+	for _, entry := range s.schedules {
+		schedule := entry.schedule
+		if entry.entryID != 0 {
+			if next := nextRun(s.cron.Entry(entry.entryID), schedule.QuietHours); !next.IsZero() {
+				schedule.NextRun = &next
+			}
+		}
+		schedules = append(schedules, schedule)
+	}
+
+	sort.Slice(schedules, func(i, j int) bool {
+		return schedules[i].Feed < schedules[j].Feed
+	})
+	return schedules
+}
+
+// nextRun returns the entry's next activation outside quiet hours
+func nextRun(entry cron.Entry, quiet *types.QuietHours) time.Time {
+	if entry.Schedule == nil {
+		return time.Time{}
+	}
+	next := entry.Next
+	if next.IsZero() {
+		next = entry.Schedule.Next(time.Now())
+	}
+	if quiet == nil {
+		return next
+	}
+
+	for i := 0; i < maxQuietHoursLookahead; i++ {
+		if inQuiet, err := quiet.Contains(next); err != nil || !inQuiet {
+			return next
+		}
+		next = entry.Schedule.Next(next)
+	}
+	return time.Time{}
+}
+
+// UpdateSchedules validates and applies schedule changes for known feeds. An
+// empty cron keeps the feed's current expression. Nothing is applied if any
+// schedule is invalid (thread-safe).
+func (s *Server) UpdateSchedules(updates []types.FeedSchedule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	parsed := make([]cron.Schedule, len(updates))
+	for i := range updates {
+		update := &updates[i]
+		existing, ok := s.schedules[update.Feed]
+		if !ok {
+			return fmt.Errorf("%w: %q", errUnknownFeed, update.Feed)
+		}
+		if update.Cron == "" {
+			update.Cron = existing.schedule.Cron
+		}
+		update.NextRun = nil
+
+		schedule, err := parseSchedule(*update)
+		if err != nil {
+			return fmt.Errorf("feed %s: %w", update.Feed, err)
+		}
+		parsed[i] = schedule
+	}
+
+	for i, update := range updates {
+		s.applySchedule(update, parsed[i])
+		log.Printf("Schedule for feed %s updated: %s (enabled: %t)", update.Feed, update.Cron, update.Enabled)
+	}
+	return nil
+}
+
+// errUnknownFeed is returned when a schedule names a feed without a preset
+var errUnknownFeed = errors.New("unknown feed")
+
+// handleSchedules handles GET and PUT /api/schedules
+func (s *Server) handleSchedules(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var req types.SchedulesResponse
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid JSON", http.StatusBadRequest)
+			return
+		}
+		if err := s.UpdateSchedules(req.Schedules); err != nil {
+			status := http.StatusBadRequest
+			if errors.Is(err, errUnknownFeed) {
+				status = http.StatusNotFound
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(types.SchedulesResponse{Schedules: s.Schedules()})
+}
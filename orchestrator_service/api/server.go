@@ -2,11 +2,10 @@ package api
 
 import (
 	"context"
-	"fmt"
 	"log"
 	"net/http"
+	"orchestrator/config"
 	"orchestrator/state"
-	"orchestrator/types"
 	"orchestrator/workflow"
 	"sync"
 
@@ -15,21 +14,24 @@ import (
 
 // Server is the orchestrator HTTP server
 type Server struct {
+	config         *config.Config
 	stateManager   *state.Manager
 	workflowRunner *workflow.Runner
 	httpServer     *http.Server
 	cron           *cron.Cron
-	cronID         cron.EntryID
+	schedules      map[string]*scheduledFeed // Feed preset -> schedule
 	mu             sync.Mutex
 	shutdown       chan struct{}
 }
 
 // NewServer creates a new orchestrator server
-func NewServer(stateManager *state.Manager, workflowRunner *workflow.Runner, port string) *Server {
+func NewServer(cfg *config.Config, stateManager *state.Manager, workflowRunner *workflow.Runner) *Server {
 	s := &Server{
+		config:         cfg,
 		stateManager:   stateManager,
 		workflowRunner: workflowRunner,
 		cron:           cron.New(),
+		schedules:      make(map[string]*scheduledFeed),
 		shutdown:       make(chan struct{}),
 	}
 
@@ -39,6 +41,13 @@ func NewServer(stateManager *state.Manager, workflowRunner *workflow.Runner, por
 	mux.HandleFunc("/api/status", s.handleStatus)
 	mux.HandleFunc("/api/start", s.handleStart)
 	mux.HandleFunc("/api/refresh", s.handleRefresh)
+	mux.HandleFunc("/api/runs", s.handleRuns)
+	mux.HandleFunc("/api/runs/", s.handleRun)
+	mux.HandleFunc("/api/candidates", s.handleCandidates)
+	mux.HandleFunc("/api/candidates/", s.handleCandidateDecision)
+	mux.HandleFunc("/api/schedules", s.handleSchedules)
+	mux.HandleFunc("/api/events", s.handleEvents)
+	mux.HandleFunc("/api/config", s.handleConfig)
 
 	// Webhook endpoint (called by generation service)
 	mux.HandleFunc("/webhook", s.handleWebhook)
@@ -47,7 +56,7 @@ func NewServer(stateManager *state.Manager, workflowRunner *workflow.Runner, por
 	mux.HandleFunc("/health", s.handleHealth)
 
 	s.httpServer = &http.Server{
-		Addr:    ":" + port,
+		Addr:    ":" + cfg.Port,
 		Handler: mux,
 	}
 
@@ -67,38 +76,6 @@ func (s *Server) Start() error {
 	return nil
 }
 
-// StartCron starts the cron jobs for automated workflow runs
-func (s *Server) StartCron(schedule string) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	// Add cron job
-	id, err := s.cron.AddFunc(schedule, func() {
-		log.Println("Cron triggered: starting automated workflow for all feeds")
-
-		// Only run if we're in idle or complete state
-		currentState := s.stateManager.GetState()
-		if currentState == types.StateIdle || currentState == types.StateComplete {
-			ctx := context.Background()
-			// Pass empty string to fetch all feeds
-			if err := s.workflowRunner.RunRefresh(ctx, ""); err != nil {
-				log.Printf("Cron workflow error: %v", err)
-			}
-		} else {
-			log.Printf("Cron skipped: orchestrator is busy (state=%s)", currentState)
-		}
-	})
-
-	if err != nil {
-		return fmt.Errorf("failed to add cron job: %w", err)
-	}
-
-	s.cronID = id
-	s.cron.Start()
-	log.Printf("Cron job started with schedule: %s", schedule)
-	return nil
-}
-
 // Shutdown gracefully shuts down the server
 func (s *Server) Shutdown(ctx context.Context) error {
 	log.Println("Shutting down orchestrator server...")
@@ -108,11 +85,9 @@ func (s *Server) Shutdown(ctx context.Context) error {
 		s.cron.Stop()
 	}
 
-	// Stop HTTP server
-	if err := s.httpServer.Shutdown(ctx); err != nil {
-		return err
-	}
-
+	// End event streams and pending scheduled runs so the HTTP server can drain
 	close(s.shutdown)
-	return nil
+
+	// Stop HTTP server
+	return s.httpServer.Shutdown(ctx)
 }
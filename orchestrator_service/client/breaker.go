@@ -0,0 +1,85 @@
+package client
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without calling the service while its circuit is open
+var ErrCircuitOpen = errors.New("circuit open: ingestion service unavailable")
+
+// circuitBreaker fails calls fast after repeated service failures. Once the
+// cooldown has passed a single probe call is let through; its outcome closes
+// or re-opens the circuit.
+type circuitBreaker struct {
+	name      string
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time // Zero while closed
+	probing  bool
+}
+
+// newCircuitBreaker creates a closed breaker (threshold 0 disables it)
+func newCircuitBreaker(name string, threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{name: name, threshold: threshold, cooldown: cooldown}
+}
+
+// allow reports whether a call may proceed (thread-safe)
+func (b *circuitBreaker) allow() error {
+	if b.threshold <= 0 {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.openedAt.IsZero() {
+		return nil
+	}
+	if b.probing || time.Since(b.openedAt) < b.cooldown {
+		return ErrCircuitOpen
+	}
+	b.probing = true
+	return nil
+}
+
+// success records a call that reached a healthy service (thread-safe)
+func (b *circuitBreaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.openedAt.IsZero() {
+		log.Printf("%s circuit closed", b.name)
+	}
+	b.failures = 0
+	b.openedAt = time.Time{}
+	b.probing = false
+}
+
+// failure records a service failure, opening the circuit at the threshold (thread-safe)
+func (b *circuitBreaker) failure() {
+	if b.threshold <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	if b.probing || (b.openedAt.IsZero() && b.failures >= b.threshold) {
+		log.Printf("%s circuit opened after %d consecutive failure(s), retrying in %s", b.name, b.failures, b.cooldown)
+		b.openedAt = time.Now()
+		b.probing = false
+	}
+}
+
+// release ends a call whose outcome says nothing about the service, such as
+// one cancelled by the caller (thread-safe)
+func (b *circuitBreaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
@@ -2,31 +2,33 @@ package client
 
 import (
 	"net/http"
-	"os"
-	"time"
+)
+
+const (
+	// DefaultIngestionURL is the ingestion service's address on the Docker network
+	DefaultIngestionURL = "http://ingestion-service:8080"
+	// DefaultGenerationURL is the generation service's address on the Docker network
+	DefaultGenerationURL = "http://generation-service:8000"
 )
 
 // IngestionClient represents the client for the ingestion service API
 type IngestionClient struct {
 	baseURL    string
 	httpClient *http.Client
+	policy     RetryPolicy
+	breaker    *circuitBreaker
 }
 
-// NewIngestionClient creates a new ingestion service client
-func NewIngestionClient(baseURL string) *IngestionClient {
+// NewIngestionClient creates a new ingestion service client. Timeouts come
+// from the policy and apply per attempt.
+func NewIngestionClient(baseURL string, policy RetryPolicy) *IngestionClient {
 	if baseURL == "" {
-		baseURL = getEnvOrDefault("API_URL", "http://ingestion-service:8080")
+		baseURL = DefaultIngestionURL
 	}
 	return &IngestionClient{
 		baseURL:    baseURL,
-		httpClient: &http.Client{Timeout: 30 * time.Second},
-	}
-}
-
-// getEnvOrDefault returns the value of an environment variable or a default value
-func getEnvOrDefault(key, defaultVal string) string {
-	if val := os.Getenv(key); val != "" {
-		return val
+		httpClient: &http.Client{},
+		policy:     policy,
+		breaker:    newCircuitBreaker("Ingestion", policy.BreakerThreshold, policy.BreakerCooldown),
 	}
-	return defaultVal
 }
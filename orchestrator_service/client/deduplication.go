@@ -2,18 +2,28 @@ package client
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"orchestrator/types"
 )
 
-// CheckDuplicate checks if an article is a duplicate via the ingestion API
-func (c *IngestionClient) CheckDuplicate(ctx context.Context, article *types.Article) (*types.DeduplicationResult, error) {
+// CheckDuplicate checks if an article is a duplicate via the ingestion API.
+// The check is read-only, so failed attempts are retried.
+func (c *IngestionClient) CheckDuplicate(ctx context.Context, namespace string, article *types.Article) (*types.DeduplicationResult, error) {
 	payload := map[string]interface{}{
 		"article": article,
 	}
 
 	var result types.DeduplicationResult
-	if err := c.doJSONRequest(ctx, http.MethodPost, "/api/deduplication/check", payload, &result); err != nil {
+	err := c.do(ctx, request{
+		method:    http.MethodPost,
+		path:      "/api/deduplication/check",
+		namespace: namespace,
+		payload:   payload,
+		result:    &result,
+		retry:     true,
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -21,16 +31,16 @@ func (c *IngestionClient) CheckDuplicate(ctx context.Context, article *types.Art
 }
 
 // AddArticle adds an article to the deduplication database via the ingestion API
-func (c *IngestionClient) AddArticle(ctx context.Context, article *types.Article) error {
+func (c *IngestionClient) AddArticle(ctx context.Context, namespace string, article *types.Article) error {
 	payload := map[string]interface{}{
 		"article": article,
 	}
 
-	return c.doJSONRequest(ctx, http.MethodPost, "/api/deduplication/add", payload, nil)
+	return c.doJSONRequest(ctx, http.MethodPost, "/api/deduplication/add", namespace, payload, nil)
 }
 
 // ProcessArticle processes an article (checks for duplicates and adds if new) via the ingestion API
-func (c *IngestionClient) ProcessArticle(ctx context.Context, article *types.Article) (*types.ArticleResult, error) {
+func (c *IngestionClient) ProcessArticle(ctx context.Context, namespace string, article *types.Article) (*types.ArticleResult, error) {
 	payload := map[string]interface{}{
 		"article": article,
 	}
@@ -42,7 +52,7 @@ func (c *IngestionClient) ProcessArticle(ctx context.Context, article *types.Art
 		Error               string                      `json:"error,omitempty"`
 	}
 
-	if err := c.doJSONRequest(ctx, http.MethodPost, "/api/deduplication/process", payload, &result); err != nil {
+	if err := c.doJSONRequest(ctx, http.MethodPost, "/api/deduplication/process", namespace, payload, &result); err != nil {
 		return nil, err
 	}
 
@@ -56,7 +66,7 @@ func (c *IngestionClient) ProcessArticle(ctx context.Context, article *types.Art
 }
 
 // ProcessArticles processes multiple articles
-func (c *IngestionClient) ProcessArticles(ctx context.Context, articles []*types.Article) ([]types.ArticleResult, error) {
+func (c *IngestionClient) ProcessArticles(ctx context.Context, namespace string, articles []*types.Article) ([]types.ArticleResult, error) {
 	results := make([]types.ArticleResult, 0, len(articles))
 
 	for _, article := range articles {
@@ -70,7 +80,7 @@ func (c *IngestionClient) ProcessArticles(ctx context.Context, articles []*types
 			continue
 		}
 
-		result, err := c.ProcessArticle(ctx, article)
+		result, err := c.ProcessArticle(ctx, namespace, article)
 		if err != nil {
 			results = append(results, types.ArticleResult{
 				Article: article,
@@ -86,18 +96,56 @@ func (c *IngestionClient) ProcessArticles(ctx context.Context, articles []*types
 	return results, nil
 }
 
-// ClearCache clears the deduplication cache via the ingestion API
-func (c *IngestionClient) ClearCache(ctx context.Context) error {
-	return c.doJSONRequest(ctx, http.MethodDelete, "/api/deduplication/clear", nil, nil)
+// CheckArticles reports the deduplication outcome of multiple articles without
+// modifying dedup state; new articles are not stored and get no presigned URL.
+// The batch is checked in one call so near-duplicates within it are caught as
+// processing them in order would. The check is read-only, so it is retried.
+func (c *IngestionClient) CheckArticles(ctx context.Context, namespace string, articles []*types.Article) ([]types.ArticleResult, error) {
+	if len(articles) == 0 {
+		return []types.ArticleResult{}, nil
+	}
+
+	payload := map[string]interface{}{
+		"articles": articles,
+	}
+
+	var response struct {
+		Results []types.ArticleResult `json:"results"`
+	}
+	err := c.do(ctx, request{
+		method:    http.MethodPost,
+		path:      "/api/deduplication/check-batch",
+		namespace: namespace,
+		payload:   payload,
+		result:    &response,
+		retry:     true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(response.Results) != len(articles) {
+		return nil, fmt.Errorf("check-batch returned %d results for %d articles", len(response.Results), len(articles))
+	}
+
+	// Keep the caller's articles rather than the echoed copies
+	for i := range response.Results {
+		response.Results[i].Article = articles[i]
+	}
+	return response.Results, nil
+}
+
+// ClearCache clears the namespace's deduplication cache via the ingestion API
+func (c *IngestionClient) ClearCache(ctx context.Context, namespace string) error {
+	return c.doJSONRequest(ctx, http.MethodDelete, "/api/deduplication/clear", namespace, nil, nil)
 }
 
 // GetCount gets the number of documents in the deduplication database via the ingestion API
-func (c *IngestionClient) GetCount(ctx context.Context) (int, error) {
+func (c *IngestionClient) GetCount(ctx context.Context, namespace string) (int, error) {
 	var result struct {
 		Count int `json:"count"`
 	}
 
-	if err := c.doJSONRequest(ctx, http.MethodGet, "/api/deduplication/count", nil, &result); err != nil {
+	if err := c.doJSONRequest(ctx, http.MethodGet, "/api/deduplication/count", namespace, nil, &result); err != nil {
 		return 0, err
 	}
 
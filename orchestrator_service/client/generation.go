@@ -0,0 +1,106 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"orchestrator/types"
+	"time"
+)
+
+// GenerationRequest is the body of POST /generate on the generation service
+type GenerationRequest struct {
+	UUID         string   `json:"uuid"`
+	PresignedURL string   `json:"presigned_url"`
+	ArticleURLs  []string `json:"article_urls,omitempty"` // Optional field in schema
+}
+
+// GenerationClient represents the client for the generation service API
+type GenerationClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewGenerationClient creates a new generation service client
+func NewGenerationClient(baseURL string) *GenerationClient {
+	if baseURL == "" {
+		baseURL = DefaultGenerationURL
+	}
+	return &GenerationClient{
+		baseURL:    baseURL,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Generate submits a story for video generation. The service accepts the
+// request and reports the result asynchronously via Kafka or webhook.
+func (c *GenerationClient) Generate(ctx context.Context, request GenerationRequest) error {
+	jsonData, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/generate", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(types.RequestIDHeader, RequestID(ctx))
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusAccepted {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("generation service returned %d: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+// Status polls the generation service for a request's status.
+// It returns nil while the request is still in progress or the service doesn't know it.
+func (c *GenerationClient) Status(ctx context.Context, generationUUID string) (*types.WebhookPayload, error) {
+	url := fmt.Sprintf("%s/status/%s", c.baseURL, generationUUID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set(types.RequestIDHeader, RequestID(ctx))
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request status: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("generation service returned %d", resp.StatusCode)
+	}
+
+	// Finished requests carry the same fields as the Kafka result
+	var status types.WebhookPayload
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return nil, fmt.Errorf("decode status: %w", err)
+	}
+
+	switch status.Status {
+	case types.GenerationStatusSuccess, "failure", "failed", "error":
+		if status.UUID == "" {
+			status.UUID = generationUUID
+		}
+		return &status, nil
+	default:
+		// Queued or still processing
+		return nil, nil
+	}
+}
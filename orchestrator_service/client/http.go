@@ -6,34 +6,122 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
+	"orchestrator/types"
+	"time"
 )
 
+// request describes one ingestion API call
+type request struct {
+	method    string
+	path      string
+	namespace string      // Sent as the dedup namespace header when non-empty
+	payload   interface{} // Encoded as the JSON body when non-nil
+	result    interface{} // Decoded from the JSON response when non-nil
+	// retry marks calls that are safe to repeat: idempotent methods and
+	// read-only POSTs. Calls that change dedup state are attempted once.
+	retry   bool
+	timeout time.Duration // Per-attempt timeout (defaults to the policy's)
+}
+
 // doJSONRequest performs a JSON request with the given method, path, payload, and result.
 // It handles marshaling the payload, creating the request, executing it, and unmarshaling the response.
-// If result is nil, the response body is not decoded.
-func (c *IngestionClient) doJSONRequest(ctx context.Context, method, path string, payload, result interface{}) error {
-	url := fmt.Sprintf("%s%s", c.baseURL, path)
+// If result is nil, the response body is not decoded. A non-empty namespace is sent as the
+// dedup namespace header. GET and DELETE requests are retried.
+func (c *IngestionClient) doJSONRequest(ctx context.Context, method, path, namespace string, payload, result interface{}) error {
+	return c.do(ctx, request{
+		method:    method,
+		path:      path,
+		namespace: namespace,
+		payload:   payload,
+		result:    result,
+		retry:     method == http.MethodGet || method == http.MethodDelete,
+	})
+}
 
-	var body io.Reader
-	if payload != nil {
-		jsonData, err := json.Marshal(payload)
+// do performs a request through the circuit breaker, retrying safe calls with
+// exponential backoff. Every attempt carries the same request ID.
+func (c *IngestionClient) do(ctx context.Context, req request) error {
+	var body []byte
+	if req.payload != nil {
+		jsonData, err := json.Marshal(req.payload)
 		if err != nil {
 			return fmt.Errorf("failed to marshal request: %w", err)
 		}
-		body = bytes.NewBuffer(jsonData)
+		body = jsonData
 	}
 
-	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	requestID := RequestID(ctx)
+	attempts := 1
+	if req.retry {
+		attempts += c.policy.MaxRetries
+	}
+
+	for attempt := 1; ; attempt++ {
+		if err := c.breaker.allow(); err != nil {
+			return fmt.Errorf("%s %s: %w", req.method, req.path, err)
+		}
+
+		err := c.attempt(ctx, req, body, requestID)
+		switch {
+		case err == nil:
+			c.breaker.success()
+			return nil
+		case isServiceFailure(ctx, err):
+			c.breaker.failure()
+		case ctx.Err() != nil:
+			c.breaker.release()
+		default:
+			c.breaker.success()
+		}
+
+		if attempt >= attempts || !isRetryable(ctx, err) {
+			return fmt.Errorf("%w (request %s)", err, requestID)
+		}
+
+		delay := c.policy.backoff(attempt)
+		log.Printf("Ingestion %s %s failed (attempt %d/%d, request %s), retrying in %s: %v",
+			req.method, req.path, attempt, attempts, requestID, delay.Round(time.Millisecond), err)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return fmt.Errorf("%w (request %s)", err, requestID)
+		}
+	}
+}
+
+// attempt performs a single HTTP round trip
+func (c *IngestionClient) attempt(ctx context.Context, req request, body []byte, requestID string) error {
+	timeout := req.timeout
+	if timeout <= 0 {
+		timeout = c.policy.Timeout
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, c.baseURL+req.path, reader)
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 
-	if payload != nil {
-		req.Header.Set("Content-Type", "application/json")
+	if body != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	if req.namespace != "" {
+		httpReq.Header.Set(types.NamespaceHeader, req.namespace)
 	}
+	httpReq.Header.Set(types.RequestIDHeader, requestID)
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.httpClient.Do(httpReq)
 	if err != nil {
 		return fmt.Errorf("failed to send request: %w", err)
 	}
@@ -41,11 +129,11 @@ func (c *IngestionClient) doJSONRequest(ctx context.Context, method, path string
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
 		bodyBytes, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("API returned %d: %s", resp.StatusCode, string(bodyBytes))
+		return &APIError{StatusCode: resp.StatusCode, Body: string(bodyBytes)}
 	}
 
-	if result != nil {
-		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+	if req.result != nil {
+		if err := json.NewDecoder(resp.Body).Decode(req.result); err != nil {
 			return fmt.Errorf("failed to decode response: %w", err)
 		}
 	}
@@ -0,0 +1,23 @@
+package client
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// requestIDKey is the context key for the request ID sent to other services
+type requestIDKey struct{}
+
+// WithRequestID returns a context whose service calls carry the given request ID
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the context's request ID, or a new one if it has none
+func RequestID(ctx context.Context) string {
+	if id, ok := ctx.Value(requestIDKey{}).(string); ok && id != "" {
+		return id
+	}
+	return uuid.NewString()
+}
@@ -0,0 +1,83 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy controls how the ingestion client retries failed calls and when
+// it stops calling a service that appears to be down
+type RetryPolicy struct {
+	MaxRetries       int           // Extra attempts for idempotent or read-only calls
+	BaseDelay        time.Duration // Backoff before the first retry (doubles each attempt, with jitter)
+	MaxDelay         time.Duration // Upper bound on a single backoff
+	Timeout          time.Duration // Per-attempt timeout
+	FetchTimeout     time.Duration // How long a feed fetch, which extracts every article, may take (per attempt when synchronous)
+	BreakerThreshold int           // Consecutive failures that open the circuit (0 disables)
+	BreakerCooldown  time.Duration // How long the circuit stays open before a probe call
+}
+
+// DefaultRetryPolicy returns the default retry policy
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries:       3,
+		BaseDelay:        500 * time.Millisecond,
+		MaxDelay:         10 * time.Second,
+		Timeout:          30 * time.Second,
+		FetchTimeout:     3 * time.Minute,
+		BreakerThreshold: 5,
+		BreakerCooldown:  30 * time.Second,
+	}
+}
+
+// backoff returns the delay before retry number attempt (1-based), using full jitter
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempt && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int64N(int64(delay))) + 1
+}
+
+// APIError is a non-success response from the ingestion service
+type APIError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API returned %d: %s", e.StatusCode, e.Body)
+}
+
+// isServiceFailure reports whether err means the service is unhealthy: it was
+// unreachable, timed out or answered 5xx. Client errors and cancellation by
+// the caller don't count.
+func isServiceFailure(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= http.StatusInternalServerError
+	}
+	return true
+}
+
+// isRetryable reports whether a failed attempt is worth repeating
+func isRetryable(ctx context.Context, err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusTooManyRequests {
+		return ctx.Err() == nil
+	}
+	return isServiceFailure(ctx, err)
+}
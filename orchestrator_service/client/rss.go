@@ -2,75 +2,117 @@ package client
 
 import (
 	"brainbot/shared/rss"
-	"bytes"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"orchestrator/types"
+	"time"
 )
 
 type FetchRequest struct {
 	FeedPreset string `json:"feed_preset"`
 	Count      int    `json:"count"`
+	DryRun     bool   `json:"dry_run,omitempty"`
 }
 
-// FetchArticles fetches articles from RSS feed via ingestion service
-func (c *IngestionClient) FetchArticles(ctx context.Context, feedPreset string, count int) ([]*types.Article, error) {
-	reqBody := FetchRequest{
-		FeedPreset: feedPreset,
-		Count:      count,
-	}
+// fetchJobPollInterval is how often FetchArticles checks on its fetch job
+const fetchJobPollInterval = time.Second
 
-	jsonBody, err := json.Marshal(reqBody)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
+// FetchArticles fetches articles from RSS feed via ingestion service. The fetch
+// runs as an ingestion job that is polled until it finishes, bounded by the
+// policy's FetchTimeout; ingestion services without the job API are called
+// synchronously instead, with FetchTimeout bounding each attempt. A dryRun
+// fetch isn't announced on the ingestion service's Kafka topics.
+func (c *IngestionClient) FetchArticles(ctx context.Context, feedPreset string, count int, dryRun bool) ([]*types.Article, error) {
+	job, err := c.SubmitFetchJob(ctx, types.FetchJobRequest{FeedPreset: feedPreset, Count: count, DryRun: dryRun})
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		return c.fetchArticlesSync(ctx, FetchRequest{FeedPreset: feedPreset, Count: count, DryRun: dryRun})
 	}
-
-	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/fetch", bytes.NewBuffer(jsonBody))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, err
 	}
-	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+	if c.policy.FetchTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.policy.FetchTimeout)
+		defer cancel()
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("ingestion service returned status: %d", resp.StatusCode)
-	}
+	ticker := time.NewTicker(fetchJobPollInterval)
+	defer ticker.Stop()
 
-	var articles []*types.Article
-	if err := json.NewDecoder(resp.Body).Decode(&articles); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	for !job.Status.Done() {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil, fmt.Errorf("fetch job %s: %w", job.ID, ctx.Err())
+		}
+
+		if job, err = c.GetFetchJob(ctx, job.ID); err != nil {
+			return nil, err
+		}
 	}
 
-	return articles, nil
+	if job.Status == types.JobFailed {
+		return nil, fmt.Errorf("fetch job %s failed: %s", job.ID, job.Error)
+	}
+	return job.Articles, nil
 }
 
-// GetPresets fetches available RSS feed presets
-func (c *IngestionClient) GetPresets(ctx context.Context) (map[string]rss.FeedConfig, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+"/presets", nil)
+// SubmitFetchJob starts an asynchronous feed fetch. A repeated fetch-only
+// submission just costs a redundant fetch, so those are retried; process jobs
+// write to the dedup state and are sent once.
+func (c *IngestionClient) SubmitFetchJob(ctx context.Context, req types.FetchJobRequest) (*types.FetchJob, error) {
+	var job types.FetchJob
+	err := c.do(ctx, request{
+		method:    http.MethodPost,
+		path:      "/api/jobs/fetch",
+		namespace: req.Namespace,
+		payload:   req,
+		result:    &job,
+		retry:     !req.Process,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, err
 	}
+	return &job, nil
+}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+// GetFetchJob reports a fetch job's status, including its articles once it succeeds
+func (c *IngestionClient) GetFetchJob(ctx context.Context, id string) (*types.FetchJob, error) {
+	var job types.FetchJob
+	if err := c.doJSONRequest(ctx, http.MethodGet, "/api/jobs/"+url.PathEscape(id), "", nil, &job); err != nil {
+		return nil, err
 	}
-	defer resp.Body.Close()
+	return &job, nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("ingestion service returned status: %d", resp.StatusCode)
+// fetchArticlesSync fetches a feed with the blocking POST /fetch endpoint
+func (c *IngestionClient) fetchArticlesSync(ctx context.Context, req FetchRequest) ([]*types.Article, error) {
+	var articles []*types.Article
+	err := c.do(ctx, request{
+		method:  http.MethodPost,
+		path:    "/fetch",
+		payload: req,
+		result:  &articles,
+		retry:   true,
+		timeout: c.policy.FetchTimeout,
+	})
+	if err != nil {
+		return nil, err
 	}
 
+	return articles, nil
+}
+
+// GetPresets fetches available RSS feed presets
+func (c *IngestionClient) GetPresets(ctx context.Context) (map[string]rss.FeedConfig, error) {
 	var presets map[string]rss.FeedConfig
-	if err := json.NewDecoder(resp.Body).Decode(&presets); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	if err := c.doJSONRequest(ctx, http.MethodGet, "/presets", "", nil, &presets); err != nil {
+		return nil, err
 	}
 
 	return presets, nil
@@ -0,0 +1,350 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"orchestrator/client"
+	"orchestrator/state"
+	"orchestrator/types"
+	"orchestrator/workflow"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Duration is a time.Duration written as "15m" in config files and JSON
+type Duration time.Duration
+
+// MarshalText implements encoding.TextMarshaler
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// Config is the orchestrator's effective configuration
+type Config struct {
+	File              string           `json:"config_file,omitempty"` // The file that was loaded, if any
+	Port              string           `json:"port"`
+	WebhookPort       string           `json:"webhook_port"`
+	IngestionURL      string           `json:"ingestion_url"`
+	GenerationURL     string           `json:"generation_url"`
+	RunsDB            string           `json:"runs_db"`
+	MaxConcurrentRuns int              `json:"max_concurrent_runs"`
+	Ingestion         IngestionConfig  `json:"ingestion"`
+	Schedule          ScheduleConfig   `json:"schedule"`
+	Generation        GenerationConfig `json:"generation"`
+	Selection         SelectionConfig  `json:"selection"`
+	Approval          ApprovalConfig   `json:"approval"`
+	Kafka             KafkaConfig      `json:"kafka"`
+}
+
+// Ingestion modes
+const (
+	IngestionModeHTTP   = "http"   // Runs fetch and deduplicate through the ingestion HTTP API
+	IngestionModeEvents = "events" // Ingestion jobs fetch and deduplicate; results arrive over Kafka
+)
+
+// IngestionConfig controls how runs reach the ingestion service, and retries
+// and circuit breaking for its calls
+type IngestionConfig struct {
+	Mode             string   `json:"mode"`
+	Timeout          Duration `json:"timeout"`
+	FetchTimeout     Duration `json:"fetch_timeout"`
+	MaxRetries       int      `json:"max_retries"`
+	RetryBackoff     Duration `json:"retry_backoff"`
+	BreakerThreshold int      `json:"breaker_threshold"`
+	BreakerCooldown  Duration `json:"breaker_cooldown"`
+}
+
+// ScheduleConfig holds the defaults for feeds whose preset doesn't set a schedule
+type ScheduleConfig struct {
+	Cron       string   `json:"cron"`
+	QuietHours string   `json:"quiet_hours,omitempty"` // "HH:MM-HH:MM"
+	Jitter     Duration `json:"jitter,omitempty"`
+}
+
+// GenerationConfig controls how long runs wait for the generation service
+type GenerationConfig struct {
+	Timeout      Duration `json:"timeout"`
+	PollInterval Duration `json:"poll_interval,omitempty"`
+	MaxRetries   int      `json:"max_retries"`
+	RetryBackoff Duration `json:"retry_backoff"`
+}
+
+// SelectionConfig controls which new stories become videos
+type SelectionConfig struct {
+	MaxVideos int    `json:"max_videos"`
+	RankBy    string `json:"rank_by"`
+}
+
+// ApprovalConfig controls the editorial approval step
+type ApprovalConfig struct {
+	Required     bool     `json:"required"`
+	TrustedFeeds []string `json:"trusted_feeds,omitempty"`
+	Timeout      Duration `json:"timeout"`
+}
+
+// KafkaConfig selects where generation and video results are consumed from
+type KafkaConfig struct {
+	Brokers      []string `json:"brokers"`
+	Topic        string   `json:"topic"`
+	GroupID      string   `json:"group_id"`
+	ResultsTopic string   `json:"results_topic,omitempty"` // Video results from the creation service; empty ignores them
+}
+
+// Default returns the built-in configuration
+func Default() Config {
+	retry := client.DefaultRetryPolicy()
+	generation := workflow.DefaultGenerationPolicy()
+	selection := workflow.DefaultSelectionPolicy()
+	approval := workflow.DefaultApprovalPolicy()
+
+	return Config{
+		Port:              "8081",
+		WebhookPort:       "9999",
+		IngestionURL:      client.DefaultIngestionURL,
+		GenerationURL:     client.DefaultGenerationURL,
+		RunsDB:            "data/runs.db",
+		MaxConcurrentRuns: state.DefaultMaxConcurrentRuns,
+		Ingestion: IngestionConfig{
+			Mode:             IngestionModeHTTP,
+			Timeout:          Duration(retry.Timeout),
+			FetchTimeout:     Duration(retry.FetchTimeout),
+			MaxRetries:       retry.MaxRetries,
+			RetryBackoff:     Duration(retry.BaseDelay),
+			BreakerThreshold: retry.BreakerThreshold,
+			BreakerCooldown:  Duration(retry.BreakerCooldown),
+		},
+		Schedule: ScheduleConfig{
+			Cron: "*/5 * * * *",
+		},
+		Generation: GenerationConfig{
+			Timeout:      Duration(generation.Deadline),
+			PollInterval: Duration(generation.PollInterval),
+			MaxRetries:   generation.MaxRetries,
+			RetryBackoff: Duration(generation.RetryBackoff),
+		},
+		Selection: SelectionConfig{
+			MaxVideos: selection.MaxVideos,
+			RankBy:    string(selection.RankBy),
+		},
+		Approval: ApprovalConfig{
+			Timeout: Duration(approval.Timeout),
+		},
+		Kafka: KafkaConfig{
+			Brokers:      []string{"kafka:9092"},
+			Topic:        "video-processing-requests",
+			GroupID:      "orchestrator-consumer-group",
+			ResultsTopic: types.TopicVideoResults,
+		},
+	}
+}
+
+// Validate reports every invalid setting at once
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(err error, field string) {
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", field, err))
+		}
+	}
+
+	check(validatePort(c.Port), "port")
+	check(validatePort(c.WebhookPort), "webhook_port")
+	check(validateURL(c.IngestionURL), "ingestion_url")
+	check(validateURL(c.GenerationURL), "generation_url")
+	if c.RunsDB == "" {
+		check(errors.New("must not be empty"), "runs_db")
+	}
+	if c.MaxConcurrentRuns < 1 {
+		check(errors.New("must be at least 1"), "max_concurrent_runs")
+	}
+
+	if c.Ingestion.Mode != IngestionModeHTTP && c.Ingestion.Mode != IngestionModeEvents {
+		check(fmt.Errorf("unknown mode %q (want %s or %s)", c.Ingestion.Mode, IngestionModeHTTP, IngestionModeEvents), "ingestion.mode")
+	}
+	if c.Ingestion.Timeout <= 0 {
+		check(errors.New("must be positive"), "ingestion.timeout")
+	}
+	if c.Ingestion.FetchTimeout <= 0 {
+		check(errors.New("must be positive"), "ingestion.fetch_timeout")
+	}
+	if c.Ingestion.MaxRetries < 0 {
+		check(errors.New("must not be negative"), "ingestion.max_retries")
+	}
+	if c.Ingestion.RetryBackoff < 0 {
+		check(errors.New("must not be negative"), "ingestion.retry_backoff")
+	}
+	if c.Ingestion.BreakerThreshold < 0 {
+		check(errors.New("must not be negative"), "ingestion.breaker_threshold")
+	}
+	if c.Ingestion.BreakerThreshold > 0 && c.Ingestion.BreakerCooldown <= 0 {
+		check(errors.New("must be positive while the breaker is enabled"), "ingestion.breaker_cooldown")
+	}
+
+	if _, err := cron.ParseStandard(c.Schedule.Cron); err != nil {
+		check(err, "schedule.cron")
+	}
+	_, err := ParseQuietHours(c.Schedule.QuietHours)
+	check(err, "schedule.quiet_hours")
+	if c.Schedule.Jitter < 0 {
+		check(errors.New("must not be negative"), "schedule.jitter")
+	}
+
+	if c.Generation.Timeout <= 0 {
+		check(errors.New("must be positive"), "generation.timeout")
+	}
+	if c.Generation.PollInterval < 0 {
+		check(errors.New("must not be negative"), "generation.poll_interval")
+	}
+	if c.Generation.MaxRetries < 0 {
+		check(errors.New("must not be negative"), "generation.max_retries")
+	}
+	if c.Generation.RetryBackoff < 0 {
+		check(errors.New("must not be negative"), "generation.retry_backoff")
+	}
+
+	if c.Selection.MaxVideos < 0 {
+		check(errors.New("must not be negative"), "selection.max_videos")
+	}
+	_, err = workflow.ParseRankStrategy(c.Selection.RankBy)
+	check(err, "selection.rank_by")
+
+	if c.Approval.Timeout <= 0 {
+		check(errors.New("must be positive"), "approval.timeout")
+	}
+
+	if len(c.Kafka.Brokers) == 0 {
+		check(errors.New("at least one broker is required"), "kafka.brokers")
+	}
+	if c.Kafka.Topic == "" {
+		check(errors.New("must not be empty"), "kafka.topic")
+	}
+	if c.Kafka.GroupID == "" {
+		check(errors.New("must not be empty"), "kafka.group_id")
+	}
+
+	return errors.Join(errs...)
+}
+
+// RetryPolicy converts the ingestion settings for client.NewIngestionClient
+func (c *Config) RetryPolicy() client.RetryPolicy {
+	policy := client.DefaultRetryPolicy()
+	policy.Timeout = time.Duration(c.Ingestion.Timeout)
+	policy.FetchTimeout = time.Duration(c.Ingestion.FetchTimeout)
+	policy.MaxRetries = c.Ingestion.MaxRetries
+	policy.BaseDelay = time.Duration(c.Ingestion.RetryBackoff)
+	policy.BreakerThreshold = c.Ingestion.BreakerThreshold
+	policy.BreakerCooldown = time.Duration(c.Ingestion.BreakerCooldown)
+	return policy
+}
+
+// RunnerConfig converts the workflow settings for workflow.NewRunner (call after Validate)
+func (c *Config) RunnerConfig() workflow.RunnerConfig {
+	rankBy, _ := workflow.ParseRankStrategy(c.Selection.RankBy)
+	return workflow.RunnerConfig{
+		Generation: workflow.GenerationPolicy{
+			Deadline:     time.Duration(c.Generation.Timeout),
+			PollInterval: time.Duration(c.Generation.PollInterval),
+			MaxRetries:   c.Generation.MaxRetries,
+			RetryBackoff: time.Duration(c.Generation.RetryBackoff),
+		},
+		Selection: workflow.SelectionPolicy{
+			MaxVideos: c.Selection.MaxVideos,
+			RankBy:    rankBy,
+		},
+		Approval: workflow.ApprovalPolicy{
+			Required:     c.Approval.Required,
+			TrustedFeeds: c.Approval.TrustedFeeds,
+			Timeout:      time.Duration(c.Approval.Timeout),
+		},
+	}
+}
+
+// Redacted returns a copy that is safe to log or serve: credentials embedded
+// in URLs and broker addresses are masked
+func (c Config) Redacted() Config {
+	c.IngestionURL = redactURL(c.IngestionURL)
+	c.GenerationURL = redactURL(c.GenerationURL)
+
+	brokers := make([]string, len(c.Kafka.Brokers))
+	for i, broker := range c.Kafka.Brokers {
+		if at := strings.LastIndex(broker, "@"); at >= 0 {
+			broker = "xxxxx" + broker[at:]
+		}
+		brokers[i] = broker
+	}
+	c.Kafka.Brokers = brokers
+	return c
+}
+
+// String renders the redacted configuration as indented JSON
+func (c Config) String() string {
+	data, err := json.MarshalIndent(c.Redacted(), "", "  ")
+	if err != nil {
+		return fmt.Sprintf("<invalid config: %v>", err)
+	}
+	return string(data)
+}
+
+// ParseQuietHours parses a "HH:MM-HH:MM" window; an empty value means no quiet hours
+func ParseQuietHours(value string) (*types.QuietHours, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+	start, end, ok := strings.Cut(value, "-")
+	if !ok {
+		return nil, fmt.Errorf("invalid quiet hours %q (use HH:MM-HH:MM)", value)
+	}
+	quiet := &types.QuietHours{Start: strings.TrimSpace(start), End: strings.TrimSpace(end)}
+	if err := quiet.Validate(); err != nil {
+		return nil, err
+	}
+	return quiet, nil
+}
+
+// validatePort checks a TCP port number
+func validatePort(port string) error {
+	n, err := strconv.Atoi(port)
+	if err != nil || n < 1 || n > 65535 {
+		return fmt.Errorf("invalid port %q", port)
+	}
+	return nil
+}
+
+// validateURL checks a service base URL
+func validateURL(value string) error {
+	u, err := url.Parse(value)
+	if err != nil {
+		return err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid URL %q (use http(s)://host[:port])", redactURL(value))
+	}
+	return nil
+}
+
+// redactURL masks the password in a URL's user info
+func redactURL(value string) string {
+	u, err := url.Parse(value)
+	if err != nil || u.User == nil {
+		return value
+	}
+	if _, hasPassword := u.User.Password(); hasPassword {
+		u.User = url.UserPassword(u.User.Username(), "xxxxx")
+	}
+	return u.String()
+}
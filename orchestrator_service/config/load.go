@@ -0,0 +1,299 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ConfigFileEnv names the config file when -config isn't given
+const ConfigFileEnv = "ORCHESTRATOR_CONFIG"
+
+// setting binds one config field to its flag and environment variable
+type setting struct {
+	flag   string
+	env    string
+	usage  string
+	isBool bool
+	get    func(*Config) string
+	set    func(*Config, string) error
+}
+
+// settings lists every option that can be overridden by env or flag. Flags win
+// over env, env wins over the config file, the file wins over the defaults.
+var settings = []setting{
+	{
+		flag: "port", usage: "HTTP API port",
+		get: func(c *Config) string { return c.Port },
+		set: func(c *Config, v string) error { c.Port = v; return nil },
+	},
+	{
+		flag: "webhook-port", usage: "Webhook server port",
+		get: func(c *Config) string { return c.WebhookPort },
+		set: func(c *Config, v string) error { c.WebhookPort = v; return nil },
+	},
+	{
+		flag: "api-url", env: "API_URL", usage: "Ingestion service API URL",
+		get: func(c *Config) string { return c.IngestionURL },
+		set: func(c *Config, v string) error { c.IngestionURL = v; return nil },
+	},
+	{
+		flag: "generation-url", env: "GENERATION_SERVICE_URL", usage: "Generation service API URL",
+		get: func(c *Config) string { return c.GenerationURL },
+		set: func(c *Config, v string) error { c.GenerationURL = v; return nil },
+	},
+	{
+		flag: "runs-db", env: "RUNS_DB_PATH", usage: "Run history database file",
+		get: func(c *Config) string { return c.RunsDB },
+		set: func(c *Config, v string) error { c.RunsDB = v; return nil },
+	},
+	{
+		flag: "max-concurrent-runs", env: "MAX_CONCURRENT_RUNS", usage: "Maximum number of workflow runs in flight",
+		get: func(c *Config) string { return strconv.Itoa(c.MaxConcurrentRuns) },
+		set: func(c *Config, v string) error { return setInt(&c.MaxConcurrentRuns, v) },
+	},
+	{
+		flag: "ingestion-timeout", env: "INGESTION_TIMEOUT", usage: "Per-attempt timeout for ingestion service calls",
+		get: func(c *Config) string { return time.Duration(c.Ingestion.Timeout).String() },
+		set: func(c *Config, v string) error { return c.Ingestion.Timeout.UnmarshalText([]byte(v)) },
+	},
+	{
+		flag: "ingestion-fetch-timeout", env: "INGESTION_FETCH_TIMEOUT", usage: "How long a feed fetch, which extracts every article, may take",
+		get: func(c *Config) string { return time.Duration(c.Ingestion.FetchTimeout).String() },
+		set: func(c *Config, v string) error { return c.Ingestion.FetchTimeout.UnmarshalText([]byte(v)) },
+	},
+	{
+		flag: "ingestion-retries", env: "INGESTION_MAX_RETRIES", usage: "Times a failed idempotent ingestion call is retried",
+		get: func(c *Config) string { return strconv.Itoa(c.Ingestion.MaxRetries) },
+		set: func(c *Config, v string) error { return setInt(&c.Ingestion.MaxRetries, v) },
+	},
+	{
+		flag: "ingestion-retry-backoff", env: "INGESTION_RETRY_BACKOFF", usage: "Delay before the first ingestion retry (doubles each attempt, with jitter)",
+		get: func(c *Config) string { return time.Duration(c.Ingestion.RetryBackoff).String() },
+		set: func(c *Config, v string) error { return c.Ingestion.RetryBackoff.UnmarshalText([]byte(v)) },
+	},
+	{
+		flag: "ingestion-breaker-threshold", env: "INGESTION_BREAKER_THRESHOLD", usage: "Consecutive ingestion failures that open the circuit (0 disables)",
+		get: func(c *Config) string { return strconv.Itoa(c.Ingestion.BreakerThreshold) },
+		set: func(c *Config, v string) error { return setInt(&c.Ingestion.BreakerThreshold, v) },
+	},
+	{
+		flag: "ingestion-breaker-cooldown", env: "INGESTION_BREAKER_COOLDOWN", usage: "How long the ingestion circuit stays open before a probe call",
+		get: func(c *Config) string { return time.Duration(c.Ingestion.BreakerCooldown).String() },
+		set: func(c *Config, v string) error { return c.Ingestion.BreakerCooldown.UnmarshalText([]byte(v)) },
+	},
+	{
+		flag: "ingestion-mode", env: "INGESTION_MODE", usage: "How runs fetch and deduplicate: http, or events to use ingestion jobs and Kafka",
+		get: func(c *Config) string { return c.Ingestion.Mode },
+		set: func(c *Config, v string) error { c.Ingestion.Mode = v; return nil },
+	},
+	{
+		flag: "cron", usage: "Default cron schedule for feeds whose preset doesn't set one",
+		get: func(c *Config) string { return c.Schedule.Cron },
+		set: func(c *Config, v string) error { c.Schedule.Cron = v; return nil },
+	},
+	{
+		flag: "quiet-hours", env: "QUIET_HOURS", usage: "Daily HH:MM-HH:MM window in which scheduled runs are skipped",
+		get: func(c *Config) string { return c.Schedule.QuietHours },
+		set: func(c *Config, v string) error { c.Schedule.QuietHours = v; return nil },
+	},
+	{
+		flag: "schedule-jitter", env: "SCHEDULE_JITTER", usage: "Random delay of up to this long before each scheduled run",
+		get: func(c *Config) string { return time.Duration(c.Schedule.Jitter).String() },
+		set: func(c *Config, v string) error { return c.Schedule.Jitter.UnmarshalText([]byte(v)) },
+	},
+	{
+		flag: "generation-timeout", env: "GENERATION_TIMEOUT", usage: "How long a run waits for a generation result before retrying or failing",
+		get: func(c *Config) string { return time.Duration(c.Generation.Timeout).String() },
+		set: func(c *Config, v string) error { return c.Generation.Timeout.UnmarshalText([]byte(v)) },
+	},
+	{
+		flag: "generation-poll-interval", env: "GENERATION_POLL_INTERVAL", usage: "Poll the generation service status endpoint at this interval (0 disables)",
+		get: func(c *Config) string { return time.Duration(c.Generation.PollInterval).String() },
+		set: func(c *Config, v string) error { return c.Generation.PollInterval.UnmarshalText([]byte(v)) },
+	},
+	{
+		flag: "generation-retries", env: "GENERATION_MAX_RETRIES", usage: "Times a timed-out generation request is re-sent",
+		get: func(c *Config) string { return strconv.Itoa(c.Generation.MaxRetries) },
+		set: func(c *Config, v string) error { return setInt(&c.Generation.MaxRetries, v) },
+	},
+	{
+		flag: "generation-retry-backoff", env: "GENERATION_RETRY_BACKOFF", usage: "Delay before the first generation retry (doubles each attempt)",
+		get: func(c *Config) string { return time.Duration(c.Generation.RetryBackoff).String() },
+		set: func(c *Config, v string) error { return c.Generation.RetryBackoff.UnmarshalText([]byte(v)) },
+	},
+	{
+		flag: "max-videos", env: "MAX_VIDEOS_PER_RUN", usage: "Maximum stories sent to generation per run (0 = no limit)",
+		get: func(c *Config) string { return strconv.Itoa(c.Selection.MaxVideos) },
+		set: func(c *Config, v string) error { return setInt(&c.Selection.MaxVideos, v) },
+	},
+	{
+		flag: "rank-by", env: "GENERATION_RANK_BY", usage: "Story ranking for generation: recency, sources or priority",
+		get: func(c *Config) string { return c.Selection.RankBy },
+		set: func(c *Config, v string) error { c.Selection.RankBy = v; return nil },
+	},
+	{
+		flag: "require-approval", env: "REQUIRE_APPROVAL", usage: "Queue selected stories for editorial approval before generation", isBool: true,
+		get: func(c *Config) string { return strconv.FormatBool(c.Approval.Required) },
+		set: func(c *Config, v string) error {
+			required, err := strconv.ParseBool(v)
+			if err != nil {
+				return fmt.Errorf("invalid boolean %q", v)
+			}
+			c.Approval.Required = required
+			return nil
+		},
+	},
+	{
+		flag: "trusted-feeds", env: "TRUSTED_FEEDS", usage: "Comma-separated feed presets that skip approval",
+		get: func(c *Config) string { return strings.Join(c.Approval.TrustedFeeds, ",") },
+		set: func(c *Config, v string) error { c.Approval.TrustedFeeds = splitList(v); return nil },
+	},
+	{
+		flag: "approval-timeout", env: "APPROVAL_TIMEOUT", usage: "How long a candidate waits for a decision before expiring",
+		get: func(c *Config) string { return time.Duration(c.Approval.Timeout).String() },
+		set: func(c *Config, v string) error { return c.Approval.Timeout.UnmarshalText([]byte(v)) },
+	},
+	{
+		flag: "kafka-brokers", env: "KAFKA_BOOTSTRAP_SERVERS", usage: "Comma-separated Kafka bootstrap servers",
+		get: func(c *Config) string { return strings.Join(c.Kafka.Brokers, ",") },
+		set: func(c *Config, v string) error { c.Kafka.Brokers = splitList(v); return nil },
+	},
+	{
+		flag: "kafka-topic", env: "KAFKA_TOPIC_VIDEO_REQUESTS", usage: "Kafka topic carrying generation results",
+		get: func(c *Config) string { return c.Kafka.Topic },
+		set: func(c *Config, v string) error { c.Kafka.Topic = v; return nil },
+	},
+	{
+		flag: "kafka-group", env: "KAFKA_CONSUMER_GROUP_ID", usage: "Kafka consumer group ID",
+		get: func(c *Config) string { return c.Kafka.GroupID },
+		set: func(c *Config, v string) error { c.Kafka.GroupID = v; return nil },
+	},
+	{
+		flag: "kafka-results-topic", env: "KAFKA_TOPIC_VIDEO_RESULTS", usage: "Kafka topic carrying finished videos (empty ignores them)",
+		get: func(c *Config) string { return c.Kafka.ResultsTopic },
+		set: func(c *Config, v string) error { c.Kafka.ResultsTopic = v; return nil },
+	},
+}
+
+// settingValue adapts a setting to flag.Value
+type settingValue struct {
+	cfg *Config
+	s   setting
+}
+
+func (v settingValue) String() string {
+	if v.cfg == nil {
+		return ""
+	}
+	return v.s.get(v.cfg)
+}
+
+func (v settingValue) Set(value string) error { return v.s.set(v.cfg, value) }
+
+func (v settingValue) IsBoolFlag() bool { return v.s.isBool }
+
+// Load builds the configuration from defaults, the JSON config file (-config
+// or ORCHESTRATOR_CONFIG), environment variables and command-line flags, in
+// increasing order of precedence, and validates the result. It returns
+// flag.ErrHelp when -h is given.
+func Load(args []string) (*Config, error) {
+	cfg := Default()
+
+	fs := flag.NewFlagSet("orchestrator", flag.ContinueOnError)
+	configFile := fs.String("config", os.Getenv(ConfigFileEnv), "JSON config file (env: "+ConfigFileEnv+")")
+
+	// Flags are parsed into a scratch copy first: the file and env are applied
+	// before them, so only flags that were actually given are replayed below
+	scratch := Default()
+	for _, s := range settings {
+		usage := s.usage
+		if s.env != "" {
+			usage += " (env: " + s.env + ")"
+		}
+		fs.Var(settingValue{cfg: &scratch, s: s}, s.flag, usage)
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if *configFile != "" {
+		if err := loadFile(&cfg, *configFile); err != nil {
+			return nil, err
+		}
+		cfg.File = *configFile
+	}
+
+	var errs []error
+	for _, s := range settings {
+		if s.env == "" {
+			continue
+		}
+		if value := os.Getenv(s.env); value != "" {
+			if err := s.set(&cfg, value); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", s.env, err))
+			}
+		}
+	}
+
+	byFlag := make(map[string]setting, len(settings))
+	for _, s := range settings {
+		byFlag[s.flag] = s
+	}
+	fs.Visit(func(f *flag.Flag) {
+		if s, ok := byFlag[f.Name]; ok {
+			if err := s.set(&cfg, s.get(&scratch)); err != nil {
+				errs = append(errs, fmt.Errorf("-%s: %w", s.flag, err))
+			}
+		}
+	})
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	return &cfg, nil
+}
+
+// loadFile overlays a JSON config file; fields it omits keep their current values
+func loadFile(cfg *Config, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(cfg); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// setInt parses an integer setting
+func setInt(dst *int, value string) error {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("invalid integer %q", value)
+	}
+	*dst = n
+	return nil
+}
+
+// splitList splits a comma-separated list, dropping empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
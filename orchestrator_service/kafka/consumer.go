@@ -33,13 +33,16 @@ func NewConsumer(config ConsumerConfig) (*sharedKafka.Consumer, error) {
 			return true
 		},
 		Process: func(ctx context.Context, msg *types.WebhookPayload) error {
-			// Note: We process both "success" and "failure" statuses here
-			// to update the orchestrator state accordingly.
-			config.StateManager.SetWebhookPayload(msg)
-			log.Printf("State updated from Kafka message for UUID: %s (Status: %s)", msg.UUID, msg.Status)
+			// Note: We process both "success" and "failure" statuses here;
+			// failures move the owning run to the failed state.
+			outcome := config.StateManager.HandleGenerationResult(msg)
+			log.Printf("Kafka message for UUID: %s (Status: %s) %s", msg.UUID, msg.Status, outcome)
 			return nil
 		},
 		AlwaysMark: true, // Always mark messages, even validation failures
+		Type:       types.GenerationResultType,
+		Version:    types.GenerationResultVersion,
+		Upgrades:   map[int]sharedKafka.UpgradeFunc{0: types.UpgradeGenerationResultV0},
 	}
 
 	return sharedKafka.NewConsumer(sharedKafka.ConsumerConfig{
@@ -0,0 +1,101 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"orchestrator/types"
+
+	sharedKafka "brainbot/shared/kafka"
+)
+
+// IngestionEventHandler receives the events ingestion jobs publish
+type IngestionEventHandler interface {
+	HandleArticle(event *types.ArticleEvent)
+	HandleJob(job *types.FetchJob)
+}
+
+// IngestionConsumerConfig holds the settings for the ingestion event consumers
+type IngestionConsumerConfig struct {
+	Brokers []string
+	// GroupID is suffixed with each topic, so every topic is consumed by its own group
+	GroupID string
+	Handler IngestionEventHandler
+}
+
+// articleTopics are the ingestion topics carrying ArticleEvent messages
+var articleTopics = []string{types.TopicArticleFetched, types.TopicArticleNew, types.TopicArticleDuplicate}
+
+// NewIngestionConsumers creates a consumer for each article topic and one for
+// finished ingestion jobs. Already created consumers are closed if one fails.
+func NewIngestionConsumers(config IngestionConsumerConfig) ([]*sharedKafka.Consumer, error) {
+	var consumers []*sharedKafka.Consumer
+	add := func(topic string, handler sharedKafka.MessageHandler) error {
+		consumer, err := sharedKafka.NewConsumer(sharedKafka.ConsumerConfig{
+			Brokers: config.Brokers,
+			Topic:   topic,
+			GroupID: config.GroupID + "-" + topic,
+			Handler: handler,
+		})
+		if err != nil {
+			return fmt.Errorf("consumer for %s: %w", topic, err)
+		}
+		consumers = append(consumers, consumer)
+		return nil
+	}
+
+	articleHandler := &sharedKafka.TypedMessageHandler[types.ArticleEvent]{
+		Validate: func(event *types.ArticleEvent) bool {
+			if event.Version > types.ArticleEventVersion {
+				log.Printf("Article event %s has unsupported version %d, skipping", event.EventID, event.Version)
+				return false
+			}
+			if event.Article.ID == "" {
+				log.Printf("Article event %s missing article ID, skipping", event.EventID)
+				return false
+			}
+			return true
+		},
+		Process: func(ctx context.Context, event *types.ArticleEvent) error {
+			config.Handler.HandleArticle(event)
+			return nil
+		},
+		AlwaysMark: true,
+	}
+	jobHandler := &sharedKafka.TypedMessageHandler[types.FetchJob]{
+		Validate: func(job *types.FetchJob) bool {
+			if job.ID == "" {
+				log.Printf("Ingestion job event missing job ID, skipping")
+				return false
+			}
+			return true
+		},
+		Process: func(ctx context.Context, job *types.FetchJob) error {
+			log.Printf("Ingestion job %s finished (Status: %s)", job.ID, job.Status)
+			config.Handler.HandleJob(job)
+			return nil
+		},
+		AlwaysMark: true,
+	}
+
+	for _, topic := range articleTopics {
+		if err := add(topic, articleHandler); err != nil {
+			closeConsumers(consumers)
+			return nil, err
+		}
+	}
+	if err := add(types.TopicIngestionJobs, jobHandler); err != nil {
+		closeConsumers(consumers)
+		return nil, err
+	}
+	return consumers, nil
+}
+
+// closeConsumers closes consumers, logging failures
+func closeConsumers(consumers []*sharedKafka.Consumer) {
+	for _, consumer := range consumers {
+		if err := consumer.Close(); err != nil {
+			log.Printf("Kafka consumer close error: %v", err)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package kafka
+
+import (
+	"context"
+	"log"
+	"orchestrator/state"
+	"orchestrator/types"
+
+	sharedKafka "brainbot/shared/kafka"
+)
+
+// VideoResultConsumerConfig holds the settings for the video result consumer
+type VideoResultConsumerConfig struct {
+	Brokers []string
+	Topic   string
+	// GroupID is suffixed with the topic, so video results get their own group
+	GroupID      string
+	StateManager *state.Manager
+}
+
+// NewVideoResultConsumer creates a consumer that records the creation
+// service's finished videos in run history
+func NewVideoResultConsumer(config VideoResultConsumerConfig) (*sharedKafka.Consumer, error) {
+	handler := &sharedKafka.TypedMessageHandler[types.VideoResult]{
+		Validate: func(result *types.VideoResult) bool {
+			if result.UUID == "" {
+				log.Printf("Video result missing UUID, skipping")
+				return false
+			}
+			return true
+		},
+		Process: func(ctx context.Context, result *types.VideoResult) error {
+			outcome := config.StateManager.HandleVideoResult(result)
+			log.Printf("Video result for UUID: %s (Status: %s) %s", result.UUID, result.Status, outcome)
+			return nil
+		},
+		AlwaysMark: true,
+		Type:       types.VideoResultType,
+		Version:    types.VideoResultVersion,
+	}
+
+	return sharedKafka.NewConsumer(sharedKafka.ConsumerConfig{
+		Brokers: config.Brokers,
+		Topic:   config.Topic,
+		GroupID: config.GroupID + "-" + config.Topic,
+		Handler: handler,
+	})
+}
@@ -2,18 +2,23 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"orchestrator/api"
 	"orchestrator/client"
+	"orchestrator/config"
 	"orchestrator/kafka"
 	"orchestrator/state"
+	"orchestrator/store"
 	"orchestrator/workflow"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	sharedKafka "brainbot/shared/kafka"
+
 	"github.com/joho/godotenv"
 )
 
@@ -21,44 +26,49 @@ func main() {
 	// Load environment variables
 	_ = godotenv.Load()
 
-	// Parse command-line flags
-	port := flag.String("port", "8081", "HTTP API port")
-	webhookPort := flag.String("webhook-port", "9999", "Webhook server port")
-	cronSchedule := flag.String("cron", "*/5 * * * *", "Cron schedule for automated runs (default: every 5 minutes)")
-	apiURL := flag.String("api-url", "", "Ingestion service API URL (overrides API_URL env var)")
-	flag.Parse()
-
-	// Determine ingestion service URL
-	ingestionURL := *apiURL
-	if ingestionURL == "" {
-		ingestionURL = os.Getenv("API_URL")
-		if ingestionURL == "" {
-			ingestionURL = "http://ingestion-service:8080"
-		}
+	// Load configuration (defaults < config file < env < flags)
+	cfg, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		fmt.Printf("%v\n", err)
+		os.Exit(1)
 	}
+	fmt.Printf("Effective configuration:\n%s\n", cfg)
 
 	// Create ingestion service client
-	ingestionClient := client.NewIngestionClient(ingestionURL)
+	ingestionClient := client.NewIngestionClient(cfg.IngestionURL, cfg.RetryPolicy())
+
+	// Open run history store (history is kept in memory only if this fails)
+	runStore, err := store.Open(cfg.RunsDB)
+	if err != nil {
+		fmt.Printf("Failed to open run store, run history will not persist: %v\n", err)
+	}
 
 	// Create state manager
-	stateManager := state.NewManager(*webhookPort, ingestionClient)
+	stateManager := state.NewManager(cfg.WebhookPort, ingestionClient, runStore, cfg.MaxConcurrentRuns)
 
-	// Create workflow runner
-	workflowRunner := workflow.NewRunner(stateManager)
+	// Runs left in flight by a previous process will never receive their results
+	if recovered, err := stateManager.RecoverInterruptedRuns(); err != nil {
+		fmt.Printf("Failed to recover interrupted runs: %v\n", err)
+	} else if recovered > 0 {
+		fmt.Printf("Marked %d interrupted run(s) as failed\n", recovered)
+	}
 
-	// Kafka configuration
-	kafkaBrokers := []string{"kafka:9092"} // Default
-	if envBrokers := os.Getenv("KAFKA_BOOTSTRAP_SERVERS"); envBrokers != "" {
-		kafkaBrokers = []string{envBrokers}
+	// Create workflow runner
+	runnerConfig := cfg.RunnerConfig()
+	runnerConfig.GenerationClient = client.NewGenerationClient(cfg.GenerationURL)
+	if cfg.Ingestion.Mode == config.IngestionModeEvents {
+		runnerConfig.IngestionEvents = workflow.NewIngestionEvents()
 	}
-	kafkaTopic := "video-processing-requests"
-	kafkaGroupID := "orchestrator-consumer-group"
+	workflowRunner := workflow.NewRunner(stateManager, runnerConfig)
 
 	// Create Kafka consumer
 	consumerConfig := kafka.ConsumerConfig{
-		Brokers:      kafkaBrokers,
-		Topic:        kafkaTopic,
-		GroupID:      kafkaGroupID,
+		Brokers:      cfg.Kafka.Brokers,
+		Topic:        cfg.Kafka.Topic,
+		GroupID:      cfg.Kafka.GroupID,
 		StateManager: stateManager,
 	}
 
@@ -73,25 +83,58 @@ func main() {
 		}
 	}
 
+	// Record the videos the creation service finishes in run history
+	var videoConsumer *sharedKafka.Consumer
+	if cfg.Kafka.ResultsTopic != "" {
+		videoConsumer, err = kafka.NewVideoResultConsumer(kafka.VideoResultConsumerConfig{
+			Brokers:      cfg.Kafka.Brokers,
+			Topic:        cfg.Kafka.ResultsTopic,
+			GroupID:      cfg.Kafka.GroupID,
+			StateManager: stateManager,
+		})
+		if err != nil {
+			fmt.Printf("Failed to create video result consumer: %v\n", err)
+		} else if err := videoConsumer.Start(context.Background()); err != nil {
+			fmt.Printf("Failed to start video result consumer: %v\n", err)
+		}
+	}
+
+	// In events mode, collect ingestion job results from Kafka
+	var ingestionConsumers []*sharedKafka.Consumer
+	if runnerConfig.IngestionEvents != nil {
+		ingestionConsumers, err = kafka.NewIngestionConsumers(kafka.IngestionConsumerConfig{
+			Brokers: cfg.Kafka.Brokers,
+			GroupID: cfg.Kafka.GroupID,
+			Handler: runnerConfig.IngestionEvents,
+		})
+		if err != nil {
+			fmt.Printf("Failed to create ingestion event consumers: %v\n", err)
+			os.Exit(1)
+		}
+		for _, consumer := range ingestionConsumers {
+			if err := consumer.Start(context.Background()); err != nil {
+				fmt.Printf("Failed to start ingestion event consumer: %v\n", err)
+			}
+		}
+	}
+
 	// Create and start API server
-	apiServer := api.NewServer(stateManager, workflowRunner, *port)
+	apiServer := api.NewServer(cfg, stateManager, workflowRunner)
 
 	if err := apiServer.Start(); err != nil {
 		fmt.Printf("Failed to start server: %v\n", err)
 		os.Exit(1)
 	}
 
-	// Start cron job
-	if err := apiServer.StartCron(*cronSchedule); err != nil {
+	// Start per-feed cron jobs
+	if err := apiServer.StartCron(); err != nil {
 		fmt.Printf("Failed to start cron: %v\n", err)
 		os.Exit(1)
 	}
 
 	fmt.Printf("Orchestrator Service\n")
-	fmt.Printf("   API:            http://0.0.0.0:%s\n", *port)
-	fmt.Printf("   Webhook:        http://0.0.0.0:%s/webhook\n", *webhookPort)
-	fmt.Printf("   Cron Schedule:  %s\n", *cronSchedule)
-	fmt.Printf("   Ingestion API:  %s\n", ingestionURL)
+	fmt.Printf("   API:            http://0.0.0.0:%s\n", cfg.Port)
+	fmt.Printf("   Webhook:        http://0.0.0.0:%s/webhook\n", cfg.WebhookPort)
 	fmt.Println("\nPress Ctrl+C to shutdown")
 
 	// Handle graceful shutdown
@@ -114,5 +157,23 @@ func main() {
 		}
 	}
 
+	if videoConsumer != nil {
+		if err := videoConsumer.Close(); err != nil {
+			fmt.Printf("Kafka consumer close error: %v\n", err)
+		}
+	}
+
+	for _, consumer := range ingestionConsumers {
+		if err := consumer.Close(); err != nil {
+			fmt.Printf("Kafka consumer close error: %v\n", err)
+		}
+	}
+
+	if runStore != nil {
+		if err := runStore.Close(); err != nil {
+			fmt.Printf("Run store close error: %v\n", err)
+		}
+	}
+
 	fmt.Println("Server stopped")
 }
@@ -0,0 +1,93 @@
+package state
+
+import (
+	"orchestrator/types"
+	"sync"
+	"time"
+)
+
+const (
+	// maxBufferedEvents is how many past events a resuming client can replay
+	maxBufferedEvents = 1000
+	// subscriberBuffer is how far a subscriber may fall behind before it is dropped
+	subscriberBuffer = 256
+)
+
+// EventBus numbers orchestrator events, keeps the most recent ones for
+// resumption and fans them out to subscribers
+type EventBus struct {
+	mu          sync.Mutex
+	seq         uint64
+	buffer      []types.Event
+	subscribers map[chan types.Event]struct{}
+}
+
+// NewEventBus creates an empty event bus
+func NewEventBus() *EventBus {
+	return &EventBus{
+		subscribers: make(map[chan types.Event]struct{}),
+	}
+}
+
+// Publish assigns the next sequence number to an event and delivers it (thread-safe).
+// A subscriber that can't keep up is dropped; its channel is closed so it can
+// reconnect and resume from its last sequence number.
+func (b *EventBus) Publish(event types.Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.seq++
+	event.Seq = b.seq
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	b.buffer = append(b.buffer, event)
+	if len(b.buffer) > maxBufferedEvents {
+		b.buffer = b.buffer[len(b.buffer)-maxBufferedEvents:]
+	}
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+			delete(b.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// Subscribe returns a channel of new events. When resuming, replay holds the
+// buffered events after afterSeq; complete is false when some of those are no
+// longer buffered (or came from a previous orchestrator process), in which case
+// the client must resync its state instead. head is the latest sequence number
+// at subscription time. Call cancel when done (thread-safe).
+func (b *EventBus) Subscribe(afterSeq uint64, resume bool) (replay []types.Event, complete bool, head uint64, events <-chan types.Event, cancel func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	complete = resume && afterSeq <= b.seq
+	if complete && len(b.buffer) > 0 && afterSeq+1 < b.buffer[0].Seq {
+		complete = false
+	}
+	if complete {
+		for _, event := range b.buffer {
+			if event.Seq > afterSeq {
+				replay = append(replay, event)
+			}
+		}
+	}
+
+	ch := make(chan types.Event, subscriberBuffer)
+	b.subscribers[ch] = struct{}{}
+
+	cancel = func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subscribers[ch]; ok {
+			delete(b.subscribers, ch)
+			close(ch)
+		}
+	}
+	return replay, complete, b.seq, ch, cancel
+}
@@ -0,0 +1,148 @@
+package state
+
+import (
+	"errors"
+	"orchestrator/types"
+	"reflect"
+	"testing"
+)
+
+// newWaitingRun returns a run with one generation that was sent and then retried
+func newWaitingRun(t *testing.T) (*Manager, *Run) {
+	t.Helper()
+	m := NewManager("", nil, nil, 0)
+	run, err := m.StartRun(RunSpec{Feeds: []string{"tech"}})
+	if err != nil {
+		t.Fatalf("StartRun() = %v", err)
+	}
+	if err := run.AwaitGeneration(types.GenerationRecord{UUID: "first", Title: "Story"}, ""); err != nil {
+		t.Fatalf("AwaitGeneration(first) = %v", err)
+	}
+	if err := run.AwaitGeneration(types.GenerationRecord{UUID: "retry", Title: "Story"}, "first"); err != nil {
+		t.Fatalf("AwaitGeneration(retry) = %v", err)
+	}
+	return m, run
+}
+
+func TestLateResultOfEarlierAttemptIsAccepted(t *testing.T) {
+	m, run := newWaitingRun(t)
+	done := run.GenerationDone("retry")
+
+	if got := m.HandleGenerationResult(&types.WebhookPayload{UUID: "first", Status: "success"}); got != OutcomeApplied {
+		t.Fatalf("first attempt's result: %s, want %s", got, OutcomeApplied)
+	}
+	if got := m.HandleGenerationResult(&types.WebhookPayload{UUID: "retry", Status: "success"}); got != OutcomeDuplicate {
+		t.Errorf("retry's result: %s, want %s", got, OutcomeDuplicate)
+	}
+
+	select {
+	case <-done:
+	default:
+		t.Error("the retry's done channel wasn't closed by the earlier attempt's result")
+	}
+
+	record := run.Record()
+	if len(record.Generations) != 1 {
+		t.Fatalf("got %d generations, want 1", len(record.Generations))
+	}
+	g := record.Generations[0]
+	if g.UUID != "first" || !reflect.DeepEqual(g.PreviousUUIDs, []string{"retry"}) {
+		t.Errorf("generation UUID = %s, previous = %v; want the accepted attempt first", g.UUID, g.PreviousUUIDs)
+	}
+	if g.State != types.StateComplete || g.Attempts != 2 {
+		t.Errorf("generation state = %s, attempts = %d", g.State, g.Attempts)
+	}
+	if record.State != types.StateComplete {
+		t.Errorf("run state = %s, want complete", record.State)
+	}
+
+	// Only the accepted attempt's video is recorded
+	if run.ApplyVideoResult(&types.VideoResult{UUID: "retry", Status: types.VideoUploaded}) {
+		t.Error("video of the ignored attempt was applied")
+	}
+	if !run.ApplyVideoResult(&types.VideoResult{UUID: "first", Status: types.VideoUploaded}) {
+		t.Error("video of the accepted attempt wasn't applied")
+	}
+}
+
+func TestRetryAfterResultIsRefused(t *testing.T) {
+	m, run := newWaitingRun(t)
+	m.HandleGenerationResult(&types.WebhookPayload{UUID: "retry", Status: "success"})
+
+	err := run.AwaitGeneration(types.GenerationRecord{UUID: "third", Title: "Story"}, "retry")
+	if !errors.Is(err, ErrGenerationFinished) {
+		t.Fatalf("AwaitGeneration after the result = %v, want ErrGenerationFinished", err)
+	}
+	if g := run.Record().Generations[0]; g.UUID != "retry" || g.Attempts != 2 {
+		t.Errorf("finished generation was changed: %+v", g)
+	}
+}
+
+func TestFailedFirstSendDoesNotFinishTheRun(t *testing.T) {
+	m := NewManager("", nil, nil, 0)
+	run, err := m.StartRun(RunSpec{Feeds: []string{"tech"}})
+	if err != nil {
+		t.Fatalf("StartRun() = %v", err)
+	}
+
+	run.BeginSending()
+	if err := run.AwaitGeneration(types.GenerationRecord{UUID: "first", Title: "First"}, ""); err != nil {
+		t.Fatalf("AwaitGeneration(first) = %v", err)
+	}
+	run.FailGeneration("first", errors.New("generation service unavailable"))
+	if state := run.GetState(); state.IsTerminal() {
+		t.Fatalf("run finished mid-send: %s", state)
+	}
+	if err := run.AwaitGeneration(types.GenerationRecord{UUID: "second", Title: "Second"}, ""); err != nil {
+		t.Fatalf("AwaitGeneration(second) = %v", err)
+	}
+	run.EndSending()
+
+	select {
+	case <-run.Done():
+		t.Fatal("run released while a generation is still waiting")
+	default:
+	}
+	for _, transition := range run.Record().Transitions {
+		if transition.State.IsTerminal() {
+			t.Fatalf("run passed through %s before its last generation finished", transition.State)
+		}
+	}
+
+	run.FailGeneration("second", errors.New("generation service unavailable"))
+	if state := run.GetState(); state != types.StateFailed {
+		t.Errorf("run state = %s, want failed", state)
+	}
+	select {
+	case <-run.Done():
+	default:
+		t.Error("finished run wasn't released")
+	}
+	if err := run.AwaitGeneration(types.GenerationRecord{UUID: "third", Title: "Third"}, ""); !errors.Is(err, ErrRunFinished) {
+		t.Errorf("AwaitGeneration on a finished run = %v, want ErrRunFinished", err)
+	}
+}
+
+func TestEndSendingFinishesRunWhoseSendsAllFailed(t *testing.T) {
+	m := NewManager("", nil, nil, 0)
+	run, err := m.StartRun(RunSpec{Feeds: []string{"tech"}})
+	if err != nil {
+		t.Fatalf("StartRun() = %v", err)
+	}
+
+	run.BeginSending()
+	for _, uuid := range []string{"first", "second"} {
+		if err := run.AwaitGeneration(types.GenerationRecord{UUID: uuid, Title: uuid}, ""); err != nil {
+			t.Fatalf("AwaitGeneration(%s) = %v", uuid, err)
+		}
+		run.FailGeneration(uuid, errors.New("generation service unavailable"))
+	}
+	run.EndSending()
+
+	if state := run.GetState(); state != types.StateFailed {
+		t.Errorf("run state = %s, want failed", state)
+	}
+	if _, err := m.StartRun(RunSpec{Feeds: []string{"tech"}}); err != nil {
+		t.Errorf("feed still locked after the run finished: %v", err)
+	}
+}
@@ -0,0 +1,772 @@
+package state
+
+import (
+	"fmt"
+	"log"
+	"orchestrator/types"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Run is a single workflow run with its own state machine (thread-safe)
+type Run struct {
+	mu      sync.RWMutex
+	manager *Manager
+	done    chan struct{} // Closed once the run releases its slot
+
+	record          types.RunRecord
+	articles        []*types.Article
+	articleFeeds    map[string]string // article ID -> feed preset
+	dedupResults    []types.ArticleResult
+	generationsDone map[string]chan struct{} // generation UUID -> closed when it finishes
+	candidatesDone  map[string]chan struct{} // candidate ID -> closed when it is decided
+	unresolved      map[string]bool          // Queued candidates the workflow hasn't acted on yet
+	sending         int                      // Open BeginSending holds; the run can't finish while any remain
+	lastErr         error
+
+	// Logs (ring buffer)
+	logs    []types.LogEntry
+	maxLogs int
+}
+
+// newRun creates a run in the idle state
+func newRun(manager *Manager, id string, spec RunSpec, feeds []string) *Run {
+	now := time.Now()
+	return &Run{
+		manager: manager,
+		done:    make(chan struct{}),
+
+		generationsDone: make(map[string]chan struct{}),
+		candidatesDone:  make(map[string]chan struct{}),
+		unresolved:      make(map[string]bool),
+		record: types.RunRecord{
+			ID:        id,
+			Trigger:   spec.Trigger,
+			Feeds:     feeds,
+			Namespace: spec.Namespace,
+			DryRun:    spec.DryRun,
+			State:     types.StateIdle,
+			StartedAt: now,
+			UpdatedAt: now,
+		},
+		logs:    make([]types.LogEntry, 0),
+		maxLogs: manager.maxLogs,
+	}
+}
+
+// ID returns the run ID
+func (r *Run) ID() string {
+	return r.record.ID
+}
+
+// Namespace returns the dedup namespace of the run
+func (r *Run) Namespace() string {
+	return r.record.Namespace
+}
+
+// Feeds returns the feed presets locked by this run
+func (r *Run) Feeds() []string {
+	return append([]string{}, r.record.Feeds...)
+}
+
+// DryRun reports whether the run only previews what it would do
+func (r *Run) DryRun() bool {
+	return r.record.DryRun
+}
+
+// Done returns a channel that is closed when the run reaches a terminal state
+func (r *Run) Done() <-chan struct{} {
+	return r.done
+}
+
+// label identifies the run in the shared orchestrator log
+func (r *Run) label() string {
+	if len(r.record.Feeds) == 1 {
+		return r.record.Feeds[0]
+	}
+	return fmt.Sprintf("%d feeds", len(r.record.Feeds))
+}
+
+// AddLog adds a log entry to the run and the shared orchestrator log (thread-safe)
+func (r *Run) AddLog(message string) {
+	r.mu.Lock()
+	r.appendLog(message)
+	r.mu.Unlock()
+
+	r.manager.addLog(r.ID(), fmt.Sprintf("[%s] %s", r.label(), message))
+}
+
+// SetState sets the run state, releasing its feeds once the state is terminal (thread-safe)
+func (r *Run) SetState(state types.State) {
+	r.mu.Lock()
+	r.transition(state)
+	r.persist()
+	r.mu.Unlock()
+
+	if state.IsTerminal() {
+		r.manager.release(r)
+	}
+}
+
+// GetState gets the run state (thread-safe)
+func (r *Run) GetState() types.State {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.record.State
+}
+
+// SetError moves the run to the error state (thread-safe)
+func (r *Run) SetError(err error) {
+	r.finishWithError(types.StateError, err)
+}
+
+// SetFailed moves the run to the failed state, e.g. when generation gives up (thread-safe)
+func (r *Run) SetFailed(err error) {
+	r.finishWithError(types.StateFailed, err)
+}
+
+// finishWithError records err and moves the run to a terminal state
+func (r *Run) finishWithError(state types.State, err error) {
+	message := fmt.Sprintf("Error: %v", err)
+
+	r.mu.Lock()
+	if r.record.State.IsTerminal() {
+		r.mu.Unlock()
+		return
+	}
+	r.lastErr = err
+	r.record.Error = err.Error()
+	r.appendLog(message)
+	r.transition(state)
+	r.persist()
+	r.mu.Unlock()
+
+	r.manager.addLog(r.ID(), fmt.Sprintf("[%s] %s", r.label(), message))
+	r.manager.release(r)
+}
+
+// SetArticles sets the articles list, the feed each article came from and the
+// feeds that couldn't be fetched (thread-safe)
+func (r *Run) SetArticles(articles []*types.Article, articleFeeds map[string]string, skippedFeeds []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.articles = articles
+	r.articleFeeds = articleFeeds
+	r.record.ArticleCount = len(articles)
+	r.record.SkippedFeeds = skippedFeeds
+	r.persist()
+}
+
+// ArticleFeed returns the feed preset an article was fetched from (thread-safe)
+func (r *Run) ArticleFeed(articleID string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.articleFeeds[articleID]
+}
+
+// GetArticles gets the articles list (thread-safe)
+func (r *Run) GetArticles() []*types.Article {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.articles
+}
+
+// SetDedupResults sets the deduplication results (thread-safe)
+func (r *Run) SetDedupResults(results []types.ArticleResult) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.dedupResults = results
+	r.record.Articles = toRunArticleResults(results, r.articleFeeds)
+	r.record.NewCount, r.record.DuplicateCount = countDedupResults(results)
+	r.persist()
+
+	for i := range r.record.Articles {
+		article := r.record.Articles[i]
+		r.manager.events.Publish(types.Event{Type: types.EventArticle, RunID: r.record.ID, Article: &article})
+	}
+}
+
+// GetDedupResults gets the deduplication results (thread-safe)
+func (r *Run) GetDedupResults() []types.ArticleResult {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.dedupResults
+}
+
+// SetPreview records the stories a dry run would have sent to generation (thread-safe)
+func (r *Run) SetPreview(preview []types.Candidate) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.record.Preview = preview
+	r.persist()
+}
+
+// AwaitGeneration registers a generation request and moves the run to the waiting state.
+// It is called before the request is sent so an early result always finds its run.
+// A retry passes the UUID it replaces. The earlier attempt stays registered, so a
+// late result for it still completes the generation; whichever attempt reports
+// first is accepted and the others are ignored as duplicates. Retrying a
+// generation that has finished in the meantime returns ErrGenerationFinished, and
+// registering one on a finished run returns ErrRunFinished (thread-safe).
+func (r *Run) AwaitGeneration(generation types.GenerationRecord, previousUUID string) error {
+	now := time.Now()
+
+	r.mu.Lock()
+	if previousUUID != "" {
+		if i := r.findGeneration(previousUUID); i >= 0 && r.record.Generations[i].State != types.StateWaiting {
+			r.mu.Unlock()
+			return ErrGenerationFinished
+		}
+	}
+	if r.record.State.IsTerminal() {
+		r.mu.Unlock()
+		return ErrRunFinished
+	}
+	done := r.generationsDone[previousUUID]
+	if done == nil {
+		done = make(chan struct{})
+	}
+	r.generationsDone[generation.UUID] = done
+
+	if i := r.findGeneration(previousUUID); previousUUID != "" && i >= 0 {
+		g := &r.record.Generations[i]
+		g.PreviousUUIDs = append(g.PreviousUUIDs, g.UUID)
+		g.UUID = generation.UUID
+		g.Attempts++
+		g.SentAt = now
+	} else {
+		generation.State = types.StateWaiting
+		generation.Attempts = 1
+		generation.SentAt = now
+		r.record.Generations = append(r.record.Generations, generation)
+	}
+	r.record.GenerationUUID = generation.UUID
+	if r.record.State != types.StateWaiting && len(r.unresolved) == 0 {
+		r.transition(types.StateWaiting)
+	}
+	r.persist()
+	r.mu.Unlock()
+
+	r.manager.indexGeneration(generation.UUID, r)
+	return nil
+}
+
+// GetGenerationUUID gets the most recently sent generation UUID (thread-safe)
+func (r *Run) GetGenerationUUID() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.record.GenerationUUID
+}
+
+// GenerationUUIDs returns the UUIDs of every generation attempt in the run (thread-safe)
+func (r *Run) GenerationUUIDs() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	uuids := make([]string, 0, len(r.record.Generations))
+	for _, g := range r.record.Generations {
+		uuids = append(uuids, g.UUID)
+		uuids = append(uuids, g.PreviousUUIDs...)
+	}
+	return uuids
+}
+
+// GenerationDone returns a channel that is closed when the generation finishes.
+// Unknown UUIDs get an already-closed channel (thread-safe).
+func (r *Run) GenerationDone(uuid string) <-chan struct{} {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if done, ok := r.generationsDone[uuid]; ok {
+		return done
+	}
+	closed := make(chan struct{})
+	close(closed)
+	return closed
+}
+
+// ApplyGenerationResult stores a generation result, marking that generation complete,
+// or failed when the generation service reported an error. The run finishes once every
+// generation has. It returns false without changing anything if the generation already
+// finished, so redelivered results and results for other attempts of the same
+// generation are harmless (thread-safe).
+func (r *Run) ApplyGenerationResult(payload *types.WebhookPayload) bool {
+	r.mu.Lock()
+	i := r.findGeneration(payload.UUID)
+	if i < 0 || r.record.Generations[i].State != types.StateWaiting || r.record.State.IsTerminal() {
+		r.mu.Unlock()
+		return false
+	}
+
+	generation := &r.record.Generations[i]
+	if generation.UUID != payload.UUID {
+		// An earlier attempt answered first: it becomes the generation's UUID,
+		// so its video is the one recorded
+		r.appendLog(fmt.Sprintf("Accepted result of earlier attempt %s for %q", payload.UUID, generation.Title))
+		superseded := generation.UUID
+		for j, previous := range generation.PreviousUUIDs {
+			if previous == payload.UUID {
+				generation.PreviousUUIDs[j] = superseded
+			}
+		}
+		generation.UUID = payload.UUID
+		if r.record.GenerationUUID == superseded {
+			r.record.GenerationUUID = payload.UUID
+		}
+	}
+	generation.WebhookPayload = payload
+	r.record.WebhookPayload = payload
+
+	var message string
+	if payload.Failed() {
+		reason := payload.FailureReason()
+		message = fmt.Sprintf("Generation failed for %q: %s", generation.Title, reason)
+		r.finishGeneration(i, types.StateFailed, reason)
+	} else {
+		message = fmt.Sprintf("Webhook received from generation service for %q!", generation.Title)
+		r.finishGeneration(i, types.StateComplete, "")
+	}
+	r.appendLog(message)
+	finished := r.finishIfDone()
+	r.persist()
+	r.mu.Unlock()
+
+	r.manager.addLog(r.ID(), fmt.Sprintf("[%s] %s", r.label(), message))
+	if finished {
+		r.manager.release(r)
+	}
+	return true
+}
+
+// ApplyVideoResult attaches the creation service's outcome to a generation,
+// replacing any earlier one. Videos arrive after their generation finished, so
+// this also applies to finished runs. It returns false for unknown UUIDs and for
+// attempts whose generation result wasn't the one accepted (thread-safe).
+func (r *Run) ApplyVideoResult(result *types.VideoResult) bool {
+	r.mu.Lock()
+	i := r.findGeneration(result.UUID)
+	if i < 0 {
+		r.mu.Unlock()
+		return false
+	}
+	if r.record.Generations[i].UUID != result.UUID {
+		title := r.record.Generations[i].Title
+		r.mu.Unlock()
+		log.Printf("Ignored video %s for %q: another attempt's result was accepted", result.UUID, title)
+		return false
+	}
+
+	generation := &r.record.Generations[i]
+	generation.Video = result
+	message := videoResultMessage(generation.Title, result)
+	updated := generation.Clone()
+	r.appendLog(message)
+	r.persist()
+	r.mu.Unlock()
+
+	r.manager.events.Publish(types.Event{Type: types.EventGeneration, RunID: r.ID(), Time: time.Now(), Generation: &updated})
+	r.manager.addLog(r.ID(), fmt.Sprintf("[%s] %s", r.label(), message))
+	return true
+}
+
+// FailGeneration marks a single generation failed, e.g. when it can't be sent or
+// times out; the run finishes once every generation has (thread-safe)
+func (r *Run) FailGeneration(uuid string, err error) {
+	message := fmt.Sprintf("Error: %v", err)
+
+	r.mu.Lock()
+	i := r.findGeneration(uuid)
+	if i < 0 || r.record.Generations[i].State != types.StateWaiting || r.record.State.IsTerminal() {
+		r.mu.Unlock()
+		return
+	}
+	r.finishGeneration(i, types.StateFailed, err.Error())
+	r.appendLog(message)
+	finished := r.finishIfDone()
+	r.persist()
+	r.mu.Unlock()
+
+	r.manager.addLog(r.ID(), fmt.Sprintf("[%s] %s", r.label(), message))
+	if finished {
+		r.manager.release(r)
+	}
+}
+
+// BeginSending holds the run open while the workflow sends a batch of generations,
+// so a generation that fails before the rest are sent can't finish it (thread-safe)
+func (r *Run) BeginSending() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sending++
+}
+
+// EndSending releases a BeginSending hold; the run finishes if every generation
+// it sent has already finished (thread-safe)
+func (r *Run) EndSending() {
+	r.mu.Lock()
+	if r.sending == 0 {
+		r.mu.Unlock()
+		return
+	}
+	r.sending--
+
+	finished := false
+	if r.sending == 0 && len(r.record.Generations) > 0 && !r.record.State.IsTerminal() {
+		finished = r.finishIfDone()
+		r.persist()
+	}
+	r.mu.Unlock()
+
+	if finished {
+		r.manager.release(r)
+	}
+}
+
+// QueueCandidates records the run's generation candidates. Pending candidates move
+// the run to the awaiting approval state until each is resolved (thread-safe).
+func (r *Run) QueueCandidates(candidates []types.Candidate) {
+	pending := 0
+
+	r.mu.Lock()
+	for _, c := range candidates {
+		r.record.Candidates = append(r.record.Candidates, c)
+		if !c.Status.IsDecided() {
+			r.candidatesDone[c.ID] = make(chan struct{})
+			r.unresolved[c.ID] = true
+			pending++
+		}
+	}
+	if pending > 0 {
+		r.transition(types.StateAwaitingApproval)
+	}
+	r.persist()
+	r.mu.Unlock()
+
+	if pending > 0 {
+		r.AddLog(fmt.Sprintf("%d candidate(s) awaiting approval", pending))
+	}
+}
+
+// DecideCandidate records an editorial decision on a pending candidate (thread-safe)
+func (r *Run) DecideCandidate(id string, status types.CandidateStatus, reason string) (types.Candidate, error) {
+	r.mu.Lock()
+	i := r.findCandidate(id)
+	if i < 0 {
+		r.mu.Unlock()
+		return types.Candidate{}, fmt.Errorf("%w: %s", ErrCandidateNotFound, id)
+	}
+	candidate := &r.record.Candidates[i]
+	if candidate.Status.IsDecided() || r.record.State.IsTerminal() {
+		current := *candidate
+		r.mu.Unlock()
+		return current, fmt.Errorf("%w: %s is %s", ErrCandidateDecided, id, current.Status)
+	}
+
+	now := time.Now()
+	candidate.Status = status
+	candidate.Reason = reason
+	candidate.DecidedAt = &now
+	if done, ok := r.candidatesDone[id]; ok {
+		close(done)
+		delete(r.candidatesDone, id)
+	}
+	decided := *candidate
+	r.persist()
+	r.mu.Unlock()
+
+	message := fmt.Sprintf("Candidate %q %s", decided.Title, status)
+	if reason != "" {
+		message += ": " + reason
+	}
+	r.AddLog(message)
+	return decided, nil
+}
+
+// CandidateDone returns a channel that is closed when the candidate is decided.
+// Unknown or already decided candidates get an already-closed channel (thread-safe).
+func (r *Run) CandidateDone(id string) <-chan struct{} {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if done, ok := r.candidatesDone[id]; ok {
+		return done
+	}
+	closed := make(chan struct{})
+	close(closed)
+	return closed
+}
+
+// Candidate returns a candidate of the run by ID (thread-safe)
+func (r *Run) Candidate(id string) (types.Candidate, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if i := r.findCandidate(id); i >= 0 {
+		return r.record.Candidates[i].Clone(), true
+	}
+	return types.Candidate{}, false
+}
+
+// Candidates returns a copy of the run's candidates (thread-safe)
+func (r *Run) Candidates() []types.Candidate {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return types.CloneCandidates(r.record.Candidates)
+}
+
+// ResolveCandidate tells the run the workflow has acted on a decided candidate
+// (sent its generation or dropped it); the run finishes once nothing is outstanding.
+// Resolving a candidate more than once is a no-op (thread-safe).
+func (r *Run) ResolveCandidate(id string) {
+	r.mu.Lock()
+	if !r.unresolved[id] || r.record.State.IsTerminal() {
+		r.mu.Unlock()
+		return
+	}
+	delete(r.unresolved, id)
+
+	finished := false
+	if len(r.unresolved) == 0 {
+		if r.hasWaitingGeneration() {
+			r.transition(types.StateWaiting)
+		} else {
+			finished = r.finishIfDone()
+		}
+	}
+	r.persist()
+	r.mu.Unlock()
+
+	if finished {
+		r.manager.release(r)
+	}
+}
+
+// findCandidate returns the index of a candidate by ID, or -1 (must hold lock)
+func (r *Run) findCandidate(id string) int {
+	for i, c := range r.record.Candidates {
+		if c.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// hasWaitingGeneration reports whether any generation is still in flight (must hold lock)
+func (r *Run) hasWaitingGeneration() bool {
+	for _, g := range r.record.Generations {
+		if g.State == types.StateWaiting {
+			return true
+		}
+	}
+	return false
+}
+
+// findGeneration returns the index of the generation any of whose attempts has
+// the UUID, or -1 (must hold lock)
+func (r *Run) findGeneration(uuid string) int {
+	if uuid == "" {
+		return -1
+	}
+	for i, g := range r.record.Generations {
+		if g.UUID == uuid || slices.Contains(g.PreviousUUIDs, uuid) {
+			return i
+		}
+	}
+	return -1
+}
+
+// finishGeneration moves a generation to a terminal state (must hold lock)
+func (r *Run) finishGeneration(i int, state types.State, reason string) {
+	now := time.Now()
+	generation := &r.record.Generations[i]
+	generation.State = state
+	generation.Error = reason
+	generation.FinishedAt = &now
+
+	finished := generation.Clone()
+	r.manager.events.Publish(types.Event{Type: types.EventGeneration, RunID: r.record.ID, Time: now, Generation: &finished})
+
+	// Every attempt shares one channel
+	if done, ok := r.generationsDone[generation.UUID]; ok {
+		close(done)
+	}
+	delete(r.generationsDone, generation.UUID)
+	for _, previous := range generation.PreviousUUIDs {
+		delete(r.generationsDone, previous)
+	}
+}
+
+// finishIfDone completes the run once no candidate, send or generation is outstanding:
+// failed if every generation failed, complete otherwise (must hold lock)
+func (r *Run) finishIfDone() bool {
+	if len(r.unresolved) > 0 || r.sending > 0 {
+		return false
+	}
+
+	succeeded, failed := 0, 0
+	var firstErr string
+	for _, g := range r.record.Generations {
+		switch g.State {
+		case types.StateWaiting:
+			return false
+		case types.StateComplete:
+			succeeded++
+		default:
+			failed++
+			if firstErr == "" {
+				firstErr = g.Error
+			}
+		}
+	}
+
+	if succeeded == 0 && failed > 0 {
+		r.lastErr = fmt.Errorf("all %d generation(s) failed: %s", failed, firstErr)
+		r.record.Error = r.lastErr.Error()
+		r.transition(types.StateFailed)
+		return true
+	}
+	if failed > 0 {
+		r.appendLog(fmt.Sprintf("%d of %d generation(s) failed", failed, failed+succeeded))
+	}
+	r.transition(types.StateComplete)
+	return true
+}
+
+// Record returns a deep copy of the run record, safe to read while the run
+// keeps changing (thread-safe)
+func (r *Run) Record() types.RunRecord {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.record.Clone()
+}
+
+// Status returns a snapshot of the run (thread-safe)
+func (r *Run) Status() types.StatusResponse {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	resp := types.StatusResponse{
+		State:          r.record.State,
+		RunID:          r.record.ID,
+		Namespace:      r.record.Namespace,
+		Feeds:          append([]string{}, r.record.Feeds...),
+		DryRun:         r.record.DryRun,
+		Logs:           append([]types.LogEntry{}, r.logs...), // Copy slice
+		ArticleCount:   len(r.articles),
+		SkippedFeeds:   slices.Clone(r.record.SkippedFeeds),
+		NewCount:       r.record.NewCount,
+		DuplicateCount: r.record.DuplicateCount,
+		GenerationUUID: r.record.GenerationUUID,
+		WebhookPayload: r.record.WebhookPayload,
+		// Deep copies, so the response can be encoded while the run keeps changing
+		Generations: append([]types.GenerationRecord{}, types.CloneGenerations(r.record.Generations)...),
+		Candidates:  append([]types.Candidate{}, types.CloneCandidates(r.record.Candidates)...),
+		Preview:     append([]types.Candidate{}, types.CloneCandidates(r.record.Preview)...),
+	}
+
+	if r.lastErr != nil {
+		resp.Error = r.lastErr.Error()
+	}
+
+	return resp
+}
+
+// appendLog adds a log entry to the ring buffer (must hold lock)
+func (r *Run) appendLog(message string) {
+	entry := types.LogEntry{
+		Timestamp: time.Now(),
+		Message:   message,
+	}
+	r.logs = append(r.logs, entry)
+	if len(r.logs) > r.maxLogs {
+		r.logs = r.logs[len(r.logs)-r.maxLogs:]
+	}
+}
+
+// transition moves to a new state and records it (must hold lock)
+func (r *Run) transition(state types.State) {
+	now := time.Now()
+	r.record.State = state
+	r.record.Transitions = append(r.record.Transitions, types.StateTransition{State: state, At: now})
+	if state.IsTerminal() {
+		r.record.FinishedAt = &now
+	}
+
+	event := types.Event{Type: types.EventState, RunID: r.record.ID, Time: now, State: state}
+	if r.lastErr != nil {
+		event.Error = r.lastErr.Error()
+	}
+	r.manager.events.Publish(event)
+}
+
+// persist writes the run record to the store (must hold lock)
+func (r *Run) persist() {
+	r.record.UpdatedAt = time.Now()
+
+	if r.manager.store == nil {
+		return
+	}
+	if err := r.manager.store.Save(&r.record); err != nil {
+		log.Printf("Warning: failed to persist run %s: %v", r.record.ID, err)
+	}
+}
+
+// countDedupResults counts new and duplicate articles
+func countDedupResults(results []types.ArticleResult) (newCount, dupCount int) {
+	for _, r := range results {
+		switch r.Status {
+		case "new":
+			newCount++
+		case "duplicate":
+			dupCount++
+		}
+	}
+	return
+}
+
+// toRunArticleResults converts dedup results into their run history form
+func toRunArticleResults(results []types.ArticleResult, articleFeeds map[string]string) []types.RunArticleResult {
+	out := make([]types.RunArticleResult, 0, len(results))
+	for _, r := range results {
+		item := types.RunArticleResult{
+			Status: r.Status,
+			Error:  r.Error,
+		}
+		if r.Article != nil {
+			item.ArticleID = r.Article.ID
+			item.Title = r.Article.Title
+			item.URL = r.Article.URL
+			item.Feed = articleFeeds[r.Article.ID]
+		}
+		if r.DeduplicationResult != nil {
+			item.IsExactDuplicate = r.DeduplicationResult.IsExactDuplicate
+			item.MatchingID = r.DeduplicationResult.MatchingID
+			item.SimilarityScore = r.DeduplicationResult.SimilarityScore
+		}
+		out = append(out, item)
+	}
+	return out
+}
+
+// videoResultMessage describes a video result for the run log
+func videoResultMessage(title string, result *types.VideoResult) string {
+	switch result.Status {
+	case types.VideoUploaded:
+		return fmt.Sprintf("Video uploaded for %q: %s", title, result.URL)
+	case types.VideoRendered:
+		return fmt.Sprintf("Video rendered for %q (upload disabled): %s", title, result.OutputPath)
+	default:
+		return fmt.Sprintf("Video creation failed for %q: %s", title, result.Error)
+	}
+}
+
+// namespaceKey normalizes a namespace so "" and "default" share locks
+func namespaceKey(namespace string) string {
+	namespace = strings.ToLower(strings.TrimSpace(namespace))
+	if namespace == "" {
+		return "default"
+	}
+	return namespace
+}
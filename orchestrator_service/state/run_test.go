@@ -0,0 +1,53 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"orchestrator/types"
+	"sync"
+	"testing"
+)
+
+// Run with -race: snapshots must not share memory with the live run
+func TestSnapshotsAreSafeToReadWhileRunChanges(t *testing.T) {
+	m := NewManager("", nil, nil, 0)
+	run, err := m.StartRun(RunSpec{Feeds: []string{"tech"}})
+	if err != nil {
+		t.Fatalf("StartRun() = %v", err)
+	}
+	run.QueueCandidates([]types.Candidate{{ID: "c1", Status: types.CandidatePending}})
+	for i := 0; i < 10; i++ {
+		uuid := fmt.Sprintf("gen-%d", i)
+		if err := run.AwaitGeneration(types.GenerationRecord{UUID: uuid, Title: uuid}, ""); err != nil {
+			t.Fatalf("AwaitGeneration(%s) = %v", uuid, err)
+		}
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 10; i++ {
+			uuid := fmt.Sprintf("gen-%d", i)
+			if err := run.AwaitGeneration(types.GenerationRecord{UUID: uuid + "-retry", Title: uuid}, uuid); err != nil {
+				t.Errorf("AwaitGeneration(%s-retry) = %v", uuid, err)
+			}
+			run.ApplyGenerationResult(&types.WebhookPayload{UUID: uuid, Status: "success"})
+			run.ApplyVideoResult(&types.VideoResult{UUID: uuid, Status: types.VideoUploaded})
+		}
+		run.DecideCandidate("c1", types.CandidateApproved, "looks good")
+	}()
+
+	for i := 0; i < 50; i++ {
+		if _, err := json.Marshal(run.Record()); err != nil {
+			t.Fatalf("marshal record: %v", err)
+		}
+		if _, err := json.Marshal(run.Status()); err != nil {
+			t.Fatalf("marshal status: %v", err)
+		}
+		for _, c := range run.Candidates() {
+			_ = c.DecidedAt
+		}
+	}
+	wg.Wait()
+}
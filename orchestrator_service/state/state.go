@@ -1,154 +1,565 @@
 package state
 
 import (
+	"errors"
 	"fmt"
+	"log"
 	"orchestrator/client"
+	"orchestrator/store"
 	"orchestrator/types"
+	"sort"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrConcurrencyLimit is returned when the maximum number of runs are already in flight
+	ErrConcurrencyLimit = errors.New("concurrent run limit reached")
+	// ErrFeedBusy is returned when the requested feeds are being processed by another run
+	ErrFeedBusy = errors.New("feed already being processed")
+	// ErrNamespaceBusy is returned when a cache-clearing run would race with other runs in its namespace
+	ErrNamespaceBusy = errors.New("namespace has runs in progress")
+	// ErrRunNotFound is returned when a run ID is unknown
+	ErrRunNotFound = store.ErrNotFound
+	// ErrCandidateNotFound is returned when a candidate ID is unknown
+	ErrCandidateNotFound = errors.New("candidate not found")
+	// ErrCandidateDecided is returned when a candidate was already approved, rejected or expired
+	ErrCandidateDecided = errors.New("candidate already decided")
+	// ErrGenerationFinished is returned when retrying a generation that has already finished
+	ErrGenerationFinished = errors.New("generation already finished")
+	// ErrRunFinished is returned when sending a generation for a run that has already finished
+	ErrRunFinished = errors.New("run already finished")
+)
+
+// GenerationOutcome describes what happened to a generation result
+type GenerationOutcome string
+
+const (
+	// OutcomeApplied means the result finished its run
+	OutcomeApplied GenerationOutcome = "applied"
+	// OutcomeDuplicate means the run had already finished; the result was ignored
+	OutcomeDuplicate GenerationOutcome = "duplicate"
+	// OutcomeParked means no run owns the UUID yet; the result is held until one does
+	OutcomeParked GenerationOutcome = "parked"
+	// OutcomeUnknown means no run, in memory or in history, sent the UUID
+	OutcomeUnknown GenerationOutcome = "unknown"
 )
 
-// Manager holds the complete orchestrator state with thread-safe access
+// DefaultMaxConcurrentRuns is used when no concurrency limit is configured
+const DefaultMaxConcurrentRuns = 3
+
+// maxRetainedRuns is how many finished runs are kept in memory for status lookups
+const maxRetainedRuns = 20
+
+const (
+	// maxParkedResults bounds how many uncorrelated generation results are held
+	maxParkedResults = 100
+	// parkedResultTTL is how long an uncorrelated generation result is held
+	parkedResultTTL = 15 * time.Minute
+)
+
+// parkedResult is a generation result that arrived before (or without) its run
+type parkedResult struct {
+	payload  *types.WebhookPayload
+	parkedAt time.Time
+}
+
+// RunSpec describes a run to start
+type RunSpec struct {
+	Trigger    types.RunTrigger
+	Namespace  string
+	Feeds      []string
+	Exclusive  bool // Fail if any feed is busy instead of skipping busy feeds
+	ClearCache bool // Run clears the namespace's dedup state, so it needs the namespace to itself
+	DryRun     bool // Run only reads dedup state, so it takes no feed locks
+}
+
+// Manager tracks concurrent workflow runs with thread-safe access
 type Manager struct {
 	mu sync.RWMutex
 
-	// Current state
-	currentState types.State
+	// Runs (active and recently finished), in start order
+	runs  map[string]*Run
+	order []string
 
-	// Data
-	articles       []*types.Article
-	dedupResults   []types.ArticleResult
-	generationUUID string
+	// Generation UUID -> run ID, and results whose run is not known (yet)
+	generations map[string]string
+	parked      map[string]parkedResult
 
-	// Logs (ring buffer)
+	// Scheduling
+	maxConcurrent   int
+	active          map[string]bool   // run IDs holding a slot
+	feedLocks       map[string]string // namespace/feed -> run ID
+	namespaceRuns   map[string]int    // namespace -> active run count
+	namespaceOwners map[string]string // namespace -> run ID clearing it
+
+	// Logs (ring buffer shared by all runs)
 	logs    []types.LogEntry
 	maxLogs int
-	lastErr error
+
+	// Event stream for SSE subscribers
+	events *EventBus
 
 	// Webhook
-	webhookPayload *types.WebhookPayload
-	webhookPort    string
+	webhookPort string
+
+	// Run history (store is optional; nil keeps history in memory only)
+	store *store.RunStore
 
 	// Dependencies
 	ingestionClient *client.IngestionClient
 }
 
 // NewManager creates a new state manager
-func NewManager(webhookPort string, ingestionClient *client.IngestionClient) *Manager {
+func NewManager(webhookPort string, ingestionClient *client.IngestionClient, runStore *store.RunStore, maxConcurrent int) *Manager {
+	if maxConcurrent <= 0 {
+		maxConcurrent = DefaultMaxConcurrentRuns
+	}
+
 	return &Manager{
-		currentState:    types.StateIdle,
+		runs:            make(map[string]*Run),
+		generations:     make(map[string]string),
+		parked:          make(map[string]parkedResult),
+		maxConcurrent:   maxConcurrent,
+		active:          make(map[string]bool),
+		feedLocks:       make(map[string]string),
+		namespaceRuns:   make(map[string]int),
+		namespaceOwners: make(map[string]string),
 		ingestionClient: ingestionClient,
 		webhookPort:     webhookPort,
+		store:           runStore,
 		logs:            make([]types.LogEntry, 0),
 		maxLogs:         50, // Keep last 50 log entries
+		events:          NewEventBus(),
 	}
 }
 
-// AddLog adds a log entry (thread-safe)
-func (m *Manager) AddLog(message string) {
+// StartRun reserves a concurrency slot and feed locks for a new run (thread-safe).
+// Non-exclusive runs take only the feeds that are free; the returned run's
+// Feeds() lists what it actually locked.
+func (m *Manager) StartRun(spec RunSpec) (*Run, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	entry := types.LogEntry{
-		Timestamp: time.Now(),
-		Message:   message,
+	if len(m.active) >= m.maxConcurrent {
+		return nil, fmt.Errorf("%w (%d)", ErrConcurrencyLimit, m.maxConcurrent)
 	}
 
-	m.logs = append(m.logs, entry)
-	if len(m.logs) > m.maxLogs {
-		m.logs = m.logs[len(m.logs)-m.maxLogs:]
+	ns := namespaceKey(spec.Namespace)
+	if owner := m.namespaceOwners[ns]; owner != "" {
+		return nil, fmt.Errorf("%w: %s is being cleared by run %s", ErrNamespaceBusy, ns, owner)
+	}
+	if spec.ClearCache && m.namespaceRuns[ns] > 0 {
+		return nil, fmt.Errorf("%w: %s has %d active run(s)", ErrNamespaceBusy, ns, m.namespaceRuns[ns])
 	}
-}
 
-// GetStatus returns a snapshot of the current state (thread-safe)
-func (m *Manager) GetStatus() types.StatusResponse {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+	if spec.DryRun {
+		return m.startDryRun(spec), nil
+	}
 
-	newCount, dupCount := m.countDedupResults()
+	var free, busy []string
+	for _, feed := range spec.Feeds {
+		if _, locked := m.feedLocks[feedLockKey(ns, feed)]; locked {
+			busy = append(busy, feed)
+		} else {
+			free = append(free, feed)
+		}
+	}
+	if len(free) == 0 || (spec.Exclusive && len(busy) > 0) {
+		return nil, fmt.Errorf("%w: %s", ErrFeedBusy, strings.Join(busy, ", "))
+	}
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		id = uuid.New()
+	}
+	run := newRun(m, id.String(), spec, free)
 
-	resp := types.StatusResponse{
-		State:          m.currentState,
-		Logs:           append([]types.LogEntry{}, m.logs...), // Copy slice
-		ArticleCount:   len(m.articles),
-		NewCount:       newCount,
-		DuplicateCount: dupCount,
-		GenerationUUID: m.generationUUID,
-		WebhookPayload: m.webhookPayload,
+	m.runs[run.ID()] = run
+	m.order = append(m.order, run.ID())
+	m.active[run.ID()] = true
+	for _, feed := range free {
+		m.feedLocks[feedLockKey(ns, feed)] = run.ID()
 	}
+	m.namespaceRuns[ns]++
+	if spec.ClearCache {
+		m.namespaceOwners[ns] = run.ID()
+	}
+
+	run.mu.Lock()
+	run.persist()
+	run.mu.Unlock()
+
+	if len(busy) > 0 {
+		log.Printf("Run %s skipping busy feeds: %s", run.ID(), strings.Join(busy, ", "))
+	}
+	return run, nil
+}
 
-	if m.lastErr != nil {
-		resp.Error = m.lastErr.Error()
+// startDryRun registers a run that only reads dedup state. It counts towards the
+// concurrency limit but doesn't block other runs of its feeds or namespace (must hold lock)
+func (m *Manager) startDryRun(spec RunSpec) *Run {
+	id, err := uuid.NewV7()
+	if err != nil {
+		id = uuid.New()
 	}
+	run := newRun(m, id.String(), spec, spec.Feeds)
 
-	return resp
+	m.runs[run.ID()] = run
+	m.order = append(m.order, run.ID())
+	m.active[run.ID()] = true
+
+	run.mu.Lock()
+	run.persist()
+	run.mu.Unlock()
+	return run
 }
 
-// SetState sets the current state (thread-safe)
-func (m *Manager) SetState(state types.State) {
+// release frees the slot and locks held by a run; safe to call more than once (thread-safe)
+func (m *Manager) release(run *Run) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	m.currentState = state
+
+	id := run.ID()
+	if !m.active[id] {
+		return
+	}
+	delete(m.active, id)
+	close(run.done)
+	if run.DryRun() {
+		m.pruneRuns()
+		return
+	}
+
+	ns := namespaceKey(run.Namespace())
+	for _, feed := range run.Feeds() {
+		key := feedLockKey(ns, feed)
+		if m.feedLocks[key] == id {
+			delete(m.feedLocks, key)
+		}
+	}
+	if m.namespaceRuns[ns]--; m.namespaceRuns[ns] <= 0 {
+		delete(m.namespaceRuns, ns)
+	}
+	if m.namespaceOwners[ns] == id {
+		delete(m.namespaceOwners, ns)
+	}
+
+	m.pruneRuns()
 }
 
-// GetState gets the current state (thread-safe)
-func (m *Manager) GetState() types.State {
+// pruneRuns drops the oldest finished runs beyond maxRetainedRuns (must hold lock)
+func (m *Manager) pruneRuns() {
+	finished := len(m.order) - len(m.active)
+	kept := m.order[:0]
+	for _, id := range m.order {
+		if finished > maxRetainedRuns && !m.active[id] {
+			for _, uuid := range m.runs[id].GenerationUUIDs() {
+				delete(m.generations, uuid)
+			}
+			delete(m.runs, id)
+			finished--
+			continue
+		}
+		kept = append(kept, id)
+	}
+	m.order = kept
+}
+
+// indexGeneration records which run owns a generation UUID and applies any result
+// that arrived before the run registered it. UUIDs of earlier attempts stay
+// indexed until the run is pruned, so their late results still find it (thread-safe).
+func (m *Manager) indexGeneration(generationUUID string, run *Run) {
+	m.mu.Lock()
+	m.generations[generationUUID] = run.ID()
+	parked, ok := m.parked[generationUUID]
+	delete(m.parked, generationUUID)
+	m.mu.Unlock()
+
+	if ok {
+		log.Printf("Applying parked generation result for UUID %s to run %s", generationUUID, run.ID())
+		run.ApplyGenerationResult(parked.payload)
+	}
+}
+
+// FindRunByGenerationUUID returns the run that sent a generation request (thread-safe)
+func (m *Manager) FindRunByGenerationUUID(generationUUID string) *Run {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
-	return m.currentState
+	return m.runs[m.generations[generationUUID]]
 }
 
-// SetError sets the error state
-func (m *Manager) SetError(err error) {
+// HandleGenerationResult routes a generation result to the run that requested it (thread-safe).
+// Results for unknown UUIDs are parked for a while in case their run registers late;
+// results for runs that already finished are ignored.
+func (m *Manager) HandleGenerationResult(payload *types.WebhookPayload) GenerationOutcome {
 	m.mu.Lock()
-	defer m.mu.Unlock()
-	m.currentState = types.StateError
-	m.lastErr = err
+	run := m.runs[m.generations[payload.UUID]]
+	if run == nil {
+		m.parkResult(payload)
+		m.mu.Unlock()
+		log.Printf("Parked generation result for unknown UUID %s (status=%s)", payload.UUID, payload.Status)
+		return OutcomeParked
+	}
+	m.mu.Unlock()
 
-	// Add log entry (must be done inside lock since we're already holding it)
-	entry := types.LogEntry{
-		Timestamp: time.Now(),
-		Message:   fmt.Sprintf("Error: %v", err),
+	if !run.ApplyGenerationResult(payload) {
+		log.Printf("Ignored generation result for UUID %s: run %s already %s", payload.UUID, run.ID(), run.GetState())
+		return OutcomeDuplicate
 	}
-	m.logs = append(m.logs, entry)
-	if len(m.logs) > m.maxLogs {
-		m.logs = m.logs[len(m.logs)-m.maxLogs:]
+	return OutcomeApplied
+}
+
+// HandleVideoResult records a finished video on the generation it was rendered
+// from. Runs that are no longer in memory are updated in run history (thread-safe).
+func (m *Manager) HandleVideoResult(result *types.VideoResult) GenerationOutcome {
+	if run := m.FindRunByGenerationUUID(result.UUID); run != nil && run.ApplyVideoResult(result) {
+		return OutcomeApplied
+	}
+	if m.store == nil {
+		return OutcomeUnknown
+	}
+
+	record, err := m.store.FindByGeneration(result.UUID)
+	if err != nil {
+		if !errors.Is(err, store.ErrNotFound) {
+			log.Printf("Failed to look up run for video %s: %v", result.UUID, err)
+		}
+		return OutcomeUnknown
+	}
+	for i := range record.Generations {
+		if record.Generations[i].UUID == result.UUID {
+			record.Generations[i].Video = result
+			m.addLog(record.ID, videoResultMessage(record.Generations[i].Title, result))
+		}
 	}
+	record.UpdatedAt = time.Now()
+	if err := m.store.Save(record); err != nil {
+		log.Printf("Failed to record video %s on run %s: %v", result.UUID, record.ID, err)
+		return OutcomeUnknown
+	}
+	return OutcomeApplied
 }
 
-// SetArticles sets the articles list (thread-safe)
-func (m *Manager) SetArticles(articles []*types.Article) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-	m.articles = articles
+// parkResult holds an uncorrelated result, evicting expired and excess entries (must hold lock)
+func (m *Manager) parkResult(payload *types.WebhookPayload) {
+	now := time.Now()
+	oldestUUID := ""
+	var oldest time.Time
+	for id, p := range m.parked {
+		if now.Sub(p.parkedAt) > parkedResultTTL {
+			delete(m.parked, id)
+			continue
+		}
+		if oldestUUID == "" || p.parkedAt.Before(oldest) {
+			oldestUUID, oldest = id, p.parkedAt
+		}
+	}
+	if _, exists := m.parked[payload.UUID]; !exists && len(m.parked) >= maxParkedResults {
+		delete(m.parked, oldestUUID)
+	}
+	if _, exists := m.parked[payload.UUID]; !exists {
+		m.parked[payload.UUID] = parkedResult{payload: payload, parkedAt: now}
+	}
+}
+
+// RecoverInterruptedRuns marks runs that were in flight when the orchestrator
+// last stopped as failed, so history doesn't show them as running forever.
+func (m *Manager) RecoverInterruptedRuns() (int, error) {
+	if m.store == nil {
+		return 0, nil
+	}
+
+	records, err := m.store.List(0)
+	if err != nil {
+		return 0, err
+	}
+
+	recovered := 0
+	for i := range records {
+		record := &records[i]
+		if record.State.IsTerminal() {
+			continue
+		}
+
+		now := time.Now()
+		record.Error = fmt.Sprintf("interrupted by orchestrator restart while %s", record.State)
+		record.State = types.StateFailed
+		record.Transitions = append(record.Transitions, types.StateTransition{State: types.StateFailed, At: now})
+		record.UpdatedAt = now
+		record.FinishedAt = &now
+		if err := m.store.Save(record); err != nil {
+			return recovered, err
+		}
+		recovered++
+	}
+	return recovered, nil
 }
 
-// GetArticles gets the articles list (thread-safe)
-func (m *Manager) GetArticles() []*types.Article {
+// ListCandidates returns candidates of in-memory runs, oldest first. An empty
+// status returns every candidate (thread-safe).
+func (m *Manager) ListCandidates(status types.CandidateStatus) []types.Candidate {
+	m.mu.RLock()
+	runs := make([]*Run, 0, len(m.order))
+	for _, id := range m.order {
+		runs = append(runs, m.runs[id])
+	}
+	m.mu.RUnlock()
+
+	candidates := make([]types.Candidate, 0)
+	for _, run := range runs {
+		for _, c := range run.Candidates() {
+			if status == "" || c.Status == status {
+				candidates = append(candidates, c)
+			}
+		}
+	}
+	return candidates
+}
+
+// DecideCandidate approves or rejects a pending candidate (thread-safe)
+func (m *Manager) DecideCandidate(id string, approve bool, reason string) (types.Candidate, error) {
+	status := types.CandidateRejected
+	if approve {
+		status = types.CandidateApproved
+	}
+
+	for _, run := range m.ActiveRuns() {
+		if _, ok := run.Candidate(id); ok {
+			return run.DecideCandidate(id, status, reason)
+		}
+	}
+
+	// Finished runs still know their candidates, but they can no longer be decided
+	m.mu.RLock()
+	runs := make([]*Run, 0, len(m.runs))
+	for _, run := range m.runs {
+		runs = append(runs, run)
+	}
+	m.mu.RUnlock()
+	for _, run := range runs {
+		if c, ok := run.Candidate(id); ok {
+			return c, fmt.Errorf("%w: run %s already %s", ErrCandidateDecided, run.ID(), run.GetState())
+		}
+	}
+
+	return types.Candidate{}, fmt.Errorf("%w: %s", ErrCandidateNotFound, id)
+}
+
+// GetRunHandle returns an in-memory run by ID, or nil (thread-safe)
+func (m *Manager) GetRunHandle(id string) *Run {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
-	return m.articles
+	return m.runs[id]
 }
 
-// SetDedupResults sets the deduplication results (thread-safe)
-func (m *Manager) SetDedupResults(results []types.ArticleResult) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-	m.dedupResults = results
+// ActiveRuns returns the runs currently holding a slot, oldest first (thread-safe)
+func (m *Manager) ActiveRuns() []*Run {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	runs := make([]*Run, 0, len(m.active))
+	for _, id := range m.order {
+		if m.active[id] {
+			runs = append(runs, m.runs[id])
+		}
+	}
+	return runs
 }
 
-// GetDedupResults gets the deduplication results (thread-safe)
-func (m *Manager) GetDedupResults() []types.ArticleResult {
+// latestRun returns the most recently started run, or nil (thread-safe)
+func (m *Manager) latestRun() *Run {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
-	return m.dedupResults
+	if len(m.order) == 0 {
+		return nil
+	}
+	return m.runs[m.order[len(m.order)-1]]
 }
 
-// SetGenerationUUID sets the generation UUID (thread-safe)
-func (m *Manager) SetGenerationUUID(uuid string) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-	m.generationUUID = uuid
+// GetStatus returns a snapshot of a run; an empty ID selects the latest run (thread-safe)
+func (m *Manager) GetStatus(runID string) (types.StatusResponse, error) {
+	var status types.StatusResponse
+
+	if runID == "" {
+		if run := m.latestRun(); run != nil {
+			status = run.Status()
+		} else {
+			status.State = types.StateIdle
+		}
+		// The latest-run view shows the shared log so interleaved runs stay visible
+		m.mu.RLock()
+		status.Logs = append([]types.LogEntry{}, m.logs...) // Copy slice
+		m.mu.RUnlock()
+	} else {
+		run := m.GetRunHandle(runID)
+		if run == nil {
+			return status, fmt.Errorf("%w: %s", ErrRunNotFound, runID)
+		}
+		status = run.Status()
+	}
+
+	for _, run := range m.ActiveRuns() {
+		status.ActiveRuns = append(status.ActiveRuns, run.Record().Summary())
+	}
+
+	return status, nil
+}
+
+// GetRun returns a run, preferring the in-memory copy over the store (thread-safe)
+func (m *Manager) GetRun(id string) (*types.RunRecord, error) {
+	if run := m.GetRunHandle(id); run != nil {
+		record := run.Record()
+		return &record, nil
+	}
+
+	if m.store == nil {
+		return nil, ErrRunNotFound
+	}
+	return m.store.Get(id)
+}
+
+// ListRuns returns up to limit run summaries, newest first (thread-safe)
+func (m *Manager) ListRuns(limit int) ([]types.RunRecord, error) {
+	var records []types.RunRecord
+	if m.store != nil {
+		var err error
+		records, err = m.store.List(limit)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		m.mu.RLock()
+		runs := make([]*Run, 0, len(m.order))
+		for _, id := range m.order {
+			runs = append(runs, m.runs[id])
+		}
+		m.mu.RUnlock()
+
+		for _, run := range runs {
+			records = append(records, run.Record())
+		}
+		sort.Slice(records, func(i, j int) bool {
+			return records[i].StartedAt.After(records[j].StartedAt)
+		})
+		if limit > 0 && len(records) > limit {
+			records = records[:limit]
+		}
+	}
+
+	summaries := make([]types.RunRecord, 0, len(records))
+	for _, record := range records {
+		summaries = append(summaries, record.Summary())
+	}
+	return summaries, nil
+}
+
+// AddLog adds an orchestrator-level log entry (thread-safe)
+func (m *Manager) AddLog(message string) {
+	m.addLog("", message)
 }
 
 // GetWebhookPort gets the webhook port (thread-safe)
@@ -158,38 +569,34 @@ func (m *Manager) GetWebhookPort() string {
 	return m.webhookPort
 }
 
-// SetWebhookPayload sets the webhook payload and transitions to complete state
-func (m *Manager) SetWebhookPayload(payload *types.WebhookPayload) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
-	m.webhookPayload = payload
-	m.currentState = types.StateComplete
+// GetIngestionClient returns the ingestion client
+func (m *Manager) GetIngestionClient() *client.IngestionClient {
+	return m.ingestionClient
+}
 
+// addLog adds a log entry to the shared ring buffer and publishes it (thread-safe)
+func (m *Manager) addLog(runID, message string) {
 	entry := types.LogEntry{
 		Timestamp: time.Now(),
-		Message:   "Webhook received from generation service!",
+		Message:   message,
 	}
+
+	m.mu.Lock()
 	m.logs = append(m.logs, entry)
 	if len(m.logs) > m.maxLogs {
 		m.logs = m.logs[len(m.logs)-m.maxLogs:]
 	}
+	m.mu.Unlock()
+
+	m.events.Publish(types.Event{Type: types.EventLog, RunID: runID, Time: entry.Timestamp, Log: &entry})
 }
 
-// GetIngestionClient returns the ingestion client
-func (m *Manager) GetIngestionClient() *client.IngestionClient {
-	return m.ingestionClient
+// Events returns the orchestrator event stream
+func (m *Manager) Events() *EventBus {
+	return m.events
 }
 
-// countDedupResults counts new and duplicate articles (must hold lock)
-func (m *Manager) countDedupResults() (newCount, dupCount int) {
-	for _, r := range m.dedupResults {
-		switch r.Status {
-		case "new":
-			newCount++
-		case "duplicate":
-			dupCount++
-		}
-	}
-	return
+// feedLockKey scopes a feed lock to its dedup namespace
+func feedLockKey(namespace, feed string) string {
+	return namespace + "/" + feed
 }
@@ -0,0 +1,124 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"orchestrator/types"
+	"os"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// ErrNotFound is returned when a run ID is not in the store
+var ErrNotFound = errors.New("run not found")
+
+var runsBucket = []byte("runs")
+
+// RunStore persists workflow run history in a BoltDB file.
+// Run IDs are time-ordered (UUIDv7), so key order is start order.
+type RunStore struct {
+	db *bolt.DB
+}
+
+// Open opens (or creates) the run store at path
+func Open(path string) (*RunStore, error) {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("create run store directory: %w", err)
+		}
+	}
+
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("open run store: %w", err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(runsBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("initialize run store: %w", err)
+	}
+
+	return &RunStore{db: db}, nil
+}
+
+// Save inserts or replaces a run record
+func (s *RunStore) Save(record *types.RunRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("marshal run %s: %w", record.ID, err)
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(runsBucket).Put([]byte(record.ID), data)
+	})
+}
+
+// Get returns the run with the given ID
+func (s *RunStore) Get(id string) (*types.RunRecord, error) {
+	var record types.RunRecord
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(runsBucket).Get([]byte(id))
+		if data == nil {
+			return ErrNotFound
+		}
+		return json.Unmarshal(data, &record)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// List returns up to limit runs, newest first
+func (s *RunStore) List(limit int) ([]types.RunRecord, error) {
+	records := make([]types.RunRecord, 0)
+	err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(runsBucket).Cursor()
+		for k, v := c.Last(); k != nil && (limit <= 0 || len(records) < limit); k, v = c.Prev() {
+			var record types.RunRecord
+			if err := json.Unmarshal(v, &record); err != nil {
+				return fmt.Errorf("decode run %s: %w", string(k), err)
+			}
+			records = append(records, record)
+		}
+		return nil
+	})
+	return records, err
+}
+
+// FindByGeneration returns the newest run that sent the given generation UUID
+func (s *RunStore) FindByGeneration(uuid string) (*types.RunRecord, error) {
+	var found *types.RunRecord
+	err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(runsBucket).Cursor()
+		for k, v := c.Last(); k != nil; k, v = c.Prev() {
+			var record types.RunRecord
+			if err := json.Unmarshal(v, &record); err != nil {
+				return fmt.Errorf("decode run %s: %w", string(k), err)
+			}
+			for _, g := range record.Generations {
+				if g.UUID == uuid {
+					found = &record
+					return nil
+				}
+			}
+		}
+		return ErrNotFound
+	})
+	if err != nil {
+		return nil, err
+	}
+	return found, nil
+}
+
+// Close closes the underlying database file
+func (s *RunStore) Close() error {
+	return s.db.Close()
+}
@@ -7,3 +7,19 @@ import (
 // Article represents a single article with metadata and extracted content
 // This is imported from the ingestion service types
 type Article = ingestionTypes.Article
+
+// FetchJob is an asynchronous feed fetch on the ingestion service
+type FetchJob = ingestionTypes.FetchJob
+
+// JobStatus is the lifecycle state of an ingestion job
+type JobStatus = ingestionTypes.JobStatus
+
+const (
+	JobQueued    = ingestionTypes.JobQueued
+	JobRunning   = ingestionTypes.JobRunning
+	JobSucceeded = ingestionTypes.JobSucceeded
+	JobFailed    = ingestionTypes.JobFailed
+)
+
+// FetchJobRequest is the body for submitting a fetch job
+type FetchJobRequest = ingestionTypes.FetchJobRequest
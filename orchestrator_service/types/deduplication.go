@@ -9,3 +9,6 @@ type ArticleResult = ingestionTypes.ArticleResult
 
 // DeduplicationResult contains the result of deduplication check
 type DeduplicationResult = ingestionTypes.DeduplicationResult
+
+// NamespaceHeader selects the deduplication namespace on ingestion API requests
+const NamespaceHeader = ingestionTypes.NamespaceHeader
@@ -8,14 +8,16 @@ import (
 type State = types.State
 
 const (
-	StateIdle          = types.StateIdle
-	StateClearing      = types.StateClearing
-	StateFetching      = types.StateFetching
-	StateDeduplicating = types.StateDeduplicating
-	StateSending       = types.StateSending
-	StateWaiting       = types.StateWaiting
-	StateComplete      = types.StateComplete
-	StateError         = types.StateError
+	StateIdle             = types.StateIdle
+	StateClearing         = types.StateClearing
+	StateFetching         = types.StateFetching
+	StateDeduplicating    = types.StateDeduplicating
+	StateAwaitingApproval = types.StateAwaitingApproval
+	StateSending          = types.StateSending
+	StateWaiting          = types.StateWaiting
+	StateComplete         = types.StateComplete
+	StateFailed           = types.StateFailed
+	StateError            = types.StateError
 )
 
 // LogEntry represents a single log line with timestamp
@@ -24,5 +26,140 @@ type LogEntry = types.LogEntry
 // WebhookPayload represents the generation service response
 type WebhookPayload = types.WebhookPayload
 
+// SubtitleTimestamp is a subtitle word or phrase and when it is spoken
+type SubtitleTimestamp = types.SubtitleTimestamp
+
+// Envelope type and schema version of generation results on Kafka
+const (
+	GenerationResultType    = types.GenerationResultType
+	GenerationResultVersion = types.GenerationResultVersion
+)
+
+// UpgradeGenerationResultV0 converts an unversioned generation result to version 1
+var UpgradeGenerationResultV0 = types.UpgradeGenerationResultV0
+
+// GenerationStatusSuccess is the status the generation service reports for a usable result
+const GenerationStatusSuccess = types.GenerationStatusSuccess
+
+// VideoResult is the creation service's outcome for one generation
+type VideoResult = types.VideoResult
+
+// VideoStatus is how a video's creation ended
+type VideoStatus = types.VideoStatus
+
+const (
+	VideoUploaded = types.VideoUploaded
+	VideoRendered = types.VideoRendered
+	VideoFailed   = types.VideoFailed
+)
+
+// Envelope type and schema version of video results on Kafka
+const (
+	VideoResultType    = types.VideoResultType
+	VideoResultVersion = types.VideoResultVersion
+)
+
+// TopicVideoResults is where the creation service announces finished videos
+const TopicVideoResults = types.TopicVideoResults
+
 // StatusResponse is the JSON response for GET /api/status
 type StatusResponse = types.StatusResponse
+
+// RunTrigger identifies what started a workflow run
+type RunTrigger = types.RunTrigger
+
+const (
+	TriggerCron    = types.TriggerCron
+	TriggerManual  = types.TriggerManual
+	TriggerRefresh = types.TriggerRefresh
+)
+
+// RunRecord is a single workflow run as persisted in run history
+type RunRecord = types.RunRecord
+
+// RunArticleResult is the per-article outcome kept in run history
+type RunArticleResult = types.RunArticleResult
+
+// StateTransition records when a run entered a state
+type StateTransition = types.StateTransition
+
+// GenerationRecord tracks one generation request within a run
+type GenerationRecord = types.GenerationRecord
+
+// Candidate is a new story waiting for editorial approval
+type Candidate = types.Candidate
+
+// CandidateStatus is the editorial decision on a candidate
+type CandidateStatus = types.CandidateStatus
+
+const (
+	CandidatePending      = types.CandidatePending
+	CandidateApproved     = types.CandidateApproved
+	CandidateAutoApproved = types.CandidateAutoApproved
+	CandidateRejected     = types.CandidateRejected
+	CandidateExpired      = types.CandidateExpired
+)
+
+// CloneCandidates and CloneGenerations deep-copy run data for snapshots
+var (
+	CloneCandidates  = types.CloneCandidates
+	CloneGenerations = types.CloneGenerations
+)
+
+// CandidateDecision is the body for approve/reject requests
+type CandidateDecision = types.CandidateDecision
+
+// CandidatesResponse is the JSON response for GET /api/candidates
+type CandidatesResponse = types.CandidatesResponse
+
+// FeedSchedule controls when the orchestrator starts runs for one feed
+type FeedSchedule = types.FeedSchedule
+
+// QuietHours is a daily window in which scheduled runs are skipped
+type QuietHours = types.QuietHours
+
+// SchedulesResponse is the JSON body of GET and PUT /api/schedules
+type SchedulesResponse = types.SchedulesResponse
+
+// Event is a single message on the orchestrator event stream
+type Event = types.Event
+
+// EventType identifies what an orchestrator event carries
+type EventType = types.EventType
+
+const (
+	EventState      = types.EventState
+	EventLog        = types.EventLog
+	EventArticle    = types.EventArticle
+	EventGeneration = types.EventGeneration
+	EventResync     = types.EventResync
+)
+
+// RequestIDHeader carries a request ID between services for log correlation
+const RequestIDHeader = types.RequestIDHeader
+
+// ArticleEvent is a message on one of the ingestion article topics
+type ArticleEvent = types.ArticleEvent
+
+// ArticlePayload is the article carried by an ArticleEvent
+type ArticlePayload = types.ArticlePayload
+
+// ArticleEventType identifies an article event
+type ArticleEventType = types.ArticleEventType
+
+const (
+	ArticleFetched   = types.ArticleFetched
+	ArticleNew       = types.ArticleNew
+	ArticleDuplicate = types.ArticleDuplicate
+)
+
+// ArticleEventVersion is the newest article event schema the orchestrator understands
+const ArticleEventVersion = types.ArticleEventVersion
+
+// Topics the ingestion service publishes article and job events to
+const (
+	TopicArticleFetched   = types.TopicArticleFetched
+	TopicArticleNew       = types.TopicArticleNew
+	TopicArticleDuplicate = types.TopicArticleDuplicate
+	TopicIngestionJobs    = types.TopicIngestionJobs
+)
@@ -0,0 +1,128 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"orchestrator/state"
+	"orchestrator/types"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// maxExcerptLength bounds the excerpt shown to reviewers
+const maxExcerptLength = 280
+
+// ApprovalPolicy controls the optional editorial approval step before generation
+type ApprovalPolicy struct {
+	// Required queues selected stories as candidates instead of sending them straight away
+	Required bool
+	// TrustedFeeds are auto-approved even when approval is required
+	TrustedFeeds []string
+	// Timeout expires undecided candidates so the run can finish
+	Timeout time.Duration
+}
+
+// DefaultApprovalPolicy sends everything without review; undecided candidates expire after 2 hours
+func DefaultApprovalPolicy() ApprovalPolicy {
+	return ApprovalPolicy{
+		Timeout: 2 * time.Hour,
+	}
+}
+
+// autoApproval reports whether a story from feed skips review, and why
+func (p ApprovalPolicy) autoApproval(feed string) (string, bool) {
+	if !p.Required {
+		return "approval not required", true
+	}
+	for _, trusted := range p.TrustedFeeds {
+		if strings.EqualFold(trusted, feed) {
+			return fmt.Sprintf("trusted feed %s", feed), true
+		}
+	}
+	return "", false
+}
+
+// queuedCandidate is a candidate waiting for an editorial decision
+type queuedCandidate struct {
+	id        string
+	candidate *candidate
+}
+
+// newCandidate describes a selected story for reviewers. reason says why an
+// auto-approved candidate skipped review.
+func newCandidate(run *state.Run, c *candidate, status types.CandidateStatus, reason string) types.Candidate {
+	article := c.result.Article
+
+	excerpt := article.Excerpt
+	if excerpt == "" {
+		excerpt = article.Summary
+	}
+	if len(excerpt) > maxExcerptLength {
+		excerpt = strings.TrimSpace(excerpt[:maxExcerptLength]) + "…"
+	}
+
+	candidate := types.Candidate{
+		ID:          uuid.New().String(),
+		RunID:       run.ID(),
+		ArticleID:   article.ID,
+		Title:       article.Title,
+		URL:         article.URL,
+		Excerpt:     excerpt,
+		Feed:        c.feed,
+		SourceCount: len(c.articleURLs),
+		Explanation: explainCandidate(c),
+		Status:      status,
+		CreatedAt:   time.Now(),
+	}
+	if status == types.CandidateAutoApproved {
+		now := time.Now()
+		candidate.Reason = reason
+		candidate.DecidedAt = &now
+	}
+	return candidate
+}
+
+// explainCandidate says why deduplication let the story through
+func explainCandidate(c *candidate) string {
+	explanation := "No exact or similar article in the dedup index"
+	if others := len(c.articleURLs) - 1; others > 0 {
+		explanation += fmt.Sprintf("; %d other article(s) in this run matched it", others)
+	}
+	return explanation
+}
+
+// awaitApproval waits for a reviewer's decision on a candidate, then sends the
+// story if it was approved. Candidates expire after the approval timeout.
+func (r *Runner) awaitApproval(ctx context.Context, run *state.Run, q queuedCandidate) {
+	defer run.ResolveCandidate(q.id)
+
+	timeout := time.NewTimer(r.approvalPolicy.Timeout)
+	defer timeout.Stop()
+
+	select {
+	case <-run.CandidateDone(q.id):
+	case <-run.Done():
+		return
+	case <-ctx.Done():
+		return
+	case <-timeout.C:
+		reason := fmt.Sprintf("no decision within %s", r.approvalPolicy.Timeout)
+		run.DecideCandidate(q.id, types.CandidateExpired, reason)
+	}
+
+	decided, ok := run.Candidate(q.id)
+	if !ok || !decided.Status.IsApproved() {
+		return
+	}
+
+	pending, ok := r.sendGeneration(ctx, run, q.candidate)
+	if !ok {
+		return
+	}
+
+	// Resolve before watching so the run moves on to waiting for generations
+	run.ResolveCandidate(q.id)
+	r.watchGeneration(ctx, run, pending)
+}
@@ -0,0 +1,32 @@
+package workflow
+
+import (
+	"brainbot/shared/rss"
+	"context"
+	"orchestrator/client"
+	"orchestrator/types"
+)
+
+// IngestionClient is the part of the ingestion service API the workflow steps use.
+// *client.IngestionClient implements it; steps can be exercised with a fake.
+type IngestionClient interface {
+	GetPresets(ctx context.Context) (map[string]rss.FeedConfig, error)
+	FetchArticles(ctx context.Context, feedPreset string, count int, dryRun bool) ([]*types.Article, error)
+	SubmitFetchJob(ctx context.Context, req types.FetchJobRequest) (*types.FetchJob, error)
+	GetFetchJob(ctx context.Context, id string) (*types.FetchJob, error)
+	ProcessArticles(ctx context.Context, namespace string, articles []*types.Article) ([]types.ArticleResult, error)
+	CheckArticles(ctx context.Context, namespace string, articles []*types.Article) ([]types.ArticleResult, error)
+	ClearCache(ctx context.Context, namespace string) error
+}
+
+// GenerationClient is the part of the generation service API the workflow steps use.
+// *client.GenerationClient implements it; steps can be exercised with a fake.
+type GenerationClient interface {
+	Generate(ctx context.Context, request client.GenerationRequest) error
+	Status(ctx context.Context, generationUUID string) (*types.WebhookPayload, error)
+}
+
+var (
+	_ IngestionClient  = (*client.IngestionClient)(nil)
+	_ GenerationClient = (*client.GenerationClient)(nil)
+)
@@ -0,0 +1,77 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"orchestrator/types"
+)
+
+// checkArticles checks fetched articles against the dedup state without
+// adding them to Chroma, the bloom filter or S3
+func (r *Runner) checkArticles(ctx context.Context, rc *RunContext) error {
+	run := rc.Run
+	run.AddLog("Dry run: checking articles for duplicates (read-only)...")
+
+	results, err := r.ingestion.CheckArticles(ctx, run.Namespace(), rc.Articles)
+	if err != nil {
+		return err
+	}
+
+	for _, res := range results {
+		switch res.Status {
+		case "new":
+			log.Printf("Article %s: would be NEW", res.Article.Title)
+		case "duplicate":
+			log.Printf("Article %s: would be DUPLICATE (%.2f%% similar)",
+				res.Article.Title, res.DeduplicationResult.SimilarityScore*100)
+		case "failed":
+			log.Printf("Article %s: FAILED extraction", res.Article.Title)
+		case "error":
+			log.Printf("Article %s: ERROR - %v", res.Article.Title, res.Error)
+		}
+	}
+
+	recordDedupResults(rc, results)
+	return nil
+}
+
+// previewGeneration applies the selection and approval policies to the checked
+// articles and records which stories would be sent, without sending them
+func (r *Runner) previewGeneration(ctx context.Context, rc *RunContext) error {
+	run := rc.Run
+
+	var priorities map[string]int
+	if r.selectionPolicy.RankBy == RankPriority {
+		priorities = r.feedPriorities(ctx)
+	}
+
+	candidates := buildCandidates(rc.Results, run.ArticleFeed, priorities, false)
+	selected := selectCandidates(candidates, r.selectionPolicy)
+
+	preview := make([]types.Candidate, 0, len(selected))
+	needReview := 0
+	for _, c := range selected {
+		status := types.CandidateAutoApproved
+		reason, auto := r.approvalPolicy.autoApproval(c.feed)
+		if !auto {
+			status = types.CandidatePending
+			needReview++
+		}
+		preview = append(preview, newCandidate(run, c, status, reason))
+	}
+	run.SetPreview(preview)
+
+	run.AddLog(fmt.Sprintf("Dry run: would generate %d of %d new stories (ranked by %s)",
+		len(selected), len(candidates), r.selectionPolicy.RankBy))
+	for _, c := range preview {
+		run.AddLog(fmt.Sprintf("Would generate %q (%s, %d source(s))", c.Title, c.Feed, c.SourceCount))
+	}
+	if needReview > 0 {
+		run.AddLog(fmt.Sprintf("%d of them would wait for editorial approval", needReview))
+	}
+
+	run.AddLog("Dry run complete. Nothing was written or sent.")
+	run.SetState(types.StateComplete)
+	return nil
+}
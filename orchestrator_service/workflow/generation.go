@@ -1,88 +1,132 @@
 package workflow
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
+	"log"
+	"orchestrator/client"
+	"orchestrator/state"
 	"orchestrator/types"
-	"os"
 
 	"github.com/google/uuid"
 )
 
-// sendGenerationRequest sends request to generation service
-func (r *Runner) sendGenerationRequest(ctx context.Context) error {
-	r.stateManager.SetState(types.StateSending)
-	r.stateManager.AddLog("Sending to generation service...")
-
-	results := r.stateManager.GetDedupResults()
-
-	// Find presigned URL from first new article and collect article URLs
-	var presignedURL string
-	var articleURLs []string
-	for _, res := range results {
-		if res.Status == "new" {
-			// Get presigned URL from first new article
-			if presignedURL == "" && res.PresignedURL != "" {
-				presignedURL = res.PresignedURL
-			}
-			// Collect article URLs
-			if res.Article != nil {
-				articleURLs = append(articleURLs, res.Article.URL)
-			}
-		}
+// pendingGeneration is a generation request the run is waiting on
+type pendingGeneration struct {
+	uuid      string
+	candidate *candidate
+}
+
+// sendGenerationRequests selects which new stories become videos and sends one
+// generation request per selected story, queueing those that need approval
+func (r *Runner) sendGenerationRequests(ctx context.Context, rc *RunContext) error {
+	run := rc.Run
+	run.AddLog("Sending to generation service...")
+
+	var priorities map[string]int
+	if r.selectionPolicy.RankBy == RankPriority {
+		priorities = r.feedPriorities(ctx)
 	}
 
-	if presignedURL == "" {
-		r.stateManager.AddLog("No presigned URL available for new articles. Workflow complete.")
-		r.stateManager.SetState(types.StateComplete)
+	candidates := buildCandidates(rc.Results, run.ArticleFeed, priorities, true)
+	if len(candidates) == 0 {
+		run.AddLog("No presigned URL available for new articles. Workflow complete.")
+		run.SetState(types.StateComplete)
 		return nil
 	}
 
-	reqUUID := uuid.New().String()
+	selected := selectCandidates(candidates, r.selectionPolicy)
+	run.AddLog(fmt.Sprintf("Selected %d of %d new stories for generation (ranked by %s)",
+		len(selected), len(candidates), r.selectionPolicy.RankBy))
 
-	requestBody := map[string]interface{}{
-		"uuid":          reqUUID,
-		"presigned_url": presignedURL,
+	// Stories that need review are queued as candidates; the rest are sent now
+	var approved []*candidate
+	var queued []queuedCandidate
+	var records []types.Candidate
+	for _, c := range selected {
+		status := types.CandidatePending
+		reason, auto := r.approvalPolicy.autoApproval(c.feed)
+		if auto {
+			status = types.CandidateAutoApproved
+			approved = append(approved, c)
+		}
+		record := newCandidate(run, c, status, reason)
+		if status == types.CandidatePending {
+			queued = append(queued, queuedCandidate{id: record.ID, candidate: c})
+		}
+		records = append(records, record)
+	}
+	if r.approvalPolicy.Required {
+		run.QueueCandidates(records)
 	}
 
-	// Add article URLs if available (optional field in schema)
-	if len(articleURLs) > 0 {
-		requestBody["article_urls"] = articleURLs
+	// Hold the run open until every story is sent; otherwise a failed first
+	// send would finish it before the rest go out
+	var pending []pendingGeneration
+	run.BeginSending()
+	for _, c := range approved {
+		if p, ok := r.sendGeneration(ctx, run, c); ok {
+			pending = append(pending, p)
+		}
 	}
+	run.EndSending()
 
-	jsonData, err := json.Marshal(requestBody)
-	if err != nil {
-		return fmt.Errorf("marshal request: %w", err)
+	rc.pending = pending
+	rc.queued = queued
+	return nil
+}
+
+// sendGeneration sends one story and reports whether it is now awaiting a result
+func (r *Runner) sendGeneration(ctx context.Context, run *state.Run, c *candidate) (pendingGeneration, bool) {
+	article := c.result.Article
+	generation := types.GenerationRecord{
+		UUID:        uuid.New().String(),
+		ArticleID:   article.ID,
+		Title:       article.Title,
+		URL:         article.URL,
+		Feed:        c.feed,
+		SourceCount: len(c.articleURLs),
 	}
 
-	// Use Docker internal DNS for generation service
-	generationURL := getEnvOrDefault("GENERATION_SERVICE_URL", "http://generation-service:8000")
-	url := fmt.Sprintf("%s/generate", generationURL)
+	if err := r.postGeneration(ctx, run, generation, c, ""); err != nil {
+		run.FailGeneration(generation.UUID, fmt.Errorf("send generation for %q: %w", article.Title, err))
+		return pendingGeneration{}, false
+	}
+	return pendingGeneration{uuid: generation.UUID, candidate: c}, true
+}
 
-	resp, err := http.Post(url, "application/json", bytes.NewBuffer(jsonData))
-	if err != nil {
-		return fmt.Errorf("failed to send request: %w", err)
+// postGeneration sends a single story to the generation service. On retry,
+// previousUUID names the request being replaced.
+func (r *Runner) postGeneration(ctx context.Context, run *state.Run, generation types.GenerationRecord, c *candidate, previousUUID string) error {
+	// Register the UUID first: the result can arrive via Kafka before the POST returns
+	if err := run.AwaitGeneration(generation, previousUUID); err != nil {
+		return err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusAccepted {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("generation service returned %d: %s", resp.StatusCode, string(body))
+	err := r.generation.Generate(ctx, client.GenerationRequest{
+		UUID:         generation.UUID,
+		PresignedURL: c.result.PresignedURL,
+		ArticleURLs:  c.articleURLs,
+	})
+	if err != nil {
+		return err
 	}
 
-	r.stateManager.SetGenerationUUID(reqUUID)
-	r.stateManager.SetState(types.StateWaiting)
-	r.stateManager.AddLog(fmt.Sprintf("Generation request sent with UUID: %s", reqUUID))
+	run.AddLog(fmt.Sprintf("Generation request sent for %q with UUID: %s", generation.Title, generation.UUID))
 	return nil
 }
 
-func getEnvOrDefault(key, defaultVal string) string {
-	if val := os.Getenv(key); val != "" {
-		return val
+// feedPriorities returns the priority of every known feed preset
+func (r *Runner) feedPriorities(ctx context.Context) map[string]int {
+	presets, err := r.ingestion.GetPresets(ctx)
+	if err != nil {
+		log.Printf("Failed to load feed priorities, ranking by recency: %v", err)
+		return nil
+	}
+
+	priorities := make(map[string]int, len(presets))
+	for name, preset := range presets {
+		priorities[name] = preset.Priority
 	}
-	return defaultVal
+	return priorities
 }
@@ -0,0 +1,82 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"orchestrator/types"
+)
+
+// ingestArticles submits an ingestion job per feed that fetches, extracts and
+// deduplicates the feed in the run's namespace, then waits for each job's
+// article events. Jobs whose events don't all arrive are read back from the
+// job API. Per-feed errors are logged, not fatal.
+func (r *Runner) ingestArticles(ctx context.Context, rc *RunContext) error {
+	run := rc.Run
+
+	type feedJob struct {
+		feed  string
+		jobID string
+	}
+	var submitted []feedJob
+	var skipped []string
+	for _, p := range run.Feeds() {
+		run.AddLog(fmt.Sprintf("Submitting ingestion job for feed: %s...", p))
+		job, err := r.ingestion.SubmitFetchJob(ctx, types.FetchJobRequest{
+			FeedPreset: p,
+			Process:    true,
+			Namespace:  run.Namespace(),
+		})
+		if err != nil {
+			run.AddLog(fmt.Sprintf("Error fetching %s: %v", p, err))
+			skipped = append(skipped, p)
+			continue
+		}
+		submitted = append(submitted, feedJob{feed: p, jobID: job.ID})
+	}
+
+	var allArticles []*types.Article
+	var allResults []types.ArticleResult
+	articleFeeds := make(map[string]string)
+
+	for _, fj := range submitted {
+		job, articles, results, err := r.ingestionEvents.Wait(ctx, fj.jobID)
+		if errors.Is(err, ErrIncompleteEvents) {
+			run.AddLog(fmt.Sprintf("Events of ingestion job %s incomplete, reading its results from the ingestion API", fj.jobID))
+			if job, err = r.ingestion.GetFetchJob(ctx, fj.jobID); err == nil {
+				articles, results = job.Articles, job.Results
+			}
+		}
+		if err != nil {
+			run.AddLog(fmt.Sprintf("Error fetching %s: %v", fj.feed, err))
+			skipped = append(skipped, fj.feed)
+			continue
+		}
+		if job.Status == types.JobFailed {
+			run.AddLog(fmt.Sprintf("Error fetching %s: %s", fj.feed, job.Error))
+			skipped = append(skipped, fj.feed)
+			continue
+		}
+
+		for _, article := range articles {
+			articleFeeds[article.ID] = fj.feed
+		}
+		allArticles = append(allArticles, articles...)
+		allResults = append(allResults, results...)
+	}
+
+	rc.Articles = allArticles
+	rc.ArticleFeeds = articleFeeds
+	rc.ingested = allResults
+	run.SetArticles(allArticles, articleFeeds, skipped)
+	logFetched(run, allArticles, skipped)
+	return nil
+}
+
+// recordIngestedResults records the dedup results collected by ingestArticles
+func (r *Runner) recordIngestedResults(ctx context.Context, rc *RunContext) error {
+	rc.Run.AddLog("Recording deduplication results from ingestion jobs...")
+	logArticleResults(rc.ingested)
+	recordDedupResults(rc, rc.ingested)
+	return nil
+}
@@ -0,0 +1,225 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"orchestrator/types"
+	"sync"
+	"time"
+)
+
+const (
+	// eventGracePeriod is how long Wait keeps waiting for article events after
+	// their job has finished before giving up on them
+	eventGracePeriod = 15 * time.Second
+	// eventRetention is how long events of a job nobody waits for are kept
+	eventRetention = time.Hour
+)
+
+// ErrIncompleteEvents is returned by IngestionEvents.Wait when a job finished
+// but some of its article events never arrived
+var ErrIncompleteEvents = errors.New("article events missing")
+
+// IngestionEvents collects the article and job events ingestion jobs publish
+// to Kafka, so runs can pick up their results without polling. The Kafka
+// consumers feed it; the ingest step waits on it.
+type IngestionEvents struct {
+	mu   sync.Mutex
+	jobs map[string]*jobEvents
+}
+
+// jobEvents is everything received so far for one ingestion job
+type jobEvents struct {
+	articles []*types.Article               // Fetched articles in arrival order
+	fetched  map[string]bool                // Article IDs seen on the fetched topic
+	results  map[string]types.ArticleResult // Dedup outcomes by article ID
+	job      *types.FetchJob                // Set once the job has finished
+	changed  chan struct{}                  // Closed and replaced on every update
+	updated  time.Time
+}
+
+// NewIngestionEvents creates an empty event collector
+func NewIngestionEvents() *IngestionEvents {
+	return &IngestionEvents{jobs: make(map[string]*jobEvents)}
+}
+
+// HandleArticle records an article event. Events outside ingestion jobs are ignored.
+func (e *IngestionEvents) HandleArticle(event *types.ArticleEvent) {
+	if event.JobID == "" {
+		return
+	}
+	article := articleFromPayload(event.Article)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	entry := e.entry(event.JobID)
+	switch event.Type {
+	case types.ArticleFetched:
+		if !entry.fetched[article.ID] {
+			entry.fetched[article.ID] = true
+			entry.articles = append(entry.articles, article)
+		}
+	case types.ArticleNew, types.ArticleDuplicate:
+		status := "new"
+		if event.Type == types.ArticleDuplicate {
+			status = "duplicate"
+		}
+		entry.results[article.ID] = types.ArticleResult{
+			Article: article,
+			Status:  status,
+			DeduplicationResult: &types.DeduplicationResult{
+				IsDuplicate:      event.Type == types.ArticleDuplicate,
+				IsExactDuplicate: event.IsExactDuplicate,
+				MatchingID:       event.MatchingID,
+				SimilarityScore:  event.SimilarityScore,
+				CheckedAt:        event.ProducedAt,
+			},
+			PresignedURL: event.PresignedURL,
+		}
+	default:
+		return
+	}
+	entry.notify()
+}
+
+// HandleJob records a finished ingestion job
+func (e *IngestionEvents) HandleJob(job *types.FetchJob) {
+	if job.ID == "" || !job.Status.Done() {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	entry := e.entry(job.ID)
+	entry.job = job
+	entry.notify()
+}
+
+// Wait blocks until a job has finished and all of its article events have
+// arrived, then returns the job summary, the fetched articles and, for process
+// jobs, the dedup results in fetch order. Articles that failed extraction or
+// deduplication are reported as "failed" or "error" results. If events are
+// still missing eventGracePeriod after the job finished, Wait returns the job
+// summary with ErrIncompleteEvents. The job's events are dropped once Wait returns.
+func (e *IngestionEvents) Wait(ctx context.Context, jobID string) (*types.FetchJob, []*types.Article, []types.ArticleResult, error) {
+	defer e.forget(jobID)
+
+	var grace <-chan time.Time
+	for {
+		e.mu.Lock()
+		entry := e.entry(jobID)
+		job := entry.job
+		complete := job != nil && entry.complete()
+		var articles []*types.Article
+		var results []types.ArticleResult
+		if complete {
+			articles, results = entry.collect()
+		}
+		changed := entry.changed
+		e.mu.Unlock()
+
+		if complete {
+			return job, articles, results, nil
+		}
+		if job != nil && grace == nil {
+			timer := time.NewTimer(eventGracePeriod)
+			defer timer.Stop()
+			grace = timer.C
+		}
+
+		select {
+		case <-changed:
+		case <-grace:
+			return job, nil, nil, ErrIncompleteEvents
+		case <-ctx.Done():
+			return nil, nil, nil, ctx.Err()
+		}
+	}
+}
+
+// entry returns a job's events, creating them and pruning stale jobs as needed (must hold lock)
+func (e *IngestionEvents) entry(jobID string) *jobEvents {
+	entry, ok := e.jobs[jobID]
+	if !ok {
+		for id, stale := range e.jobs {
+			if time.Since(stale.updated) > eventRetention {
+				delete(e.jobs, id)
+			}
+		}
+		entry = &jobEvents{
+			fetched: make(map[string]bool),
+			results: make(map[string]types.ArticleResult),
+			changed: make(chan struct{}),
+			updated: time.Now(),
+		}
+		e.jobs[jobID] = entry
+	}
+	return entry
+}
+
+// forget drops a job's events
+func (e *IngestionEvents) forget(jobID string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.jobs, jobID)
+}
+
+// notify wakes up waiters (must hold lock)
+func (j *jobEvents) notify() {
+	j.updated = time.Now()
+	close(j.changed)
+	j.changed = make(chan struct{})
+}
+
+// complete reports whether every event the finished job announced has arrived (must hold lock)
+func (j *jobEvents) complete() bool {
+	if j.job.Status == types.JobFailed {
+		return true
+	}
+	progress := j.job.Progress
+	if len(j.fetched) < progress.Total {
+		return false
+	}
+	return !j.job.Process || len(j.results) >= progress.New+progress.Duplicates
+}
+
+// collect assembles the articles and, for process jobs, their results (must hold lock)
+func (j *jobEvents) collect() ([]*types.Article, []types.ArticleResult) {
+	if !j.job.Process {
+		return j.articles, nil
+	}
+
+	results := make([]types.ArticleResult, 0, len(j.articles))
+	for _, article := range j.articles {
+		if result, ok := j.results[article.ID]; ok {
+			results = append(results, result)
+			continue
+		}
+		if article.ExtractionError != "" {
+			results = append(results, types.ArticleResult{Article: article, Status: "failed", Error: article.ExtractionError})
+			continue
+		}
+		results = append(results, types.ArticleResult{Article: article, Status: "error", Error: "deduplication failed on the ingestion service"})
+	}
+	return j.articles, results
+}
+
+// articleFromPayload converts an event's article; the HTML content isn't carried
+func articleFromPayload(payload types.ArticlePayload) *types.Article {
+	return &types.Article{
+		ID:              payload.ID,
+		Title:           payload.Title,
+		URL:             payload.URL,
+		PublishedAt:     payload.PublishedAt,
+		FetchedAt:       payload.FetchedAt,
+		Summary:         payload.Summary,
+		Author:          payload.Author,
+		Categories:      payload.Categories,
+		FullContentText: payload.ContentText,
+		Excerpt:         payload.Excerpt,
+		ImageURL:        payload.ImageURL,
+		ExtractionError: payload.ExtractionError,
+	}
+}
@@ -0,0 +1,237 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"orchestrator/state"
+	"orchestrator/types"
+	"time"
+)
+
+// RunContext carries a run and the outputs of earlier steps through a pipeline.
+// Each step reads the fields produced before it and fills in its own.
+type RunContext struct {
+	Run     *state.Run
+	Options RunOptions
+
+	// Set by the fetch step
+	Articles     []*types.Article
+	ArticleFeeds map[string]string // Article ID -> feed preset
+
+	// Set by the dedup step
+	Results []types.ArticleResult
+
+	// Set by the ingest step, consumed by the record step
+	ingested []types.ArticleResult
+
+	// Set by the generate step, consumed by the await step
+	pending []pendingGeneration
+	queued  []queuedCandidate
+}
+
+// Step is a named unit of work in a pipeline
+type Step struct {
+	Name string
+	// State is entered before the step runs; empty leaves the run state unchanged
+	State types.State
+	// Skip, when set, is checked before the step runs
+	Skip func(rc *RunContext) bool
+	// Timeout bounds a single attempt; zero means no limit
+	Timeout time.Duration
+	// Retries is how many times a failed attempt is repeated. Only idempotent steps retry.
+	Retries int
+	// Backoff is the delay before each retry, doubled per attempt
+	Backoff time.Duration
+	Run     func(ctx context.Context, rc *RunContext) error
+}
+
+// Pipeline is an ordered list of steps executed for a run
+type Pipeline struct {
+	Name  string
+	Steps []Step
+}
+
+// Execute runs the steps in order. It stops early once the run reaches a
+// terminal state, and fails the run with the step name if a step fails.
+func (p Pipeline) Execute(ctx context.Context, rc *RunContext) error {
+	run := rc.Run
+	for _, step := range p.Steps {
+		if run.GetState().IsTerminal() {
+			return nil
+		}
+		if step.Skip != nil && step.Skip(rc) {
+			log.Printf("Run %s: skipping step %s", run.ID(), step.Name)
+			continue
+		}
+		if step.State != "" {
+			run.SetState(step.State)
+		}
+
+		if err := step.execute(ctx, rc); err != nil {
+			err = fmt.Errorf("%s: %w", step.Name, err)
+			run.SetError(err)
+			return err
+		}
+	}
+	return nil
+}
+
+// execute runs a single step, applying its timeout and retries
+func (s Step) execute(ctx context.Context, rc *RunContext) error {
+	for attempt := 0; ; attempt++ {
+		err := s.attempt(ctx, rc)
+		if err == nil || attempt >= s.Retries || ctx.Err() != nil {
+			return err
+		}
+
+		backoff := s.Backoff << attempt
+		rc.Run.AddLog(fmt.Sprintf("Step %s failed (%v), retrying in %s (%d/%d)",
+			s.Name, err, backoff, attempt+1, s.Retries))
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+	}
+}
+
+// attempt runs the step once under its timeout
+func (s Step) attempt(ctx context.Context, rc *RunContext) error {
+	if s.Timeout <= 0 {
+		return s.Run(ctx, rc)
+	}
+	stepCtx, cancel := context.WithTimeout(ctx, s.Timeout)
+	defer cancel()
+	return s.Run(stepCtx, rc)
+}
+
+// Pipeline names
+const (
+	PipelineStart   = "start"   // Clear the namespace's dedup state, then fetch, dedup and generate
+	PipelineRefresh = "refresh" // Fetch, dedup and generate against the existing dedup state
+	PipelineDryRun  = "dry-run" // Fetch and check dedup read-only, then report what would be generated
+)
+
+// pipeline returns the pipeline definition for a run
+func (r *Runner) pipeline(opts RunOptions) Pipeline {
+	if opts.DryRun {
+		return Pipeline{
+			Name:  PipelineDryRun,
+			Steps: []Step{r.fetchStep(), r.checkStep(), r.previewStep()},
+		}
+	}
+
+	steps := []Step{r.fetchStep(), r.dedupStep()}
+	if r.ingestionEvents != nil {
+		steps = []Step{r.ingestStep(), r.recordStep()}
+	}
+	steps = append(steps, r.generateStep(), r.awaitStep())
+
+	if opts.ClearCache {
+		return Pipeline{
+			Name:  PipelineStart,
+			Steps: append([]Step{r.clearStep()}, steps...),
+		}
+	}
+	return Pipeline{
+		Name:  PipelineRefresh,
+		Steps: steps,
+	}
+}
+
+// clearStep clears the namespace's dedup state; clearing twice is harmless so it retries
+func (r *Runner) clearStep() Step {
+	return Step{
+		Name:    "clear cache",
+		State:   types.StateClearing,
+		Timeout: time.Minute,
+		Retries: 2,
+		Backoff: 2 * time.Second,
+		Run:     r.clearCache,
+	}
+}
+
+// fetchStep fetches the run's feeds one after another. Each fetch is bounded by
+// the ingestion client's FetchTimeout rather than a step timeout, which a run
+// over many feeds would outgrow. Feeds that fail are skipped, not fatal.
+func (r *Runner) fetchStep() Step {
+	return Step{
+		Name:  "fetch articles",
+		State: types.StateFetching,
+		Run:   r.fetchArticles,
+	}
+}
+
+// dedupStep deduplicates fetched articles. Processing adds new articles to the
+// dedup state, so it is never retried.
+func (r *Runner) dedupStep() Step {
+	return Step{
+		Name:  "deduplicate",
+		State: types.StateDeduplicating,
+		Run:   r.deduplicateArticles,
+	}
+}
+
+// ingestStep has ingestion jobs fetch and deduplicate the run's feeds and
+// collects the outcomes from Kafka. Like the dedup step it writes to the dedup
+// state, so it is never retried.
+func (r *Runner) ingestStep() Step {
+	return Step{
+		Name:    "ingest articles",
+		State:   types.StateFetching,
+		Timeout: 10 * time.Minute,
+		Run:     r.ingestArticles,
+	}
+}
+
+// recordStep records the dedup results the ingest step collected
+func (r *Runner) recordStep() Step {
+	return Step{
+		Name:  "record deduplication",
+		State: types.StateDeduplicating,
+		Run:   r.recordIngestedResults,
+	}
+}
+
+// checkStep checks fetched articles against the dedup state without writing to it;
+// being read-only, it is safe to retry
+func (r *Runner) checkStep() Step {
+	return Step{
+		Name:    "check duplicates",
+		State:   types.StateDeduplicating,
+		Retries: 1,
+		Backoff: 2 * time.Second,
+		Run:     r.checkArticles,
+	}
+}
+
+// previewStep reports the stories a dry run would send and completes the run
+func (r *Runner) previewStep() Step {
+	return Step{
+		Name: "preview generation",
+		Run:  r.previewGeneration,
+	}
+}
+
+// generateStep selects stories and sends or queues them; the watchdog owns retries
+func (r *Runner) generateStep() Step {
+	return Step{
+		Name:  "send generation",
+		State: types.StateSending,
+		Run:   r.sendGenerationRequests,
+	}
+}
+
+// awaitStep waits for approvals and generation results. The run moves itself to
+// awaiting_approval or waiting, and the watchdog bounds how long each result takes.
+func (r *Runner) awaitStep() Step {
+	return Step{
+		Name: "await results",
+		Skip: func(rc *RunContext) bool {
+			return len(rc.pending) == 0 && len(rc.queued) == 0
+		},
+		Run: r.awaitResults,
+	}
+}
@@ -0,0 +1,317 @@
+package workflow
+
+import (
+	"brainbot/shared/rss"
+	"context"
+	"errors"
+	"orchestrator/client"
+	"orchestrator/state"
+	"orchestrator/types"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeIngestion is an in-memory IngestionClient. Each *Err field holds the
+// errors returned by successive calls; once it is exhausted calls succeed.
+type fakeIngestion struct {
+	mu sync.Mutex
+
+	articles   []*types.Article
+	fetchErr   []error
+	clearErr   []error
+	processErr []error
+	checkErr   []error
+
+	calls map[string]int
+}
+
+func (f *fakeIngestion) record(method string, errs *[]error) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.calls == nil {
+		f.calls = make(map[string]int)
+	}
+	f.calls[method]++
+	if errs == nil || len(*errs) == 0 {
+		return nil
+	}
+	err := (*errs)[0]
+	*errs = (*errs)[1:]
+	return err
+}
+
+func (f *fakeIngestion) count(method string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls[method]
+}
+
+func (f *fakeIngestion) GetPresets(ctx context.Context) (map[string]rss.FeedConfig, error) {
+	return map[string]rss.FeedConfig{}, f.record("GetPresets", nil)
+}
+
+func (f *fakeIngestion) FetchArticles(ctx context.Context, feedPreset string, count int, dryRun bool) ([]*types.Article, error) {
+	if err := f.record("FetchArticles", &f.fetchErr); err != nil {
+		return nil, err
+	}
+	return f.articles, nil
+}
+
+func (f *fakeIngestion) SubmitFetchJob(ctx context.Context, req types.FetchJobRequest) (*types.FetchJob, error) {
+	return nil, f.record("SubmitFetchJob", nil)
+}
+
+func (f *fakeIngestion) GetFetchJob(ctx context.Context, id string) (*types.FetchJob, error) {
+	return nil, f.record("GetFetchJob", nil)
+}
+
+func (f *fakeIngestion) ProcessArticles(ctx context.Context, namespace string, articles []*types.Article) ([]types.ArticleResult, error) {
+	if err := f.record("ProcessArticles", &f.processErr); err != nil {
+		return nil, err
+	}
+	return newResults(articles), nil
+}
+
+func (f *fakeIngestion) CheckArticles(ctx context.Context, namespace string, articles []*types.Article) ([]types.ArticleResult, error) {
+	if err := f.record("CheckArticles", &f.checkErr); err != nil {
+		return nil, err
+	}
+	return newResults(articles), nil
+}
+
+func (f *fakeIngestion) ClearCache(ctx context.Context, namespace string) error {
+	return f.record("ClearCache", &f.clearErr)
+}
+
+// newResults reports every article as new
+func newResults(articles []*types.Article) []types.ArticleResult {
+	results := make([]types.ArticleResult, 0, len(articles))
+	for _, a := range articles {
+		results = append(results, types.ArticleResult{Article: a, Status: "new"})
+	}
+	return results
+}
+
+// fakeGeneration is a GenerationClient that accepts every request
+type fakeGeneration struct{}
+
+func (fakeGeneration) Generate(ctx context.Context, request client.GenerationRequest) error {
+	return nil
+}
+
+func (fakeGeneration) Status(ctx context.Context, generationUUID string) (*types.WebhookPayload, error) {
+	return nil, errors.New("not found")
+}
+
+// newTestRunner returns a runner over fake clients and a run reserved for one feed
+func newTestRunner(t *testing.T, ingestion *fakeIngestion) (*Runner, *state.Run) {
+	t.Helper()
+	manager := state.NewManager("", nil, nil, 0)
+	runner := NewRunner(manager, RunnerConfig{
+		IngestionClient:  ingestion,
+		GenerationClient: fakeGeneration{},
+	})
+	run, err := manager.StartRun(state.RunSpec{Feeds: []string{"tech"}})
+	if err != nil {
+		t.Fatalf("StartRun() = %v", err)
+	}
+	return runner, run
+}
+
+// fastRetries shortens a step's backoff so retries don't slow the tests down
+func fastRetries(step Step) Step {
+	step.Backoff = time.Millisecond
+	return step
+}
+
+func TestPipelineRunsStepsInOrder(t *testing.T) {
+	_, run := newTestRunner(t, &fakeIngestion{})
+
+	var ran []string
+	var states []types.State
+	step := func(name string, st types.State) Step {
+		return Step{
+			Name:  name,
+			State: st,
+			Run: func(ctx context.Context, rc *RunContext) error {
+				ran = append(ran, name)
+				states = append(states, rc.Run.GetState())
+				return nil
+			},
+		}
+	}
+
+	skipped := step("skipped", types.StateSending)
+	skipped.Skip = func(rc *RunContext) bool { return true }
+
+	pipeline := Pipeline{Name: "test", Steps: []Step{
+		step("first", types.StateFetching),
+		skipped,
+		step("second", ""),
+		step("third", types.StateDeduplicating),
+	}}
+	if err := pipeline.Execute(context.Background(), &RunContext{Run: run}); err != nil {
+		t.Fatalf("Execute() = %v", err)
+	}
+
+	if want := []string{"first", "second", "third"}; !reflect.DeepEqual(ran, want) {
+		t.Errorf("steps ran %v, want %v", ran, want)
+	}
+	// A step without a State leaves the previous step's state in place
+	if want := []types.State{types.StateFetching, types.StateFetching, types.StateDeduplicating}; !reflect.DeepEqual(states, want) {
+		t.Errorf("states %v, want %v", states, want)
+	}
+}
+
+func TestPipelineStopsOnceRunIsTerminal(t *testing.T) {
+	_, run := newTestRunner(t, &fakeIngestion{})
+
+	var ran []string
+	pipeline := Pipeline{Name: "test", Steps: []Step{
+		{Name: "complete", Run: func(ctx context.Context, rc *RunContext) error {
+			ran = append(ran, "complete")
+			rc.Run.SetState(types.StateComplete)
+			return nil
+		}},
+		{Name: "after", Run: func(ctx context.Context, rc *RunContext) error {
+			ran = append(ran, "after")
+			return nil
+		}},
+	}}
+	if err := pipeline.Execute(context.Background(), &RunContext{Run: run}); err != nil {
+		t.Fatalf("Execute() = %v", err)
+	}
+	if want := []string{"complete"}; !reflect.DeepEqual(ran, want) {
+		t.Errorf("steps ran %v, want %v", ran, want)
+	}
+}
+
+func TestOnlyIdempotentStepsRetry(t *testing.T) {
+	runner, _ := newTestRunner(t, &fakeIngestion{})
+
+	tests := []struct {
+		step  Step
+		retry bool
+	}{
+		{runner.clearStep(), true},
+		{runner.checkStep(), true},
+		{runner.fetchStep(), false},
+		{runner.dedupStep(), false},
+		{runner.ingestStep(), false},
+		{runner.recordStep(), false},
+		{runner.generateStep(), false},
+	}
+	for _, tt := range tests {
+		if retries := tt.step.Retries > 0; retries != tt.retry {
+			t.Errorf("step %q: Retries = %d, want retries %v", tt.step.Name, tt.step.Retries, tt.retry)
+		}
+		if tt.retry && tt.step.Backoff <= 0 {
+			t.Errorf("step %q retries without a backoff", tt.step.Name)
+		}
+	}
+}
+
+func TestClearRetriesButDedupDoesNot(t *testing.T) {
+	ingestion := &fakeIngestion{
+		articles:   []*types.Article{{ID: "a1", Title: "Story"}},
+		clearErr:   []error{errors.New("chroma unavailable")},
+		processErr: []error{errors.New("chroma unavailable")},
+	}
+	runner, run := newTestRunner(t, ingestion)
+
+	pipeline := Pipeline{Name: "test", Steps: []Step{
+		fastRetries(runner.clearStep()),
+		runner.fetchStep(),
+		fastRetries(runner.dedupStep()),
+	}}
+	err := pipeline.Execute(context.Background(), &RunContext{Run: run})
+	if err == nil {
+		t.Fatal("Execute() succeeded, want the dedup failure")
+	}
+
+	if n := ingestion.count("ClearCache"); n != 2 {
+		t.Errorf("ClearCache called %d times, want 2 (one retry)", n)
+	}
+	if n := ingestion.count("ProcessArticles"); n != 1 {
+		t.Errorf("ProcessArticles called %d times, want 1 (never retried)", n)
+	}
+}
+
+func TestCheckStepRetries(t *testing.T) {
+	ingestion := &fakeIngestion{
+		articles: []*types.Article{{ID: "a1", Title: "Story"}},
+		checkErr: []error{errors.New("timeout")},
+	}
+	runner, run := newTestRunner(t, ingestion)
+
+	pipeline := Pipeline{Name: "test", Steps: []Step{runner.fetchStep(), fastRetries(runner.checkStep())}}
+	rc := &RunContext{Run: run}
+	if err := pipeline.Execute(context.Background(), rc); err != nil {
+		t.Fatalf("Execute() = %v", err)
+	}
+	if n := ingestion.count("CheckArticles"); n != 2 {
+		t.Errorf("CheckArticles called %d times, want 2", n)
+	}
+	if len(rc.Results) != 1 {
+		t.Errorf("got %d results, want 1", len(rc.Results))
+	}
+}
+
+func TestFailingStepSetsErrorWithStepName(t *testing.T) {
+	ingestion := &fakeIngestion{
+		articles:   []*types.Article{{ID: "a1", Title: "Story"}},
+		processErr: []error{errors.New("boom")},
+	}
+	runner, run := newTestRunner(t, ingestion)
+
+	var ranAfter bool
+	pipeline := Pipeline{Name: "test", Steps: []Step{
+		runner.fetchStep(),
+		runner.dedupStep(),
+		{Name: "after", Run: func(ctx context.Context, rc *RunContext) error {
+			ranAfter = true
+			return nil
+		}},
+	}}
+	err := pipeline.Execute(context.Background(), &RunContext{Run: run})
+	if err == nil || err.Error() != "deduplicate: boom" {
+		t.Fatalf("Execute() = %v, want %q", err, "deduplicate: boom")
+	}
+	if ranAfter {
+		t.Error("step after the failure ran")
+	}
+
+	if st := run.GetState(); st != types.StateError {
+		t.Errorf("run state = %s, want %s", st, types.StateError)
+	}
+	if record := run.Record(); !strings.HasPrefix(record.Error, "deduplicate:") {
+		t.Errorf("run error = %q, want it to name the step", record.Error)
+	}
+}
+
+func TestFailedFeedsAreSkippedAndReported(t *testing.T) {
+	ingestion := &fakeIngestion{
+		articles: []*types.Article{{ID: "a1", Title: "Story"}},
+		fetchErr: []error{context.DeadlineExceeded},
+	}
+	runner, run := newTestRunner(t, ingestion)
+
+	step := runner.fetchStep()
+	if step.Timeout != 0 {
+		t.Errorf("fetch step timeout = %s; each feed is bounded by FetchTimeout instead", step.Timeout)
+	}
+	pipeline := Pipeline{Name: "test", Steps: []Step{step}}
+	if err := pipeline.Execute(context.Background(), &RunContext{Run: run}); err != nil {
+		t.Fatalf("Execute() = %v", err)
+	}
+
+	if skipped := run.Record().SkippedFeeds; !reflect.DeepEqual(skipped, []string{"tech"}) {
+		t.Errorf("skipped feeds = %v, want [tech]", skipped)
+	}
+	if skipped := run.Status().SkippedFeeds; !reflect.DeepEqual(skipped, []string{"tech"}) {
+		t.Errorf("status skipped feeds = %v, want [tech]", skipped)
+	}
+}
@@ -0,0 +1,116 @@
+package workflow
+
+import (
+	"fmt"
+	"orchestrator/types"
+	"sort"
+	"strings"
+)
+
+// RankStrategy orders generation candidates
+type RankStrategy string
+
+const (
+	// RankRecency picks the most recently published stories first
+	RankRecency RankStrategy = "recency"
+	// RankSources picks stories covered by the most articles in the run first
+	RankSources RankStrategy = "sources"
+	// RankPriority picks stories from the highest-priority feeds first
+	RankPriority RankStrategy = "priority"
+)
+
+// SelectionPolicy decides which new stories in a run become videos
+type SelectionPolicy struct {
+	MaxVideos int // Zero means no limit
+	RankBy    RankStrategy
+}
+
+// DefaultSelectionPolicy sends up to 5 of the most recent stories per run
+func DefaultSelectionPolicy() SelectionPolicy {
+	return SelectionPolicy{
+		MaxVideos: 5,
+		RankBy:    RankRecency,
+	}
+}
+
+// ParseRankStrategy validates a ranking name
+func ParseRankStrategy(value string) (RankStrategy, error) {
+	switch strategy := RankStrategy(strings.ToLower(strings.TrimSpace(value))); strategy {
+	case RankRecency, RankSources, RankPriority:
+		return strategy, nil
+	case "":
+		return RankRecency, nil
+	default:
+		return "", fmt.Errorf("unknown rank strategy %q (use recency, sources or priority)", value)
+	}
+}
+
+// candidate is a new story that could be sent to generation
+type candidate struct {
+	result      types.ArticleResult
+	feed        string
+	priority    int
+	articleURLs []string // The new article first, then in-run duplicates of it
+}
+
+// buildCandidates groups a run's dedup results into stories: each new article with
+// an uploaded story object, plus the duplicates in the same run that matched it.
+// Dry runs upload nothing, so they pass requireUpload=false.
+func buildCandidates(results []types.ArticleResult, feedOf func(articleID string) string, priorities map[string]int, requireUpload bool) []*candidate {
+	byID := make(map[string]*candidate)
+	var candidates []*candidate
+
+	for _, res := range results {
+		if res.Status != "new" || res.Article == nil || (requireUpload && res.PresignedURL == "") {
+			continue
+		}
+		feed := feedOf(res.Article.ID)
+		c := &candidate{
+			result:      res,
+			feed:        feed,
+			priority:    priorities[feed],
+			articleURLs: []string{res.Article.URL},
+		}
+		byID[res.Article.ID] = c
+		candidates = append(candidates, c)
+	}
+
+	for _, res := range results {
+		if res.Status != "duplicate" || res.Article == nil || res.DeduplicationResult == nil {
+			continue
+		}
+		if c, ok := byID[res.DeduplicationResult.MatchingID]; ok {
+			c.articleURLs = append(c.articleURLs, res.Article.URL)
+		}
+	}
+
+	return candidates
+}
+
+// selectCandidates ranks candidates by the policy and keeps at most MaxVideos.
+// Ties fall back to recency, then title for a stable order.
+func selectCandidates(candidates []*candidate, policy SelectionPolicy) []*candidate {
+	sorted := append([]*candidate{}, candidates...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		switch policy.RankBy {
+		case RankSources:
+			if len(a.articleURLs) != len(b.articleURLs) {
+				return len(a.articleURLs) > len(b.articleURLs)
+			}
+		case RankPriority:
+			if a.priority != b.priority {
+				return a.priority > b.priority
+			}
+		}
+		if !a.result.Article.PublishedAt.Equal(b.result.Article.PublishedAt) {
+			return a.result.Article.PublishedAt.After(b.result.Article.PublishedAt)
+		}
+		return a.result.Article.Title < b.result.Article.Title
+	})
+
+	if policy.MaxVideos > 0 && len(sorted) > policy.MaxVideos {
+		sorted = sorted[:policy.MaxVideos]
+	}
+	return sorted
+}
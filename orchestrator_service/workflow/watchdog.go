@@ -0,0 +1,141 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"orchestrator/state"
+	"orchestrator/types"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// GenerationPolicy controls how long a run waits for the generation service
+// and whether a timed-out generation is retried. Failures reported by the
+// generation service are final.
+type GenerationPolicy struct {
+	// Deadline is how long to wait for a result before giving up on an attempt
+	Deadline time.Duration
+	// PollInterval enables polling GET {GENERATION_SERVICE_URL}/status/{uuid}; zero disables it
+	PollInterval time.Duration
+	// MaxRetries is how many times the generation request is re-sent after a timeout
+	MaxRetries int
+	// RetryBackoff is the delay before each retry, doubled per attempt
+	RetryBackoff time.Duration
+}
+
+// DefaultGenerationPolicy waits 15 minutes for a single attempt without polling
+func DefaultGenerationPolicy() GenerationPolicy {
+	return GenerationPolicy{
+		Deadline:     15 * time.Minute,
+		RetryBackoff: 30 * time.Second,
+	}
+}
+
+// watchGenerations waits for every pending generation and queued candidate of the run concurrently
+func (r *Runner) watchGenerations(ctx context.Context, run *state.Run, pending []pendingGeneration, queued []queuedCandidate) {
+	var wg sync.WaitGroup
+	for _, p := range pending {
+		wg.Add(1)
+		go func(p pendingGeneration) {
+			defer wg.Done()
+			r.watchGeneration(ctx, run, p)
+		}(p)
+	}
+	for _, q := range queued {
+		wg.Add(1)
+		go func(q queuedCandidate) {
+			defer wg.Done()
+			r.awaitApproval(ctx, run, q)
+		}(q)
+	}
+	wg.Wait()
+}
+
+// watchGeneration waits for a generation result, retrying or failing the generation
+// when the deadline passes so a lost result never leaves the run waiting forever.
+func (r *Runner) watchGeneration(ctx context.Context, run *state.Run, p pendingGeneration) {
+	policy := r.generationPolicy
+	generationUUID := p.uuid
+
+	for attempt := 0; ; attempt++ {
+		reason := r.waitForGeneration(ctx, run, generationUUID)
+		if reason == "" {
+			return
+		}
+
+		if attempt >= policy.MaxRetries {
+			run.FailGeneration(generationUUID, fmt.Errorf("generation %s: %s", generationUUID, reason))
+			return
+		}
+
+		backoff := policy.RetryBackoff << attempt
+		run.AddLog(fmt.Sprintf("Generation %s (%s), retrying in %s (%d/%d)",
+			generationUUID, reason, backoff, attempt+1, policy.MaxRetries))
+
+		select {
+		case <-run.GenerationDone(generationUUID):
+			return
+		case <-ctx.Done():
+			run.FailGeneration(generationUUID, ctx.Err())
+			return
+		case <-time.After(backoff):
+		}
+
+		article := p.candidate.result.Article
+		retry := types.GenerationRecord{UUID: uuid.New().String(), Title: article.Title}
+		if err := r.postGeneration(ctx, run, retry, p.candidate, generationUUID); err != nil {
+			if errors.Is(err, state.ErrGenerationFinished) {
+				// The previous attempt's result arrived during the backoff
+				return
+			}
+			run.FailGeneration(retry.UUID, fmt.Errorf("send generation for %q: %w", article.Title, err))
+			return
+		}
+		generationUUID = retry.UUID
+	}
+}
+
+// waitForGeneration blocks until the generation finishes (returns "") or the
+// current attempt should be abandoned (returns the reason).
+func (r *Runner) waitForGeneration(ctx context.Context, run *state.Run, generationUUID string) string {
+	policy := r.generationPolicy
+	done := run.GenerationDone(generationUUID)
+
+	deadline := time.NewTimer(policy.Deadline)
+	defer deadline.Stop()
+
+	var poll <-chan time.Time
+	if policy.PollInterval > 0 {
+		ticker := time.NewTicker(policy.PollInterval)
+		defer ticker.Stop()
+		poll = ticker.C
+	}
+
+	for {
+		select {
+		case <-done:
+			return ""
+		case <-run.Done():
+			return ""
+		case <-ctx.Done():
+			return ctx.Err().Error()
+		case <-deadline.C:
+			return fmt.Sprintf("timed out after %s", policy.Deadline)
+		case <-poll:
+			payload, err := r.generation.Status(ctx, generationUUID)
+			if err != nil {
+				log.Printf("Generation status poll for %s failed: %v", generationUUID, err)
+				continue
+			}
+			if payload == nil {
+				continue
+			}
+			// A finished request whose result message was lost; applying it closes done
+			r.stateManager.HandleGenerationResult(payload)
+		}
+	}
+}
@@ -4,161 +4,224 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"orchestrator/client"
 	"orchestrator/state"
 	"orchestrator/types"
+	"sort"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
 
 // Runner executes the complete workflow
 type Runner struct {
-	stateManager *state.Manager
+	stateManager     *state.Manager
+	ingestion        IngestionClient
+	generation       GenerationClient
+	generationPolicy GenerationPolicy
+	selectionPolicy  SelectionPolicy
+	approvalPolicy   ApprovalPolicy
+	ingestionEvents  *IngestionEvents
 }
 
-// NewRunner creates a new workflow runner
-func NewRunner(stateManager *state.Manager) *Runner {
-	return &Runner{
-		stateManager: stateManager,
-	}
+// RunnerConfig holds the policies a Runner applies to every run
+type RunnerConfig struct {
+	Generation GenerationPolicy
+	Selection  SelectionPolicy
+	Approval   ApprovalPolicy
+
+	// Clients default to the state manager's ingestion client and the
+	// generation service at client.DefaultGenerationURL
+	IngestionClient  IngestionClient
+	GenerationClient GenerationClient
+
+	// IngestionEvents, when set, has ingestion jobs fetch and deduplicate each
+	// run's feeds, with outcomes collected from the Kafka article topics.
+	// Dry runs always use the HTTP API.
+	IngestionEvents *IngestionEvents
 }
 
-// Run executes the complete workflow
-// This is called either by manual trigger (POST /start) or by cron job
-func (r *Runner) Run(ctx context.Context, feedPreset string) error {
-	_ = godotenv.Load()
-
-	// Step 1: Clear cache
-	if err := r.clearCache(ctx); err != nil {
-		r.stateManager.SetError(fmt.Errorf("clear cache: %w", err))
-		return err
+// DefaultRunnerConfig returns the default policies
+func DefaultRunnerConfig() RunnerConfig {
+	return RunnerConfig{
+		Generation: DefaultGenerationPolicy(),
+		Selection:  DefaultSelectionPolicy(),
+		Approval:   DefaultApprovalPolicy(),
 	}
+}
 
-	// Step 2: Fetch articles
-	if err := r.fetchArticles(ctx, feedPreset); err != nil {
-		r.stateManager.SetError(fmt.Errorf("fetch articles: %w", err))
-		return err
+// NewRunner creates a new workflow runner
+func NewRunner(stateManager *state.Manager, config RunnerConfig) *Runner {
+	defaults := DefaultRunnerConfig()
+	if config.Generation.Deadline <= 0 {
+		config.Generation.Deadline = defaults.Generation.Deadline
 	}
-
-	// Step 3: Deduplicate
-	if err := r.deduplicateArticles(ctx); err != nil {
-		r.stateManager.SetError(fmt.Errorf("deduplicate: %w", err))
-		return err
+	if config.Approval.Timeout <= 0 {
+		config.Approval.Timeout = defaults.Approval.Timeout
 	}
-
-	// Step 4: Send to generation service
-	if err := r.sendGenerationRequest(ctx); err != nil {
-		r.stateManager.SetError(fmt.Errorf("send generation: %w", err))
-		return err
+	if config.IngestionClient == nil {
+		config.IngestionClient = stateManager.GetIngestionClient()
+	}
+	if config.GenerationClient == nil {
+		config.GenerationClient = client.NewGenerationClient("")
 	}
 
-	if r.stateManager.GetState() == types.StateComplete {
-		return nil
+	return &Runner{
+		stateManager:     stateManager,
+		ingestion:        config.IngestionClient,
+		generation:       config.GenerationClient,
+		generationPolicy: config.Generation,
+		selectionPolicy:  config.Selection,
+		approvalPolicy:   config.Approval,
+		ingestionEvents:  config.IngestionEvents,
 	}
+}
 
-	// Step 5: Wait for webhook (async - webhook handler will update state)
-	r.stateManager.AddLog("Workflow initiated successfully, waiting for generation service callback via Kafka")
-	return nil
+// RunOptions describes a single workflow run
+type RunOptions struct {
+	Trigger    types.RunTrigger
+	FeedPreset string // Empty fetches all feeds that aren't already being processed
+	Namespace  string // Selects which dedup state (Chroma collection, bloom keys) is used
+	ClearCache bool   // Clear the namespace's dedup state before fetching
+	DryRun     bool   // Only report what the run would generate; nothing is cleared, written or sent
 }
 
-// RunRefresh executes the workflow without clearing the cache
-// This allows fetching new articles while keeping the history for deduplication
-func (r *Runner) RunRefresh(ctx context.Context, feedPreset string) error {
+// Start reserves a run for the requested feeds and executes the workflow in the background.
+// This is called either by manual trigger (POST /start, /refresh) or by cron job.
+// It returns state.ErrFeedBusy, state.ErrNamespaceBusy or state.ErrConcurrencyLimit
+// when the run can't be scheduled.
+func (r *Runner) Start(ctx context.Context, opts RunOptions) (*state.Run, error) {
 	_ = godotenv.Load()
 
-	// Skip Step 1: Clear cache
+	if opts.DryRun {
+		opts.ClearCache = false
+	}
 
-	// Step 2: Fetch articles
-	if err := r.fetchArticles(ctx, feedPreset); err != nil {
-		r.stateManager.SetError(fmt.Errorf("fetch articles: %w", err))
-		return err
+	feeds, err := r.resolveFeeds(ctx, opts.FeedPreset)
+	if err != nil {
+		return nil, fmt.Errorf("resolve feeds: %w", err)
 	}
 
-	// Step 3: Deduplicate
-	if err := r.deduplicateArticles(ctx); err != nil {
-		r.stateManager.SetError(fmt.Errorf("deduplicate: %w", err))
-		return err
+	run, err := r.stateManager.StartRun(state.RunSpec{
+		Trigger:    opts.Trigger,
+		Namespace:  opts.Namespace,
+		Feeds:      feeds,
+		Exclusive:  opts.FeedPreset != "",
+		ClearCache: opts.ClearCache,
+		DryRun:     opts.DryRun,
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// Step 4: Send to generation service
-	if err := r.sendGenerationRequest(ctx); err != nil {
-		r.stateManager.SetError(fmt.Errorf("send generation: %w", err))
-		return err
+	go func() {
+		if err := r.execute(ctx, run, opts); err != nil {
+			log.Printf("Workflow run %s error: %v", run.ID(), err)
+		}
+	}()
+
+	return run, nil
+}
+
+// execute runs the pipeline for a reserved run
+func (r *Runner) execute(ctx context.Context, run *state.Run, opts RunOptions) error {
+	pipeline := r.pipeline(opts)
+	log.Printf("Run %s: executing %s pipeline", run.ID(), pipeline.Name)
+	// Service calls carry the run ID so ingestion logs can be matched to the run
+	ctx = client.WithRequestID(ctx, run.ID())
+	return pipeline.Execute(ctx, &RunContext{Run: run, Options: opts})
+}
+
+// resolveFeeds expands an empty preset to every known feed
+func (r *Runner) resolveFeeds(ctx context.Context, feedPreset string) ([]string, error) {
+	if feedPreset != "" {
+		return []string{feedPreset}, nil
 	}
 
-	if r.stateManager.GetState() == types.StateComplete {
-		return nil
+	presets, err := r.ingestion.GetPresets(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	// Step 5: Wait for webhook (async - webhook handler will update state)
-	r.stateManager.AddLog("Refresh workflow initiated successfully, waiting for generation service callback via Kafka")
-	return nil
+	feeds := make([]string, 0, len(presets))
+	for p := range presets {
+		feeds = append(feeds, p)
+	}
+	sort.Strings(feeds)
+	return feeds, nil
 }
 
-// clearCache clears the ChromaDB cache
-func (r *Runner) clearCache(ctx context.Context) error {
-	r.stateManager.SetState(types.StateClearing)
-	r.stateManager.AddLog("Clearing ChromaDB cache...")
+// clearCache clears the namespace's dedup state
+func (r *Runner) clearCache(ctx context.Context, rc *RunContext) error {
+	run := rc.Run
+	namespace := run.Namespace()
+	if namespace != "" {
+		run.AddLog(fmt.Sprintf("Clearing ChromaDB cache (namespace: %s)...", namespace))
+	} else {
+		run.AddLog("Clearing ChromaDB cache...")
+	}
 
-	client := r.stateManager.GetIngestionClient()
-	err := client.ClearCache(ctx)
-	if err != nil {
+	if err := r.ingestion.ClearCache(ctx, namespace); err != nil {
 		return err
 	}
 
-	r.stateManager.AddLog("Cache cleared successfully")
+	run.AddLog("Cache cleared successfully")
 	return nil
 }
 
-// fetchArticles fetches RSS articles
-func (r *Runner) fetchArticles(ctx context.Context, feedPreset string) error {
-	r.stateManager.SetState(types.StateFetching)
-
-	client := r.stateManager.GetIngestionClient()
+// fetchArticles fetches RSS articles for the feeds locked by the run
+func (r *Runner) fetchArticles(ctx context.Context, rc *RunContext) error {
+	run := rc.Run
 	var allArticles []*types.Article
+	var skipped []string
+	articleFeeds := make(map[string]string)
 
-	presetsToFetch := []string{}
-	if feedPreset != "" {
-		presetsToFetch = append(presetsToFetch, feedPreset)
-	} else {
-		// Fetch all
-		r.stateManager.AddLog("Fetching all RSS feeds...")
-		presets, err := client.GetPresets(ctx)
-		if err != nil {
-			return err
-		}
-		for p := range presets {
-			presetsToFetch = append(presetsToFetch, p)
-		}
-	}
-
-	for _, p := range presetsToFetch {
-		r.stateManager.AddLog(fmt.Sprintf("Fetching feed: %s...", p))
-		articles, err := client.FetchArticles(ctx, p, 0)
+	for _, p := range run.Feeds() {
+		run.AddLog(fmt.Sprintf("Fetching feed: %s...", p))
+		articles, err := r.ingestion.FetchArticles(ctx, p, 0, run.DryRun())
 		if err != nil {
-			r.stateManager.AddLog(fmt.Sprintf("Error fetching %s: %v", p, err))
+			run.AddLog(fmt.Sprintf("Error fetching %s: %v", p, err))
+			skipped = append(skipped, p)
 			continue
 		}
+		for _, article := range articles {
+			articleFeeds[article.ID] = p
+		}
 		allArticles = append(allArticles, articles...)
 	}
 
-	r.stateManager.SetArticles(allArticles)
-	r.stateManager.AddLog(fmt.Sprintf("Fetched total %d articles", len(allArticles)))
+	rc.Articles = allArticles
+	rc.ArticleFeeds = articleFeeds
+	run.SetArticles(allArticles, articleFeeds, skipped)
+	logFetched(run, allArticles, skipped)
 	return nil
 }
 
+// logFetched logs how many articles were fetched and which feeds were skipped
+func logFetched(run *state.Run, articles []*types.Article, skipped []string) {
+	run.AddLog(fmt.Sprintf("Fetched total %d articles", len(articles)))
+	if len(skipped) > 0 {
+		run.AddLog(fmt.Sprintf("Skipped %d of %d feeds: %s", len(skipped), len(run.Feeds()), strings.Join(skipped, ", ")))
+	}
+}
+
 // deduplicateArticles processes articles for deduplication
-func (r *Runner) deduplicateArticles(ctx context.Context) error {
-	r.stateManager.SetState(types.StateDeduplicating)
-	r.stateManager.AddLog("Deduplicating articles...")
+func (r *Runner) deduplicateArticles(ctx context.Context, rc *RunContext) error {
+	run := rc.Run
+	run.AddLog("Deduplicating articles...")
 
-	articles := r.stateManager.GetArticles()
-	client := r.stateManager.GetIngestionClient()
-	results, err := client.ProcessArticles(ctx, articles)
+	results, err := r.ingestion.ProcessArticles(ctx, run.Namespace(), rc.Articles)
 	if err != nil {
 		return err
 	}
 
-	// Log individual results
+	logArticleResults(results)
+	recordDedupResults(rc, results)
+	return nil
+}
+
+// logArticleResults logs each article's dedup outcome
+func logArticleResults(results []types.ArticleResult) {
 	for _, res := range results {
 		switch res.Status {
 		case "new":
@@ -172,8 +235,13 @@ func (r *Runner) deduplicateArticles(ctx context.Context) error {
 			log.Printf("Article %s: ERROR - %v", res.Article.Title, res.Error)
 		}
 	}
+}
 
-	r.stateManager.SetDedupResults(results)
+// recordDedupResults stores the dedup results on the run and logs the totals
+func recordDedupResults(rc *RunContext, results []types.ArticleResult) {
+	run := rc.Run
+	rc.Results = results
+	run.SetDedupResults(results)
 
 	newCount := 0
 	dupCount := 0
@@ -193,8 +261,16 @@ func (r *Runner) deduplicateArticles(ctx context.Context) error {
 		}
 	}
 
-	r.stateManager.AddLog(fmt.Sprintf("Results: %d new, %d duplicates, %d failed, %d errors", newCount, dupCount, failCount, errCount))
-	return nil
+	run.AddLog(fmt.Sprintf("Results: %d new, %d duplicates, %d failed, %d errors", newCount, dupCount, failCount, errCount))
 }
 
-// sendGenerationRequest is implemented in generation.go
+// awaitResults waits for approvals and generation results (Kafka/webhook handler updates
+// state; the watchdog fails or retries a generation if nothing arrives before the deadline)
+func (r *Runner) awaitResults(ctx context.Context, rc *RunContext) error {
+	if len(rc.pending) > 0 {
+		rc.Run.AddLog(fmt.Sprintf("Workflow initiated successfully, waiting up to %s for generation service callback via Kafka",
+			r.generationPolicy.Deadline))
+	}
+	r.watchGenerations(ctx, rc.Run, rc.pending, rc.queued)
+	return nil
+}
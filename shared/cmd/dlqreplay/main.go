@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+
+	"brainbot/shared/kafka"
+
+	"github.com/joho/godotenv"
+)
+
+// dlqreplay sends messages from a dead-letter topic back to the topic they failed on.
+// Replayed offsets are recorded under -group, so running it again only replays new failures.
+//
+//	go run ./shared/cmd/dlqreplay -topic video-requests.dlq -dry-run
+//	go run ./shared/cmd/dlqreplay -topic video-requests.dlq
+func main() {
+	_ = godotenv.Load()
+
+	brokers := flag.String("brokers", os.Getenv("KAFKA_BOOTSTRAP_SERVERS"), "Comma-separated Kafka bootstrap servers")
+	topic := flag.String("topic", "", "Dead-letter topic to replay (required)")
+	target := flag.String("to", "", "Topic to replay to (defaults to each message's original topic)")
+	group := flag.String("group", "dlq-replay", "Group that records how far the topic has been replayed")
+	limit := flag.Int("limit", 0, "Replay at most this many messages (0 replays all)")
+	dryRun := flag.Bool("dry-run", false, "List the messages without replaying them")
+	flag.Parse()
+
+	if *topic == "" {
+		fmt.Fprintln(os.Stderr, "Usage: dlqreplay -topic <topic>.dlq [-to topic] [-limit n] [-dry-run]")
+		os.Exit(2)
+	}
+
+	var brokerList []string
+	for _, broker := range strings.Split(*brokers, ",") {
+		if broker = strings.TrimSpace(broker); broker != "" {
+			brokerList = append(brokerList, broker)
+		}
+	}
+	if len(brokerList) == 0 {
+		brokerList = []string{"localhost:9092"}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	count, err := kafka.ReplayDeadLetters(ctx, kafka.ReplayConfig{
+		Brokers: brokerList,
+		Topic:   *topic,
+		Target:  *target,
+		GroupID: *group,
+		Limit:   *limit,
+		DryRun:  *dryRun,
+		OnMessage: func(m kafka.ReplayedMessage) {
+			fmt.Printf("partition=%d offset=%d key=%s -> %s (attempts=%s, failed_at=%s): %s\n",
+				m.Partition, m.Offset, m.Key, m.Target, m.Attempts, m.FailedAt, m.Error)
+		},
+	})
+	if err != nil {
+		log.Fatalf("replay failed after %d messages: %v", count, err)
+	}
+
+	if *dryRun {
+		log.Printf("Dry run: %d messages would be replayed from %s", count, *topic)
+	} else {
+		log.Printf("Replayed %d messages from %s", count, *topic)
+	}
+}
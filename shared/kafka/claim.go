@@ -0,0 +1,316 @@
+package kafka
+
+import (
+	"context"
+	"hash/fnv"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+// pauseAfter is how long a partition may sit at MaxInFlight before it is paused
+const pauseAfter = time.Second
+
+// PartitionLag describes one claimed partition
+type PartitionLag struct {
+	Topic         string `json:"topic"`
+	Partition     int32  `json:"partition"`
+	HighWaterMark int64  `json:"high_water_mark"` // Offset the next produced message will get
+	Committed     int64  `json:"committed"`       // Next offset the group will resume from
+	Lag           int64  `json:"lag"`             // Messages not yet handled
+	InFlight      int    `json:"in_flight"`       // Messages dispatched but not yet marked
+	Paused        bool   `json:"paused"`
+}
+
+// claimState tracks a claimed partition's in-flight messages so offsets are
+// marked in order even when messages complete out of order
+type claimState struct {
+	claim     sarama.ConsumerGroupClaim
+	pending   []*pendingMessage // Dispatched messages in offset order
+	committed int64
+	paused    bool
+}
+
+// pendingMessage is a dispatched message and whether it has completed
+type pendingMessage struct {
+	message *sarama.ConsumerMessage
+	done    bool
+}
+
+// handled is a worker's outcome for one message
+type handled struct {
+	message *sarama.ConsumerMessage
+	err     error
+}
+
+// consumeClaim hands a partition's messages to its workers, routing messages
+// by key so each key is handled in order, and marks offsets as the oldest
+// in-flight messages complete
+func (c *Consumer) consumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	ctx, cancel := context.WithCancel(session.Context())
+	defer cancel()
+
+	state := c.trackClaim(claim)
+	defer c.untrackClaim(claim.Partition())
+
+	// Buffers are sized so neither dispatching nor reporting ever blocks
+	results := make(chan handled, c.maxInFlight)
+	queues := make([]chan *sarama.ConsumerMessage, c.concurrency)
+	workers := make(chan struct{}, c.concurrency)
+	for i := range queues {
+		queues[i] = make(chan *sarama.ConsumerMessage, c.maxInFlight)
+		go func(queue <-chan *sarama.ConsumerMessage) {
+			defer func() { workers <- struct{}{} }()
+			for message := range queue {
+				if ctx.Err() != nil {
+					// Left unmarked; the message is redelivered to whoever claims the partition next
+					continue
+				}
+				// Messages are marked in order whatever the handler's shouldMark says
+				_, err := c.handle(ctx, message)
+				results <- handled{message: message, err: err}
+			}
+		}(queues[i])
+	}
+
+	c.dispatch(ctx, session, state, queues, results)
+
+	// Let the workers finish what they hold, then mark whatever completed in order
+	cancel()
+	for _, queue := range queues {
+		close(queue)
+	}
+	for range queues {
+		<-workers
+	}
+	close(results)
+	for result := range results {
+		c.complete(session, state, result)
+	}
+	return nil
+}
+
+// dispatch feeds messages to the workers until the claim or the session ends
+func (c *Consumer) dispatch(ctx context.Context, session sarama.ConsumerGroupSession, state *claimState,
+	queues []chan *sarama.ConsumerMessage, results <-chan handled) {
+	for {
+		if c.inFlight(state) >= c.maxInFlight {
+			if c.awaitSlot(ctx, session, state, results); ctx.Err() != nil {
+				return
+			}
+			continue
+		}
+
+		select {
+		case message, ok := <-state.claim.Messages():
+			if !ok {
+				return
+			}
+			log.Printf("Received Kafka message: partition=%d, offset=%d, key=%s",
+				message.Partition, message.Offset, string(message.Key))
+
+			c.mu.Lock()
+			state.pending = append(state.pending, &pendingMessage{message: message})
+			c.mu.Unlock()
+			queues[worker(message, len(queues))] <- message
+
+		case result := <-results:
+			c.complete(session, state, result)
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// awaitSlot waits for an in-flight message to complete, pausing the partition
+// if that takes longer than pauseAfter
+func (c *Consumer) awaitSlot(ctx context.Context, session sarama.ConsumerGroupSession, state *claimState, results <-chan handled) {
+	timer := time.NewTimer(pauseAfter)
+	defer timer.Stop()
+
+	for {
+		select {
+		case result := <-results:
+			c.resumeSaturated(state)
+			c.complete(session, state, result)
+			return
+		case <-timer.C:
+			c.pauseSaturated(state)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// complete records a handled message and marks every leading completed offset.
+// A message that could not be dead-lettered is left unmarked, which holds back
+// every later offset of the partition, and the session is restarted so the
+// message is redelivered from the last committed offset.
+func (c *Consumer) complete(session sarama.ConsumerGroupSession, state *claimState, result handled) {
+	if result.err != nil {
+		if session.Context().Err() == nil {
+			log.Printf("Kafka partition %s/%d: %v; restarting the session to redeliver it",
+				result.message.Topic, result.message.Partition, result.err)
+			c.restartSession()
+		}
+		// Otherwise the session ended mid-retry; the message is redelivered after the rebalance
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, p := range state.pending {
+		if p.message == result.message {
+			p.done = true
+			break
+		}
+	}
+	for len(state.pending) > 0 && state.pending[0].done {
+		message := state.pending[0].message
+		state.pending = state.pending[1:]
+		session.MarkOffset(message.Topic, message.Partition, message.Offset+1, "")
+		state.committed = message.Offset + 1
+	}
+}
+
+// restartSession ends the current group session. The member rejoins the group
+// and its claims resume from their committed offsets.
+func (c *Consumer) restartSession() {
+	c.mu.Lock()
+	cancel := c.cancelSession
+	c.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+}
+
+// worker picks the worker for a message: by key hash, or by offset for keyless messages
+func worker(message *sarama.ConsumerMessage, workers int) int {
+	if len(message.Key) == 0 {
+		return int(message.Offset % int64(workers))
+	}
+	h := fnv.New32a()
+	h.Write(message.Key)
+	return int(h.Sum32() % uint32(workers))
+}
+
+// inFlight returns how many of a claim's messages are dispatched but not yet marked
+func (c *Consumer) inFlight(state *claimState) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(state.pending)
+}
+
+// trackClaim registers a new claim for lag reporting
+func (c *Consumer) trackClaim(claim sarama.ConsumerGroupClaim) *claimState {
+	state := &claimState{claim: claim, committed: claim.InitialOffset()}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.claims[claim.Partition()] = state
+	return state
+}
+
+// untrackClaim forgets a claim once it has been revoked
+func (c *Consumer) untrackClaim(partition int32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.claims, partition)
+}
+
+// pauseSaturated pauses a partition whose workers are all busy
+func (c *Consumer) pauseSaturated(state *claimState) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if state.paused {
+		return
+	}
+	state.paused = true
+	c.consumer.Pause(map[string][]int32{c.topic: {state.claim.Partition()}})
+	log.Printf("Kafka partition %s/%d saturated (%d in flight), paused", c.topic, state.claim.Partition(), len(state.pending))
+}
+
+// resumeSaturated resumes a partition paused by pauseSaturated, unless Pause was called
+func (c *Consumer) resumeSaturated(state *claimState) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !state.paused {
+		return
+	}
+	state.paused = false
+	if !c.pausedByAPI {
+		c.consumer.Resume(map[string][]int32{c.topic: {state.claim.Partition()}})
+		log.Printf("Kafka partition %s/%d resumed", c.topic, state.claim.Partition())
+	}
+}
+
+// Pause stops fetching from every claimed partition, e.g. while the handler's
+// downstream is saturated. Messages already fetched are still handled.
+func (c *Consumer) Pause() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pausedByAPI = true
+	c.consumer.PauseAll()
+	log.Printf("Kafka consumer paused (group: %s, topic: %s)", c.groupID, c.topic)
+}
+
+// Resume undoes Pause. Partitions still saturated stay paused until a slot frees up.
+func (c *Consumer) Resume() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pausedByAPI = false
+	c.consumer.ResumeAll()
+	for _, state := range c.claims {
+		if state.paused {
+			c.consumer.Pause(map[string][]int32{c.topic: {state.claim.Partition()}})
+		}
+	}
+	log.Printf("Kafka consumer resumed (group: %s, topic: %s)", c.groupID, c.topic)
+}
+
+// Lag reports the partitions this member currently claims, ordered by partition
+func (c *Consumer) Lag() []PartitionLag {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	lags := make([]PartitionLag, 0, len(c.claims))
+	for partition, state := range c.claims {
+		highWater := state.claim.HighWaterMarkOffset()
+		lag := PartitionLag{
+			Topic:         c.topic,
+			Partition:     partition,
+			HighWaterMark: highWater,
+			Committed:     state.committed,
+			InFlight:      len(state.pending),
+			Paused:        state.paused || c.pausedByAPI,
+		}
+		if state.committed >= 0 && highWater > state.committed {
+			lag.Lag = highWater - state.committed
+		}
+		lags = append(lags, lag)
+	}
+	sort.Slice(lags, func(i, j int) bool { return lags[i].Partition < lags[j].Partition })
+	return lags
+}
+
+// reportLag logs each claimed partition's lag every lagInterval until ctx is done
+func (c *Consumer) reportLag(ctx context.Context) {
+	ticker := time.NewTicker(c.lagInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			for _, lag := range c.Lag() {
+				log.Printf("Kafka lag %s/%d: %d (committed %d, high water %d, in flight %d, paused %t)",
+					lag.Topic, lag.Partition, lag.Lag, lag.Committed, lag.HighWaterMark, lag.InFlight, lag.Paused)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
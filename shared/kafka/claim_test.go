@@ -0,0 +1,154 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
+)
+
+// fakeSession records marked offsets
+type fakeSession struct {
+	ctx context.Context
+
+	mu     sync.Mutex
+	marked int64
+}
+
+func (s *fakeSession) Claims() map[string][]int32 { return nil }
+func (s *fakeSession) MemberID() string           { return "member" }
+func (s *fakeSession) GenerationID() int32        { return 1 }
+func (s *fakeSession) Commit()                    {}
+func (s *fakeSession) Context() context.Context   { return s.ctx }
+func (s *fakeSession) ResetOffset(topic string, partition int32, offset int64, metadata string) {
+}
+func (s *fakeSession) MarkMessage(msg *sarama.ConsumerMessage, metadata string) {
+	s.MarkOffset(msg.Topic, msg.Partition, msg.Offset+1, metadata)
+}
+func (s *fakeSession) MarkOffset(topic string, partition int32, offset int64, metadata string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.marked = offset
+}
+
+func (s *fakeSession) markedOffset() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.marked
+}
+
+// fakeClaim serves a fixed set of messages on partition 0
+type fakeClaim struct {
+	messages chan *sarama.ConsumerMessage
+}
+
+func newFakeClaim(values ...string) *fakeClaim {
+	claim := &fakeClaim{messages: make(chan *sarama.ConsumerMessage, len(values))}
+	for i, value := range values {
+		claim.messages <- &sarama.ConsumerMessage{Topic: "events", Offset: int64(i), Value: []byte(value)}
+	}
+	return claim
+}
+
+func (c *fakeClaim) Topic() string                            { return "events" }
+func (c *fakeClaim) Partition() int32                         { return 0 }
+func (c *fakeClaim) InitialOffset() int64                     { return 0 }
+func (c *fakeClaim) HighWaterMarkOffset() int64               { return int64(cap(c.messages)) }
+func (c *fakeClaim) Messages() <-chan *sarama.ConsumerMessage { return c.messages }
+
+// handlerFunc adapts a function to MessageHandler
+type handlerFunc func(ctx context.Context, message []byte) (bool, error)
+
+func (f handlerFunc) HandleMessage(ctx context.Context, message []byte) (bool, error) {
+	return f(ctx, message)
+}
+
+// newTestConsumer returns a consumer whose session is ended by restartSession
+func newTestConsumer(t *testing.T, handler MessageHandler, deadLetter sarama.SyncProducer) (*Consumer, *fakeSession) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	c := &Consumer{
+		handler:         handler,
+		topic:           "events",
+		retry:           RetryPolicy{MaxAttempts: 1, Backoff: time.Millisecond, MaxBackoff: time.Millisecond},
+		deadLetter:      deadLetter,
+		deadLetterTopic: "events.dlq",
+		concurrency:     1,
+		maxInFlight:     3,
+		claims:          make(map[int32]*claimState),
+		cancelSession:   cancel,
+	}
+	return c, &fakeSession{ctx: ctx}
+}
+
+// consumeUntil runs the claim loop until the session ends or all messages are marked
+func consumeUntil(t *testing.T, c *Consumer, session *fakeSession, claim *fakeClaim, marked int64) {
+	t.Helper()
+	done := make(chan error, 1)
+	go func() { done <- c.consumeClaim(session, claim) }()
+
+	deadline := time.After(5 * time.Second)
+	for session.ctx.Err() == nil && session.markedOffset() < marked {
+		select {
+		case err := <-done:
+			t.Fatalf("consumeClaim returned early: %v", err)
+		case <-deadline:
+			t.Fatal("claim never finished")
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+	c.restartSession()
+	if err := <-done; err != nil {
+		t.Errorf("consumeClaim() = %v", err)
+	}
+}
+
+func TestMessagesAreMarkedWhateverShouldMarkSays(t *testing.T) {
+	handler := handlerFunc(func(ctx context.Context, message []byte) (bool, error) {
+		return false, nil
+	})
+	c, session := newTestConsumer(t, handler, mocks.NewSyncProducer(t, nil))
+
+	consumeUntil(t, c, session, newFakeClaim("a", "b"), 2)
+	if got := session.markedOffset(); got != 2 {
+		t.Errorf("marked offset = %d, want 2", got)
+	}
+}
+
+func TestFailedDeadLetterRestartsTheSession(t *testing.T) {
+	handler := handlerFunc(func(ctx context.Context, message []byte) (bool, error) {
+		if string(message) == "bad" {
+			return false, errors.New("handler failed")
+		}
+		return true, nil
+	})
+	deadLetter := mocks.NewSyncProducer(t, nil)
+	deadLetter.ExpectSendMessageAndFail(sarama.ErrNotEnoughReplicas)
+	c, session := newTestConsumer(t, handler, deadLetter)
+
+	done := make(chan error, 1)
+	go func() { done <- c.consumeClaim(session, newFakeClaim("ok", "bad", "ok")) }()
+
+	select {
+	case <-session.ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("session wasn't restarted after the dead-letter send failed")
+	}
+	if err := <-done; err != nil {
+		t.Errorf("consumeClaim() = %v", err)
+	}
+
+	// The failed message and everything after it is left for redelivery
+	if got := session.markedOffset(); got != 1 {
+		t.Errorf("marked offset = %d, want 1", got)
+	}
+	if err := deadLetter.Close(); err != nil {
+		t.Errorf("dead-letter producer: %v", err)
+	}
+}
@@ -3,7 +3,11 @@ package kafka
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
+	"sync"
+	"time"
 
 	"github.com/IBM/sarama"
 )
@@ -11,19 +15,33 @@ import (
 // MessageHandler defines the interface for handling consumed messages
 // Each service implements this to provide custom message processing logic
 type MessageHandler interface {
-	// HandleMessage processes a Kafka message and returns whether to mark it as processed
-	// If error is returned, the message will not be marked (allowing retry)
-	// If shouldMark is false, the message will not be marked (allowing retry)
+	// HandleMessage processes a Kafka message
+	// If error is returned, the message is retried per the consumer's RetryPolicy,
+	// then sent to the dead-letter topic
+	// shouldMark is advisory: offsets are committed in order, so every message
+	// that returns without error is marked once the messages before it are.
+	// Return an error for messages that must be handled again.
 	HandleMessage(ctx context.Context, message []byte) (shouldMark bool, err error)
 }
 
 // Consumer handles Kafka message consumption with pluggable message handling
 type Consumer struct {
-	consumer sarama.ConsumerGroup
-	handler  MessageHandler
-	topic    string
-	groupID  string
-	ready    chan bool
+	consumer        sarama.ConsumerGroup
+	handler         MessageHandler
+	topic           string
+	groupID         string
+	ready           chan bool
+	retry           RetryPolicy
+	deadLetter      sarama.SyncProducer
+	deadLetterTopic string
+	concurrency     int
+	maxInFlight     int
+	lagInterval     time.Duration
+
+	mu            sync.Mutex
+	claims        map[int32]*claimState // Partitions currently claimed by this member
+	pausedByAPI   bool                  // Pause was called; saturation doesn't resume partitions
+	cancelSession context.CancelFunc    // Ends the current group session so the member rejoins
 }
 
 // ConsumerConfig holds Kafka consumer configuration
@@ -32,27 +50,136 @@ type ConsumerConfig struct {
 	Topic   string
 	GroupID string
 	Handler MessageHandler
+	// Retry controls how a message whose handler fails is retried; zero fields use DefaultRetryPolicy
+	Retry RetryPolicy
+	// DeadLetterTopic receives messages that still fail after the last attempt
+	// (defaults to DeadLetterTopic(Topic))
+	DeadLetterTopic string
+
+	// Concurrency is how many messages of one partition are handled at once
+	// (default 1). Messages with the same key always go to the same worker,
+	// so they are handled in order; keyless messages are spread by offset.
+	Concurrency int
+	// MaxInFlight caps the messages of one partition that are queued, being
+	// handled or done but waiting for an earlier offset to complete (defaults
+	// to Concurrency). A partition that stays at the cap is paused until the
+	// oldest message completes.
+	MaxInFlight int
+	// InitialOffset is where a group without committed offsets starts (default OffsetNewest)
+	InitialOffset InitialOffset
+	// BalanceStrategy assigns partitions to group members (default round-robin)
+	BalanceStrategy sarama.BalanceStrategy
+	// LagReportInterval, when set, logs each claimed partition's lag this often
+	LagReportInterval time.Duration
+}
+
+// InitialOffset selects where a new consumer group starts reading
+type InitialOffset string
+
+const (
+	OffsetNewest InitialOffset = "newest" // Only messages produced after the group joins
+	OffsetOldest InitialOffset = "oldest" // Everything still retained in the topic
+)
+
+// ParseInitialOffset parses "newest" or "oldest"; empty means newest
+func ParseInitialOffset(value string) (InitialOffset, error) {
+	switch InitialOffset(value) {
+	case "", OffsetNewest:
+		return OffsetNewest, nil
+	case OffsetOldest:
+		return OffsetOldest, nil
+	}
+	return "", fmt.Errorf("unknown initial offset %q (want newest or oldest)", value)
+}
+
+// RetryPolicy controls in-process retries of failed messages
+type RetryPolicy struct {
+	MaxAttempts int           // Attempts per message, including the first
+	Backoff     time.Duration // Delay before the first retry, doubled per attempt
+	MaxBackoff  time.Duration // Upper bound on a single delay
+}
+
+// DefaultRetryPolicy returns the default retry policy
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		Backoff:     time.Second,
+		MaxBackoff:  30 * time.Second,
+	}
+}
+
+// delay returns the backoff before retry number attempt (1-based)
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	delay := p.Backoff
+	for i := 1; i < attempt && delay < p.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	return delay
 }
 
 // NewConsumer creates a new Kafka consumer
 func NewConsumer(config ConsumerConfig) (*Consumer, error) {
+	if config.BalanceStrategy == nil {
+		config.BalanceStrategy = sarama.NewBalanceStrategyRoundRobin()
+	}
+	initialOffset := sarama.OffsetNewest
+	if config.InitialOffset == OffsetOldest {
+		initialOffset = sarama.OffsetOldest
+	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = 1
+	}
+	if config.MaxInFlight < config.Concurrency {
+		config.MaxInFlight = config.Concurrency
+	}
+
 	saramaConfig := sarama.NewConfig()
 	saramaConfig.Version = sarama.V3_6_0_0
-	saramaConfig.Consumer.Group.Rebalance.GroupStrategies = []sarama.BalanceStrategy{sarama.NewBalanceStrategyRoundRobin()}
-	saramaConfig.Consumer.Offsets.Initial = sarama.OffsetNewest
+	saramaConfig.Consumer.Group.Rebalance.GroupStrategies = []sarama.BalanceStrategy{config.BalanceStrategy}
+	saramaConfig.Consumer.Offsets.Initial = initialOffset
 	saramaConfig.Consumer.Return.Errors = true
 
+	retry := DefaultRetryPolicy()
+	if config.Retry.MaxAttempts > 0 {
+		retry.MaxAttempts = config.Retry.MaxAttempts
+	}
+	if config.Retry.Backoff > 0 {
+		retry.Backoff = config.Retry.Backoff
+	}
+	if config.Retry.MaxBackoff > 0 {
+		retry.MaxBackoff = config.Retry.MaxBackoff
+	}
+	if config.DeadLetterTopic == "" {
+		config.DeadLetterTopic = DeadLetterTopic(config.Topic)
+	}
+
 	client, err := sarama.NewConsumerGroup(config.Brokers, config.GroupID, saramaConfig)
 	if err != nil {
 		return nil, err
 	}
 
+	deadLetter, err := sarama.NewSyncProducer(config.Brokers, NewProducerConfig(ProducerConfig{}))
+	if err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to create dead-letter producer: %w", err)
+	}
+
 	consumer := &Consumer{
-		consumer: client,
-		handler:  config.Handler,
-		topic:    config.Topic,
-		groupID:  config.GroupID,
-		ready:    make(chan bool),
+		consumer:        client,
+		handler:         config.Handler,
+		topic:           config.Topic,
+		groupID:         config.GroupID,
+		ready:           make(chan bool),
+		retry:           retry,
+		deadLetter:      deadLetter,
+		deadLetterTopic: config.DeadLetterTopic,
+		concurrency:     config.Concurrency,
+		maxInFlight:     config.MaxInFlight,
+		lagInterval:     config.LagReportInterval,
+		claims:          make(map[int32]*claimState),
 	}
 
 	return consumer, nil
@@ -61,30 +188,39 @@ func NewConsumer(config ConsumerConfig) (*Consumer, error) {
 // Start begins consuming messages from Kafka
 func (c *Consumer) Start(ctx context.Context) error {
 	handler := &consumerGroupHandler{
-		consumer:       c,
-		messageHandler: c.handler,
-		ready:          c.ready,
+		consumer: c,
+		ready:    c.ready,
 	}
 
 	go func() {
 		for {
-			if err := c.consumer.Consume(ctx, []string{c.topic}, handler); err != nil {
-				if err == context.Canceled {
-					log.Println("Kafka consumer context canceled")
-					return
-				}
-				log.Printf("Error from Kafka consumer: %v", err)
-			}
+			// Each session gets its own context so restartSession can end it
+			// without stopping the consumer
+			sessionCtx, cancel := context.WithCancel(ctx)
+			c.mu.Lock()
+			c.cancelSession = cancel
+			c.mu.Unlock()
 
+			err := c.consumer.Consume(sessionCtx, []string{c.topic}, handler)
+			cancel()
 			if ctx.Err() != nil {
+				log.Println("Kafka consumer context canceled")
 				return
 			}
+			if err != nil && !errors.Is(err, context.Canceled) {
+				log.Printf("Error from Kafka consumer: %v", err)
+			}
 			handler.ready = make(chan bool)
 		}
 	}()
 
 	<-c.ready
-	log.Printf("Kafka consumer started (group: %s, topic: %s)", c.groupID, c.topic)
+	log.Printf("Kafka consumer started (group: %s, topic: %s, concurrency: %d, max in flight: %d)",
+		c.groupID, c.topic, c.concurrency, c.maxInFlight)
+
+	if c.lagInterval > 0 {
+		go c.reportLag(ctx)
+	}
 
 	// Handle errors
 	go func() {
@@ -99,14 +235,52 @@ func (c *Consumer) Start(ctx context.Context) error {
 // Close gracefully shuts down the consumer
 func (c *Consumer) Close() error {
 	log.Println("Closing Kafka consumer...")
-	return c.consumer.Close()
+	return errors.Join(c.consumer.Close(), c.deadLetter.Close())
+}
+
+// handle runs the handler for a message, retrying failures with backoff and
+// dead-lettering the message once the attempts are used up. An error means the
+// message could not be dead-lettered, so it must be left unmarked and redelivered.
+func (c *Consumer) handle(ctx context.Context, message *sarama.ConsumerMessage) (bool, error) {
+	var err error
+	for attempt := 1; ; attempt++ {
+		var shouldMark bool
+		shouldMark, err = c.handler.HandleMessage(ctx, message.Value)
+		if err == nil {
+			return shouldMark, nil
+		}
+		if ctx.Err() != nil {
+			// Failures caused by the session ending (e.g. a shutdown interrupting
+			// the handler) are left for redelivery rather than dead-lettered
+			return false, ctx.Err()
+		}
+		if attempt >= c.retry.MaxAttempts {
+			break
+		}
+
+		delay := c.retry.delay(attempt)
+		log.Printf("Failed to handle message (partition=%d, offset=%d, attempt %d/%d): %v, retrying in %s",
+			message.Partition, message.Offset, attempt, c.retry.MaxAttempts, err, delay)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			// The session is ending; the unmarked message is redelivered after the rebalance
+			return false, ctx.Err()
+		}
+	}
+
+	log.Printf("Failed to handle message (partition=%d, offset=%d) after %d attempts: %v, sending to %s",
+		message.Partition, message.Offset, c.retry.MaxAttempts, err, c.deadLetterTopic)
+	if _, _, dlqErr := c.deadLetter.SendMessage(deadLetterMessage(message, c.deadLetterTopic, err, c.retry.MaxAttempts)); dlqErr != nil {
+		return false, fmt.Errorf("failed to dead-letter message at offset %d: %w", message.Offset, dlqErr)
+	}
+	return true, nil
 }
 
 // consumerGroupHandler implements sarama.ConsumerGroupHandler
 type consumerGroupHandler struct {
-	consumer       *Consumer
-	messageHandler MessageHandler
-	ready          chan bool
+	consumer *Consumer
+	ready    chan bool
 }
 
 // Setup is run at the beginning of a new session, before ConsumeClaim
@@ -122,31 +296,7 @@ func (h *consumerGroupHandler) Cleanup(sarama.ConsumerGroupSession) error {
 
 // ConsumeClaim must start a consumer loop of ConsumerGroupClaim's Messages()
 func (h *consumerGroupHandler) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
-	for {
-		select {
-		case message := <-claim.Messages():
-			if message == nil {
-				return nil
-			}
-
-			log.Printf("Received Kafka message: partition=%d, offset=%d, key=%s",
-				message.Partition, message.Offset, string(message.Key))
-
-			// Delegate to custom handler
-			shouldMark, err := h.messageHandler.HandleMessage(session.Context(), message.Value)
-			if err != nil {
-				log.Printf("Failed to handle message: %v", err)
-			}
-
-			// Mark message if handler indicates success
-			if shouldMark {
-				session.MarkMessage(message, "")
-			}
-
-		case <-session.Context().Done():
-			return nil
-		}
-	}
+	return h.consumer.consumeClaim(session, claim)
 }
 
 // TypedMessageHandler is a generic helper that handles type conversion
@@ -156,17 +306,45 @@ type TypedMessageHandler[T any] struct {
 	Validate func(msg *T) bool
 	// Process handles the actual message processing
 	Process func(ctx context.Context, msg *T) error
-	// AlwaysMark determines if messages should be marked even on validation failure
+	// AlwaysMark is returned as shouldMark for messages that are skipped (wrong
+	// type, undecodable or invalid). The consumer marks skipped messages either
+	// way; it only documents that they are not retried.
 	AlwaysMark bool
+
+	// Type, when set, is the envelope type this handler accepts; envelopes of
+	// other types are skipped. Messages without an envelope are always accepted.
+	Type string
+	// Version is the payload version T represents. Older payloads, including
+	// unenveloped ones (version 0), are brought up to it with Upgrades; newer
+	// ones are decoded as far as T allows, so producers can upgrade first.
+	Version int
+	// Upgrades converts payloads of version v to v+1, keyed by v
+	Upgrades map[int]UpgradeFunc
 }
 
 // HandleMessage implements MessageHandler interface
 func (h *TypedMessageHandler[T]) HandleMessage(ctx context.Context, message []byte) (bool, error) {
+	envelope := decodeEnvelope(message)
+	if h.Type != "" && envelope.Type != "" && envelope.Type != h.Type {
+		log.Printf("Skipping message %s of type %s (want %s)", envelope.ID, envelope.Type, h.Type)
+		return h.AlwaysMark, nil
+	}
+	if envelope.Version > h.Version {
+		log.Printf("Message %s has version %d, newer than %d; decoding known fields only", envelope.ID, envelope.Version, h.Version)
+	}
+
+	payload, err := upgrade(envelope.Payload, envelope.Version, h.Version, h.Upgrades)
+	if err != nil {
+		log.Printf("Failed to upgrade message %s: %v", envelope.ID, err)
+		return h.AlwaysMark, nil // Mark to skip invalid messages
+	}
+
 	var msg T
-	if err := json.Unmarshal(message, &msg); err != nil {
+	if err := json.Unmarshal(payload, &msg); err != nil {
 		log.Printf("Failed to unmarshal message: %v", err)
 		return h.AlwaysMark, nil // Mark to skip invalid messages
 	}
+	ctx = withEnvelope(ctx, envelope)
 
 	// Validate message
 	if h.Validate != nil && !h.Validate(&msg) {
@@ -176,7 +354,7 @@ func (h *TypedMessageHandler[T]) HandleMessage(ctx context.Context, message []by
 
 	// Process message
 	if err := h.Process(ctx, &msg); err != nil {
-		return false, err // Don't mark - allow retry
+		return false, err // Don't mark - the consumer retries, then dead-letters
 	}
 
 	return true, nil // Success - mark message
@@ -0,0 +1,258 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+// DeadLetterSuffix is appended to a topic to name its dead-letter topic
+const DeadLetterSuffix = ".dlq"
+
+// Headers added to dead-lettered messages, next to the original headers
+const (
+	HeaderDLQError     = "dlq-error"              // Error from the last attempt
+	HeaderDLQTopic     = "dlq-original-topic"     // Topic the message was consumed from
+	HeaderDLQPartition = "dlq-original-partition" // Partition the message was consumed from
+	HeaderDLQOffset    = "dlq-original-offset"    // Offset the message was consumed at
+	HeaderDLQAttempts  = "dlq-attempts"           // Attempts made before giving up
+	HeaderDLQFailedAt  = "dlq-failed-at"          // RFC 3339 time of the last attempt
+)
+
+// DeadLetterTopic returns the dead-letter topic for topic
+func DeadLetterTopic(topic string) string {
+	return topic + DeadLetterSuffix
+}
+
+// deadLetterMessage copies a failed message to the dead-letter topic, recording why it failed
+func deadLetterMessage(message *sarama.ConsumerMessage, topic string, err error, attempts int) *sarama.ProducerMessage {
+	headers := make([]sarama.RecordHeader, 0, len(message.Headers)+6)
+	for _, header := range message.Headers {
+		if header != nil && !isDeadLetterHeader(string(header.Key)) {
+			headers = append(headers, *header)
+		}
+	}
+	headers = append(headers,
+		sarama.RecordHeader{Key: []byte(HeaderDLQError), Value: []byte(err.Error())},
+		sarama.RecordHeader{Key: []byte(HeaderDLQTopic), Value: []byte(message.Topic)},
+		sarama.RecordHeader{Key: []byte(HeaderDLQPartition), Value: []byte(strconv.Itoa(int(message.Partition)))},
+		sarama.RecordHeader{Key: []byte(HeaderDLQOffset), Value: []byte(strconv.FormatInt(message.Offset, 10))},
+		sarama.RecordHeader{Key: []byte(HeaderDLQAttempts), Value: []byte(strconv.Itoa(attempts))},
+		sarama.RecordHeader{Key: []byte(HeaderDLQFailedAt), Value: []byte(time.Now().UTC().Format(time.RFC3339))},
+	)
+
+	dlqMessage := &sarama.ProducerMessage{
+		Topic:   topic,
+		Value:   sarama.ByteEncoder(message.Value),
+		Headers: headers,
+	}
+	if message.Key != nil {
+		dlqMessage.Key = sarama.ByteEncoder(message.Key)
+	}
+	return dlqMessage
+}
+
+// isDeadLetterHeader reports whether a header was added by dead-lettering
+func isDeadLetterHeader(key string) bool {
+	return strings.HasPrefix(key, "dlq-")
+}
+
+// ReplayConfig selects which dead-lettered messages ReplayDeadLetters sends back
+type ReplayConfig struct {
+	Brokers []string
+	Topic   string // Dead-letter topic to read
+	Target  string // Topic to replay to; empty uses each message's dlq-original-topic header
+	// GroupID records how far the dead-letter topic has been replayed, so
+	// messages are replayed once. Dry runs don't record anything.
+	GroupID string
+	Limit   int  // Stop after this many messages (0 replays everything)
+	DryRun  bool // Only report the messages that would be replayed
+	// OnMessage, when set, is called for every message before it is replayed
+	OnMessage func(ReplayedMessage)
+}
+
+// ReplayedMessage describes one dead-lettered message
+type ReplayedMessage struct {
+	Partition int32
+	Offset    int64
+	Key       string
+	Target    string
+	Error     string // dlq-error header
+	Attempts  string // dlq-attempts header
+	FailedAt  string // dlq-failed-at header
+}
+
+// ReplayDeadLetters republishes the messages currently in a dead-letter topic
+// to their source topic, without the dead-letter headers. Messages added
+// while it runs are left for the next replay. It returns how many messages
+// were replayed (or, in a dry run, would have been).
+func ReplayDeadLetters(ctx context.Context, config ReplayConfig) (int, error) {
+	if config.Topic == "" {
+		return 0, fmt.Errorf("no dead-letter topic")
+	}
+	if config.GroupID == "" {
+		config.GroupID = "dlq-replay"
+	}
+
+	saramaConfig := NewProducerConfig(ProducerConfig{ClientID: "dlq-replay"})
+	client, err := sarama.NewClient(config.Brokers, saramaConfig)
+	if err != nil {
+		return 0, fmt.Errorf("failed to connect to Kafka: %w", err)
+	}
+	defer client.Close()
+
+	partitions, err := client.Partitions(config.Topic)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list partitions of %s: %w", config.Topic, err)
+	}
+
+	consumer, err := sarama.NewConsumerFromClient(client)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create consumer: %w", err)
+	}
+	defer consumer.Close()
+
+	producer, err := sarama.NewSyncProducerFromClient(client)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create producer: %w", err)
+	}
+	defer producer.Close()
+
+	offsets, err := sarama.NewOffsetManagerFromClient(config.GroupID, client)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create offset manager: %w", err)
+	}
+	defer offsets.Close()
+
+	replayed := 0
+	for _, partition := range partitions {
+		if config.Limit > 0 && replayed >= config.Limit {
+			break
+		}
+		n, err := replayPartition(ctx, config, client, consumer, producer, offsets, partition, config.Limit-replayed)
+		replayed += n
+		if err != nil {
+			return replayed, fmt.Errorf("partition %d: %w", partition, err)
+		}
+	}
+	return replayed, nil
+}
+
+// replayPartition replays one partition of the dead-letter topic up to its
+// current high water mark. A limit of zero or less means no limit.
+func replayPartition(ctx context.Context, config ReplayConfig, client sarama.Client, consumer sarama.Consumer,
+	producer sarama.SyncProducer, offsets sarama.OffsetManager, partition int32, limit int) (int, error) {
+	newest, err := client.GetOffset(config.Topic, partition, sarama.OffsetNewest)
+	if err != nil {
+		return 0, err
+	}
+	oldest, err := client.GetOffset(config.Topic, partition, sarama.OffsetOldest)
+	if err != nil {
+		return 0, err
+	}
+
+	tracker, err := offsets.ManagePartition(config.Topic, partition)
+	if err != nil {
+		return 0, err
+	}
+	defer tracker.Close()
+
+	next, _ := tracker.NextOffset()
+	if next < oldest {
+		next = oldest
+	}
+	if next >= newest {
+		return 0, nil
+	}
+
+	messages, err := consumer.ConsumePartition(config.Topic, partition, next)
+	if err != nil {
+		return 0, err
+	}
+	defer messages.Close()
+
+	replayed := 0
+	for {
+		var message *sarama.ConsumerMessage
+		select {
+		case message = <-messages.Messages():
+		case <-ctx.Done():
+			return replayed, ctx.Err()
+		}
+		if message == nil {
+			return replayed, nil
+		}
+
+		info := replayInfo(message, config.Target)
+		if config.OnMessage != nil {
+			config.OnMessage(info)
+		}
+		if !config.DryRun {
+			if info.Target == "" {
+				log.Printf("Skipping dead-lettered message at offset %d: no target topic", message.Offset)
+			} else if _, _, err := producer.SendMessage(replayMessage(message, info.Target)); err != nil {
+				return replayed, fmt.Errorf("failed to replay offset %d: %w", message.Offset, err)
+			}
+			tracker.MarkOffset(message.Offset+1, "")
+		}
+		replayed++
+
+		if message.Offset+1 >= newest || (limit > 0 && replayed >= limit) {
+			return replayed, nil
+		}
+	}
+}
+
+// replayInfo reads the dead-letter headers of a message
+func replayInfo(message *sarama.ConsumerMessage, target string) ReplayedMessage {
+	info := ReplayedMessage{
+		Partition: message.Partition,
+		Offset:    message.Offset,
+		Key:       string(message.Key),
+		Target:    target,
+	}
+	for _, header := range message.Headers {
+		if header == nil {
+			continue
+		}
+		value := string(header.Value)
+		switch string(header.Key) {
+		case HeaderDLQTopic:
+			if info.Target == "" {
+				info.Target = value
+			}
+		case HeaderDLQError:
+			info.Error = value
+		case HeaderDLQAttempts:
+			info.Attempts = value
+		case HeaderDLQFailedAt:
+			info.FailedAt = value
+		}
+	}
+	return info
+}
+
+// replayMessage copies a dead-lettered message back to target without the dead-letter headers
+func replayMessage(message *sarama.ConsumerMessage, target string) *sarama.ProducerMessage {
+	var headers []sarama.RecordHeader
+	for _, header := range message.Headers {
+		if header != nil && !isDeadLetterHeader(string(header.Key)) {
+			headers = append(headers, *header)
+		}
+	}
+
+	replay := &sarama.ProducerMessage{
+		Topic:   target,
+		Value:   sarama.ByteEncoder(message.Value),
+		Headers: headers,
+	}
+	if message.Key != nil {
+		replay.Key = sarama.ByteEncoder(message.Key)
+	}
+	return replay
+}
@@ -0,0 +1,65 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Envelope wraps a Kafka payload with its schema type and version, so
+// consumers can tell message kinds apart and upgrade older payloads. Messages
+// without an envelope are treated as version 0 of whatever type the consumer expects.
+type Envelope struct {
+	Type       string            `json:"type"`
+	Version    int               `json:"version"`
+	ID         string            `json:"id"`
+	ProducedAt time.Time         `json:"produced_at"`
+	Headers    map[string]string `json:"headers,omitempty"` // Trace headers, e.g. X-Request-ID
+	Payload    json.RawMessage   `json:"payload"`
+}
+
+// UpgradeFunc converts a payload from one schema version to the next
+type UpgradeFunc func(payload json.RawMessage) (json.RawMessage, error)
+
+// decodeEnvelope unwraps message. A message that isn't an envelope is returned
+// as the payload of an untyped version 0 envelope.
+func decodeEnvelope(message []byte) Envelope {
+	var envelope Envelope
+	if err := json.Unmarshal(message, &envelope); err == nil && envelope.Type != "" && len(envelope.Payload) > 0 {
+		return envelope
+	}
+	return Envelope{Payload: message}
+}
+
+// upgrade applies the hooks needed to bring a payload from version to target.
+// Versions without a hook are assumed to share the next version's shape.
+func upgrade(payload json.RawMessage, version, target int, upgrades map[int]UpgradeFunc) (json.RawMessage, error) {
+	for v := version; v < target; v++ {
+		hook, ok := upgrades[v]
+		if !ok {
+			continue
+		}
+		upgraded, err := hook(payload)
+		if err != nil {
+			return nil, fmt.Errorf("upgrade from version %d: %w", v, err)
+		}
+		payload = upgraded
+	}
+	return payload, nil
+}
+
+type envelopeKey struct{}
+
+// withEnvelope attaches the envelope of the message being handled to ctx
+func withEnvelope(ctx context.Context, envelope Envelope) context.Context {
+	envelope.Payload = nil
+	return context.WithValue(ctx, envelopeKey{}, envelope)
+}
+
+// EnvelopeFromContext returns the envelope of the message a handler is
+// processing, without its payload. ok is false outside TypedMessageHandler.
+func EnvelopeFromContext(ctx context.Context) (Envelope, bool) {
+	envelope, ok := ctx.Value(envelopeKey{}).(Envelope)
+	return envelope, ok
+}
@@ -0,0 +1,230 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/google/uuid"
+)
+
+// ContentTypeHeader is set on every message a Producer sends
+const ContentTypeHeader = "content-type"
+
+// ProducerConfig holds Kafka producer configuration
+type ProducerConfig struct {
+	Brokers  []string
+	Topic    string // Default topic; Message.Topic overrides it
+	ClientID string // Optional client ID reported to the brokers
+	// Async queues messages and sends them in the background instead of
+	// waiting for each acknowledgement. Outcomes go to OnDelivery.
+	Async bool
+	// OnDelivery is called once per message the brokers acknowledged or
+	// rejected (async mode only). Failures are logged when it is nil.
+	OnDelivery func(Delivery)
+	// MessageType, when set, wraps every value in an Envelope of this type
+	// and Version; the message headers are copied into the envelope
+	MessageType string
+	Version     int
+}
+
+// Message is a single record to publish
+type Message[T any] struct {
+	Topic   string // Empty uses the producer's default topic
+	Key     string // Messages with the same key land on the same partition, in order
+	Value   T
+	Headers map[string]string
+}
+
+// Delivery reports where a message was written, or why it wasn't
+type Delivery struct {
+	Topic     string
+	Key       string
+	Partition int32
+	Offset    int64
+	Err       error
+}
+
+// Producer publishes JSON-encoded messages of type T
+type Producer[T any] struct {
+	topic      string
+	envelope   Envelope // Type and version of enveloped messages; empty type sends bare values
+	sync       sarama.SyncProducer
+	async      sarama.AsyncProducer
+	onDelivery func(Delivery)
+	reported   sync.WaitGroup
+}
+
+// NewProducerConfig returns the Sarama settings producers use: an idempotent
+// producer that waits for all in-sync replicas, so retries never duplicate or
+// reorder messages within a partition
+func NewProducerConfig(config ProducerConfig) *sarama.Config {
+	saramaConfig := sarama.NewConfig()
+	saramaConfig.Version = sarama.V3_6_0_0
+	if config.ClientID != "" {
+		saramaConfig.ClientID = config.ClientID
+	}
+	saramaConfig.Producer.Idempotent = true
+	saramaConfig.Producer.RequiredAcks = sarama.WaitForAll
+	saramaConfig.Producer.Retry.Max = 5
+	saramaConfig.Net.MaxOpenRequests = 1
+	saramaConfig.Producer.Return.Successes = true
+	saramaConfig.Producer.Return.Errors = true
+	return saramaConfig
+}
+
+// NewProducer connects a producer to the brokers
+func NewProducer[T any](config ProducerConfig) (*Producer[T], error) {
+	saramaConfig := NewProducerConfig(config)
+
+	if config.Async {
+		producer, err := sarama.NewAsyncProducer(config.Brokers, saramaConfig)
+		if err != nil {
+			return nil, err
+		}
+		return NewAsyncProducerFrom[T](producer, config.Topic, config.OnDelivery).WithEnvelope(config.MessageType, config.Version), nil
+	}
+
+	producer, err := sarama.NewSyncProducer(config.Brokers, saramaConfig)
+	if err != nil {
+		return nil, err
+	}
+	return NewSyncProducerFrom[T](producer, config.Topic).WithEnvelope(config.MessageType, config.Version), nil
+}
+
+// WithEnvelope makes the producer wrap values in envelopes of the given type
+// and version (an empty type sends bare values). Call it before sending.
+func (p *Producer[T]) WithEnvelope(messageType string, version int) *Producer[T] {
+	p.envelope = Envelope{Type: messageType, Version: version}
+	return p
+}
+
+// NewSyncProducerFrom wraps an existing Sarama sync producer (e.g. a mock)
+func NewSyncProducerFrom[T any](producer sarama.SyncProducer, topic string) *Producer[T] {
+	return &Producer[T]{topic: topic, sync: producer}
+}
+
+// NewAsyncProducerFrom wraps an existing Sarama async producer (e.g. a mock).
+// The producer must return successes and errors.
+func NewAsyncProducerFrom[T any](producer sarama.AsyncProducer, topic string, onDelivery func(Delivery)) *Producer[T] {
+	p := &Producer[T]{topic: topic, async: producer, onDelivery: onDelivery}
+	if p.onDelivery == nil {
+		p.onDelivery = logFailedDelivery
+	}
+
+	p.reported.Add(2)
+	go func() {
+		defer p.reported.Done()
+		for msg := range producer.Successes() {
+			p.onDelivery(delivery(msg, nil))
+		}
+	}()
+	go func() {
+		defer p.reported.Done()
+		for perr := range producer.Errors() {
+			p.onDelivery(delivery(perr.Msg, perr.Err))
+		}
+	}()
+	return p
+}
+
+// Send publishes a message. In sync mode it blocks until the brokers
+// acknowledge the message and returns where it was written; in async mode it
+// returns once the message is queued, and the outcome goes to OnDelivery.
+func (p *Producer[T]) Send(ctx context.Context, msg Message[T]) (Delivery, error) {
+	producerMsg, err := p.encode(msg)
+	if err != nil {
+		return Delivery{}, err
+	}
+	if err := ctx.Err(); err != nil {
+		return Delivery{}, err
+	}
+
+	if p.async != nil {
+		select {
+		case p.async.Input() <- producerMsg:
+			return Delivery{Topic: producerMsg.Topic, Key: msg.Key, Partition: -1, Offset: -1}, nil
+		case <-ctx.Done():
+			return Delivery{}, ctx.Err()
+		}
+	}
+
+	partition, offset, err := p.sync.SendMessage(producerMsg)
+	result := Delivery{Topic: producerMsg.Topic, Key: msg.Key, Partition: partition, Offset: offset, Err: err}
+	if err != nil {
+		return result, fmt.Errorf("failed to publish to %s: %w", producerMsg.Topic, err)
+	}
+	return result, nil
+}
+
+// Close flushes queued messages and closes the producer. In async mode it
+// returns after the remaining deliveries have been reported.
+func (p *Producer[T]) Close() error {
+	if p.async != nil {
+		p.async.AsyncClose()
+		p.reported.Wait()
+		return nil
+	}
+	return p.sync.Close()
+}
+
+// encode builds the Sarama message for msg
+func (p *Producer[T]) encode(msg Message[T]) (*sarama.ProducerMessage, error) {
+	topic := msg.Topic
+	if topic == "" {
+		topic = p.topic
+	}
+	if topic == "" {
+		return nil, fmt.Errorf("no topic for message")
+	}
+
+	value, err := json.Marshal(msg.Value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal message: %w", err)
+	}
+	if p.envelope.Type != "" {
+		envelope := p.envelope
+		envelope.ID = uuid.NewString()
+		envelope.ProducedAt = time.Now()
+		envelope.Headers = msg.Headers
+		envelope.Payload = value
+		if value, err = json.Marshal(envelope); err != nil {
+			return nil, fmt.Errorf("failed to marshal envelope: %w", err)
+		}
+	}
+
+	headers := []sarama.RecordHeader{{Key: []byte(ContentTypeHeader), Value: []byte("application/json")}}
+	for key, value := range msg.Headers {
+		headers = append(headers, sarama.RecordHeader{Key: []byte(key), Value: []byte(value)})
+	}
+
+	producerMsg := &sarama.ProducerMessage{
+		Topic:   topic,
+		Value:   sarama.ByteEncoder(value),
+		Headers: headers,
+	}
+	if msg.Key != "" {
+		producerMsg.Key = sarama.StringEncoder(msg.Key)
+	}
+	return producerMsg, nil
+}
+
+// delivery converts a Sarama delivery report
+func delivery(msg *sarama.ProducerMessage, err error) Delivery {
+	result := Delivery{Topic: msg.Topic, Partition: msg.Partition, Offset: msg.Offset, Err: err}
+	if key, ok := msg.Key.(sarama.StringEncoder); ok {
+		result.Key = string(key)
+	}
+	return result
+}
+
+// logFailedDelivery is the default async delivery callback
+func logFailedDelivery(d Delivery) {
+	if d.Err != nil {
+		log.Printf("Failed to publish to %s (key %s): %v", d.Topic, d.Key, d.Err)
+	}
+}
@@ -0,0 +1,227 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
+)
+
+type testEvent struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// headerMap flattens a Sarama message's headers
+func headerMap(msg *sarama.ProducerMessage) map[string]string {
+	headers := make(map[string]string, len(msg.Headers))
+	for _, h := range msg.Headers {
+		headers[string(h.Key)] = string(h.Value)
+	}
+	return headers
+}
+
+func TestNewProducerConfigIsIdempotent(t *testing.T) {
+	config := NewProducerConfig(ProducerConfig{ClientID: "test-client"})
+
+	if !config.Producer.Idempotent {
+		t.Error("Producer.Idempotent = false, want true")
+	}
+	if config.Producer.RequiredAcks != sarama.WaitForAll {
+		t.Errorf("Producer.RequiredAcks = %v, want WaitForAll", config.Producer.RequiredAcks)
+	}
+	if config.Net.MaxOpenRequests != 1 {
+		t.Errorf("Net.MaxOpenRequests = %d, want 1", config.Net.MaxOpenRequests)
+	}
+	if config.Producer.Retry.Max < 1 {
+		t.Errorf("Producer.Retry.Max = %d, want at least 1", config.Producer.Retry.Max)
+	}
+	if !config.Producer.Return.Successes || !config.Producer.Return.Errors {
+		t.Error("Producer.Return.Successes and Errors must both be enabled")
+	}
+	if config.ClientID != "test-client" {
+		t.Errorf("ClientID = %q, want test-client", config.ClientID)
+	}
+	if err := config.Validate(); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+}
+
+func TestSyncSendWrapsValueInEnvelope(t *testing.T) {
+	mock := mocks.NewSyncProducer(t, NewProducerConfig(ProducerConfig{}))
+	mock.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(msg *sarama.ProducerMessage) error {
+		if msg.Topic != "events" {
+			return fmt.Errorf("topic = %q, want events", msg.Topic)
+		}
+		if key, _ := msg.Key.Encode(); string(key) != "story-1" {
+			return fmt.Errorf("key = %q, want story-1", key)
+		}
+
+		headers := headerMap(msg)
+		if headers[ContentTypeHeader] != "application/json" {
+			return fmt.Errorf("content-type header = %q", headers[ContentTypeHeader])
+		}
+		if headers["X-Request-ID"] != "req-1" {
+			return fmt.Errorf("X-Request-ID header = %q, want req-1", headers["X-Request-ID"])
+		}
+
+		value, _ := msg.Value.Encode()
+		var envelope Envelope
+		if err := json.Unmarshal(value, &envelope); err != nil {
+			return fmt.Errorf("value is not an envelope: %v", err)
+		}
+		if envelope.Type != "test.event" || envelope.Version != 2 {
+			return fmt.Errorf("envelope type/version = %s/%d, want test.event/2", envelope.Type, envelope.Version)
+		}
+		if envelope.ID == "" || envelope.ProducedAt.IsZero() {
+			return fmt.Errorf("envelope ID and ProducedAt must be set")
+		}
+		if envelope.Headers["X-Request-ID"] != "req-1" {
+			return fmt.Errorf("envelope headers = %v, want X-Request-ID copied", envelope.Headers)
+		}
+
+		var payload testEvent
+		if err := json.Unmarshal(envelope.Payload, &payload); err != nil {
+			return fmt.Errorf("payload: %v", err)
+		}
+		if payload != (testEvent{Name: "fetched", Count: 3}) {
+			return fmt.Errorf("payload = %+v", payload)
+		}
+		return nil
+	})
+
+	producer := NewSyncProducerFrom[testEvent](mock, "events").WithEnvelope("test.event", 2)
+	delivery, err := producer.Send(context.Background(), Message[testEvent]{
+		Key:     "story-1",
+		Value:   testEvent{Name: "fetched", Count: 3},
+		Headers: map[string]string{"X-Request-ID": "req-1"},
+	})
+	if err != nil {
+		t.Fatalf("Send() = %v", err)
+	}
+	if delivery.Topic != "events" || delivery.Key != "story-1" || delivery.Offset < 0 {
+		t.Errorf("delivery = %+v", delivery)
+	}
+	if err := producer.Close(); err != nil {
+		t.Errorf("Close() = %v", err)
+	}
+}
+
+func TestSyncSendBareValueToMessageTopic(t *testing.T) {
+	mock := mocks.NewSyncProducer(t, NewProducerConfig(ProducerConfig{}))
+	mock.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(msg *sarama.ProducerMessage) error {
+		if msg.Topic != "override" {
+			return fmt.Errorf("topic = %q, want override", msg.Topic)
+		}
+		if msg.Key != nil {
+			return fmt.Errorf("key = %v, want none", msg.Key)
+		}
+		value, _ := msg.Value.Encode()
+		if string(value) != `{"name":"bare","count":1}` {
+			return fmt.Errorf("value = %s", value)
+		}
+		return nil
+	})
+
+	producer := NewSyncProducerFrom[testEvent](mock, "events")
+	if _, err := producer.Send(context.Background(), Message[testEvent]{
+		Topic: "override",
+		Value: testEvent{Name: "bare", Count: 1},
+	}); err != nil {
+		t.Fatalf("Send() = %v", err)
+	}
+	if err := producer.Close(); err != nil {
+		t.Errorf("Close() = %v", err)
+	}
+}
+
+func TestSyncSendReportsBrokerError(t *testing.T) {
+	mock := mocks.NewSyncProducer(t, NewProducerConfig(ProducerConfig{}))
+	mock.ExpectSendMessageAndFail(sarama.ErrNotEnoughReplicas)
+
+	producer := NewSyncProducerFrom[testEvent](mock, "events")
+	delivery, err := producer.Send(context.Background(), Message[testEvent]{Key: "k", Value: testEvent{Name: "x"}})
+	if !errors.Is(err, sarama.ErrNotEnoughReplicas) {
+		t.Fatalf("Send() = %v, want ErrNotEnoughReplicas", err)
+	}
+	if !errors.Is(delivery.Err, sarama.ErrNotEnoughReplicas) {
+		t.Errorf("delivery.Err = %v, want ErrNotEnoughReplicas", delivery.Err)
+	}
+	if err := producer.Close(); err != nil {
+		t.Errorf("Close() = %v", err)
+	}
+}
+
+func TestSendWithoutTopicOrCancelled(t *testing.T) {
+	mock := mocks.NewSyncProducer(t, NewProducerConfig(ProducerConfig{}))
+	producer := NewSyncProducerFrom[testEvent](mock, "")
+
+	if _, err := producer.Send(context.Background(), Message[testEvent]{}); err == nil {
+		t.Error("Send() without a topic succeeded")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := producer.Send(ctx, Message[testEvent]{Topic: "events"}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Send() with cancelled context = %v, want context.Canceled", err)
+	}
+	if err := producer.Close(); err != nil {
+		t.Errorf("Close() = %v", err)
+	}
+}
+
+func TestAsyncSendReportsDeliveries(t *testing.T) {
+	mock := mocks.NewAsyncProducer(t, NewProducerConfig(ProducerConfig{}))
+	mock.ExpectInputWithMessageCheckerFunctionAndSucceed(func(msg *sarama.ProducerMessage) error {
+		if headerMap(msg)["X-Request-ID"] != "req-ok" {
+			return fmt.Errorf("headers = %v", headerMap(msg))
+		}
+		return nil
+	})
+	mock.ExpectInputAndFail(sarama.ErrRequestTimedOut)
+
+	var (
+		mu         sync.Mutex
+		deliveries = map[string]Delivery{}
+	)
+	producer := NewAsyncProducerFrom[testEvent](mock, "events", func(d Delivery) {
+		mu.Lock()
+		defer mu.Unlock()
+		deliveries[d.Key] = d
+	}).WithEnvelope("test.event", 1)
+
+	for _, msg := range []Message[testEvent]{
+		{Key: "ok", Value: testEvent{Name: "a"}, Headers: map[string]string{"X-Request-ID": "req-ok"}},
+		{Key: "failed", Value: testEvent{Name: "b"}},
+	} {
+		queued, err := producer.Send(context.Background(), msg)
+		if err != nil {
+			t.Fatalf("Send(%s) = %v", msg.Key, err)
+		}
+		if queued.Partition != -1 || queued.Offset != -1 {
+			t.Errorf("queued delivery = %+v, want unknown partition and offset", queued)
+		}
+	}
+
+	// Close returns once every delivery has been reported
+	if err := producer.Close(); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(deliveries) != 2 {
+		t.Fatalf("got %d deliveries, want 2: %+v", len(deliveries), deliveries)
+	}
+	if ok := deliveries["ok"]; ok.Err != nil || ok.Topic != "events" {
+		t.Errorf("successful delivery = %+v", ok)
+	}
+	if failed := deliveries["failed"]; !errors.Is(failed.Err, sarama.ErrRequestTimedOut) {
+		t.Errorf("failed delivery = %+v, want ErrRequestTimedOut", failed)
+	}
+}
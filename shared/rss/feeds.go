@@ -2,26 +2,34 @@ package rss
 
 // FeedConfig represents the configuration for a single RSS feed
 type FeedConfig struct {
-	Name string `json:"name"`
-	URL  string `json:"url"`
+	Name     string `json:"name"`
+	URL      string `json:"url"`
+	Priority int    `json:"priority,omitempty"` // Higher-priority feeds are picked first for generation
+	Schedule string `json:"schedule,omitempty"` // Default cron schedule; empty uses the orchestrator's -cron
 }
 
 // FeedPresets maps friendly keys to RSS feed configurations
 var FeedPresets = map[string]FeedConfig{
 	"cna": {
-		Name: "Channel News Asia",
-		URL:  "https://www.channelnewsasia.com/api/v1/rss-outbound-feed?_format=xml",
+		Name:     "Channel News Asia",
+		URL:      "https://www.channelnewsasia.com/api/v1/rss-outbound-feed?_format=xml",
+		Priority: 2,
+		Schedule: "*/5 * * * *",
 	},
 	"st": {
-		Name: "Straits Times",
-		URL:  "https://www.straitstimes.com/news/singapore/rss.xml",
+		Name:     "Straits Times",
+		URL:      "https://www.straitstimes.com/news/singapore/rss.xml",
+		Priority: 2,
+		Schedule: "*/5 * * * *",
 	},
 	"hn": {
 		Name: "Hacker News",
 		URL:  "https://hnrss.org/newest",
 	},
 	"tr": {
-		Name: "Technology Review",
-		URL:  "https://www.technologyreview.com/feed/",
+		Name:     "Technology Review",
+		URL:      "https://www.technologyreview.com/feed/",
+		Priority: 1,
+		Schedule: "0 * * * *",
 	},
 }
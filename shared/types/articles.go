@@ -0,0 +1,68 @@
+package types
+
+import "time"
+
+// Topics the ingestion service publishes article events to. Each topic carries
+// ArticleEvent messages keyed by article ID.
+const (
+	// TopicArticleFetched receives every article a feed fetch returns,
+	// including articles whose content extraction failed
+	TopicArticleFetched = "article.fetched"
+	// TopicArticleNew receives articles deduplication found to be new
+	TopicArticleNew = "article.new"
+	// TopicArticleDuplicate receives articles deduplication matched to an
+	// article it has already seen
+	TopicArticleDuplicate = "article.duplicate"
+	// TopicIngestionJobs receives finished ingestion jobs, keyed by job ID
+	TopicIngestionJobs = "ingestion-jobs"
+)
+
+// ArticleEventVersion is the current ArticleEvent schema version. Consumers
+// should ignore fields they don't know and reject versions newer than they
+// understand.
+const ArticleEventVersion = 1
+
+// ArticleEventType identifies an article event; it matches the event's topic
+type ArticleEventType string
+
+const (
+	ArticleFetched   ArticleEventType = TopicArticleFetched
+	ArticleNew       ArticleEventType = TopicArticleNew
+	ArticleDuplicate ArticleEventType = TopicArticleDuplicate
+)
+
+// ArticlePayload is the article carried by an ArticleEvent. It omits the HTML
+// content; ContentText is the extracted plain text.
+type ArticlePayload struct {
+	ID              string    `json:"id"`
+	Title           string    `json:"title"`
+	URL             string    `json:"url"`
+	PublishedAt     time.Time `json:"published_at"`
+	FetchedAt       time.Time `json:"fetched_at"`
+	Summary         string    `json:"summary,omitempty"`
+	Author          string    `json:"author,omitempty"`
+	Categories      []string  `json:"categories,omitempty"`
+	Excerpt         string    `json:"excerpt,omitempty"`
+	ImageURL        string    `json:"image_url,omitempty"`
+	ContentText     string    `json:"content_text,omitempty"`
+	ExtractionError string    `json:"extraction_error,omitempty"`
+}
+
+// ArticleEvent is a message on one of the article topics
+type ArticleEvent struct {
+	EventID    string           `json:"event_id"`
+	Type       ArticleEventType `json:"type"`
+	Version    int              `json:"version"`
+	ProducedAt time.Time        `json:"produced_at"`
+	RequestID  string           `json:"request_id,omitempty"`  // Request that caused the event
+	JobID      string           `json:"job_id,omitempty"`      // Set for events from ingestion jobs
+	FeedPreset string           `json:"feed_preset,omitempty"` // Set for events from feed fetches
+	Namespace  string           `json:"namespace,omitempty"`   // Dedup namespace (new/duplicate events)
+	Article    ArticlePayload   `json:"article"`
+
+	// Deduplication outcome (new/duplicate events)
+	IsExactDuplicate bool    `json:"is_exact_duplicate,omitempty"`
+	MatchingID       string  `json:"matching_id,omitempty"`
+	SimilarityScore  float32 `json:"similarity_score,omitempty"`
+	PresignedURL     string  `json:"presigned_url,omitempty"` // New articles stored in S3
+}
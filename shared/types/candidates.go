@@ -0,0 +1,69 @@
+package types
+
+import "time"
+
+// CandidateStatus is the editorial decision on a generation candidate
+type CandidateStatus string
+
+const (
+	CandidatePending      CandidateStatus = "pending"
+	CandidateApproved     CandidateStatus = "approved"
+	CandidateAutoApproved CandidateStatus = "auto_approved"
+	CandidateRejected     CandidateStatus = "rejected"
+	CandidateExpired      CandidateStatus = "expired"
+)
+
+// IsDecided reports whether the candidate is no longer waiting for a decision
+func (s CandidateStatus) IsDecided() bool {
+	return s != CandidatePending
+}
+
+// IsApproved reports whether the candidate may be sent to generation
+func (s CandidateStatus) IsApproved() bool {
+	return s == CandidateApproved || s == CandidateAutoApproved
+}
+
+// Candidate is a new story waiting for (or given) editorial approval before generation
+type Candidate struct {
+	ID          string          `json:"id"`
+	RunID       string          `json:"run_id"`
+	ArticleID   string          `json:"article_id"`
+	Title       string          `json:"title"`
+	URL         string          `json:"url"`
+	Excerpt     string          `json:"excerpt,omitempty"`
+	Feed        string          `json:"feed,omitempty"`
+	SourceCount int             `json:"source_count"`
+	Explanation string          `json:"explanation"` // Why deduplication considered the story new
+	Status      CandidateStatus `json:"status"`
+	Reason      string          `json:"reason,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	DecidedAt   *time.Time      `json:"decided_at,omitempty"`
+}
+
+// CandidateDecision is the body for POST /api/candidates/{id}/approve|reject
+type CandidateDecision struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+// CandidatesResponse is the JSON response for GET /api/candidates
+type CandidatesResponse struct {
+	Candidates []Candidate `json:"candidates"`
+}
+
+// Clone returns a copy of the candidate that doesn't share its decision time
+func (c Candidate) Clone() Candidate {
+	c.DecidedAt = cloneTime(c.DecidedAt)
+	return c
+}
+
+// CloneCandidates deep-copies a list of candidates, keeping nil as nil
+func CloneCandidates(candidates []Candidate) []Candidate {
+	if candidates == nil {
+		return nil
+	}
+	copied := make([]Candidate, len(candidates))
+	for i, c := range candidates {
+		copied[i] = c.Clone()
+	}
+	return copied
+}
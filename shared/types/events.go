@@ -0,0 +1,35 @@
+package types
+
+import "time"
+
+// EventType identifies what an orchestrator event carries
+type EventType string
+
+const (
+	// EventState is a run entering a new state
+	EventState EventType = "state"
+	// EventLog is a line added to the shared orchestrator log
+	EventLog EventType = "log"
+	// EventArticle is one article's deduplication outcome
+	EventArticle EventType = "article"
+	// EventGeneration is a generation request finishing (complete or failed),
+	// or the creation service reporting the generation's video
+	EventGeneration EventType = "generation"
+	// EventResync tells a resuming client that events were missed and it
+	// should reload GET /api/status before applying further events
+	EventResync EventType = "resync"
+)
+
+// Event is a single message on the orchestrator event stream (GET /api/events).
+// Seq increases by one per event, so clients resume with Last-Event-ID.
+type Event struct {
+	Seq        uint64            `json:"seq"`
+	Type       EventType         `json:"type"`
+	RunID      string            `json:"run_id,omitempty"`
+	Time       time.Time         `json:"time"`
+	State      State             `json:"state,omitempty"`
+	Error      string            `json:"error,omitempty"`
+	Log        *LogEntry         `json:"log,omitempty"`
+	Article    *RunArticleResult `json:"article,omitempty"`
+	Generation *GenerationRecord `json:"generation,omitempty"`
+}
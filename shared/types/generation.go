@@ -0,0 +1,110 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// GenerationResultType is the envelope type of generation results on Kafka
+const GenerationResultType = "generation.result"
+
+// GenerationResultVersion is the current GenerationResult schema version.
+// Version 0 is the unversioned payload, whose subtitle entries had no fixed schema.
+const GenerationResultVersion = 1
+
+// GenerationStatusSuccess is the status the generation service reports for a usable result
+const GenerationStatusSuccess = "success"
+
+// SubtitleTimestamp is a subtitle word or phrase and when it is spoken, in seconds
+type SubtitleTimestamp struct {
+	Text  string  `json:"text"`
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+}
+
+// GenerationResult is the generation service's output for one request: the
+// voiceover script, its subtitle timing and the resources shown alongside it.
+// The orchestrator tracks it and the creation service renders it.
+type GenerationResult struct {
+	UUID               string                 `json:"uuid"`
+	Voiceover          string                 `json:"voiceover"`
+	SubtitleTimestamps []SubtitleTimestamp    `json:"subtitle_timestamps"`
+	ResourceTimestamps map[string]interface{} `json:"resource_timestamps"`
+	Status             string                 `json:"status"`
+	Error              *string                `json:"error,omitempty"`
+	Timings            map[string]float64     `json:"timings,omitempty"`
+
+	// Passed through from the generation request when it carried them
+	Title       string   `json:"title,omitempty"`
+	SourceURL   string   `json:"source_url,omitempty"`
+	ArticleURLs []string `json:"article_urls,omitempty"`
+}
+
+// Failed reports whether the generation service could not produce a result
+func (p *GenerationResult) Failed() bool {
+	return p.Status != GenerationStatusSuccess || (p.Error != nil && *p.Error != "")
+}
+
+// FailureReason describes why a generation failed
+func (p *GenerationResult) FailureReason() string {
+	if p.Error != nil && *p.Error != "" {
+		return *p.Error
+	}
+	return fmt.Sprintf("generation status %q", p.Status)
+}
+
+// UpgradeGenerationResultV0 converts an unversioned generation result to
+// version 1. Unversioned subtitle entries may name the text "word" and give
+// start and end times as strings.
+func UpgradeGenerationResultV0(payload json.RawMessage) (json.RawMessage, error) {
+	var result map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &result); err != nil {
+		return nil, err
+	}
+
+	raw, ok := result["subtitle_timestamps"]
+	if !ok || string(raw) == "null" {
+		return payload, nil
+	}
+	var entries []map[string]interface{}
+	err := json.Unmarshal(raw, &entries)
+	if err != nil {
+		return nil, fmt.Errorf("subtitle_timestamps: %w", err)
+	}
+
+	subtitles := make([]SubtitleTimestamp, 0, len(entries))
+	for i, entry := range entries {
+		text, _ := entry["text"].(string)
+		if text == "" {
+			text, _ = entry["word"].(string)
+		}
+		start, err := seconds(entry["start"])
+		if err != nil {
+			return nil, fmt.Errorf("subtitle_timestamps[%d].start: %w", i, err)
+		}
+		end, err := seconds(entry["end"])
+		if err != nil {
+			return nil, fmt.Errorf("subtitle_timestamps[%d].end: %w", i, err)
+		}
+		subtitles = append(subtitles, SubtitleTimestamp{Text: text, Start: start, End: end})
+	}
+
+	if result["subtitle_timestamps"], err = json.Marshal(subtitles); err != nil {
+		return nil, err
+	}
+	return json.Marshal(result)
+}
+
+// seconds reads a time given as a JSON number or numeric string
+func seconds(value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case string:
+		return strconv.ParseFloat(v, 64)
+	case nil:
+		return 0, nil
+	}
+	return 0, fmt.Errorf("unexpected %T", value)
+}
@@ -0,0 +1,5 @@
+package types
+
+// RequestIDHeader carries a request ID between services so their logs can be
+// correlated. Services reuse an incoming ID and generate one otherwise.
+const RequestIDHeader = "X-Request-ID"
@@ -6,14 +6,16 @@ import "time"
 type State string
 
 const (
-	StateIdle          State = "idle"
-	StateClearing      State = "clearing"
-	StateFetching      State = "fetching"
-	StateDeduplicating State = "deduplicating"
-	StateSending       State = "sending"
-	StateWaiting       State = "waiting"
-	StateComplete      State = "complete"
-	StateError         State = "error"
+	StateIdle             State = "idle"
+	StateClearing         State = "clearing"
+	StateFetching         State = "fetching"
+	StateDeduplicating    State = "deduplicating"
+	StateAwaitingApproval State = "awaiting_approval" // Candidates are queued for editorial approval
+	StateSending          State = "sending"
+	StateWaiting          State = "waiting"
+	StateComplete         State = "complete"
+	StateFailed           State = "failed" // Generation service reported a failure
+	StateError            State = "error"
 )
 
 // LogEntry represents a single log line with timestamp
@@ -22,25 +24,29 @@ type LogEntry struct {
 	Message   string    `json:"message"`
 }
 
-// WebhookPayload represents the generation service response
-type WebhookPayload struct {
-	UUID               string                   `json:"uuid"`
-	Voiceover          string                   `json:"voiceover"`
-	SubtitleTimestamps []map[string]interface{} `json:"subtitle_timestamps"`
-	ResourceTimestamps map[string]interface{}   `json:"resource_timestamps"`
-	Status             string                   `json:"status"`
-	Error              *string                  `json:"error,omitempty"`
-	Timings            map[string]float64       `json:"timings,omitempty"`
-}
+// WebhookPayload is the generation result as delivered to the orchestrator's
+// webhook and Kafka consumer
+type WebhookPayload = GenerationResult
 
-// StatusResponse is the JSON response for GET /api/status
+// StatusResponse is the JSON response for GET /api/status.
+// It describes a single run (the latest one unless a run ID was requested).
 type StatusResponse struct {
-	State          State           `json:"state"`
-	Logs           []LogEntry      `json:"logs"`
-	ArticleCount   int             `json:"article_count"`
-	NewCount       int             `json:"new_count"`
-	DuplicateCount int             `json:"duplicate_count"`
-	GenerationUUID string          `json:"generation_uuid,omitempty"`
-	WebhookPayload *WebhookPayload `json:"webhook_payload,omitempty"`
-	Error          string          `json:"error,omitempty"`
+	State          State              `json:"state"`
+	RunID          string             `json:"run_id,omitempty"`
+	Namespace      string             `json:"namespace,omitempty"`
+	Feeds          []string           `json:"feeds,omitempty"`
+	DryRun         bool               `json:"dry_run,omitempty"`
+	Logs           []LogEntry         `json:"logs"`
+	ArticleCount   int                `json:"article_count"`
+	SkippedFeeds   []string           `json:"skipped_feeds,omitempty"` // Feeds that couldn't be fetched
+	NewCount       int                `json:"new_count"`
+	DuplicateCount int                `json:"duplicate_count"`
+	GenerationUUID string             `json:"generation_uuid,omitempty"`
+	WebhookPayload *WebhookPayload    `json:"webhook_payload,omitempty"`
+	Generations    []GenerationRecord `json:"generations,omitempty"`
+	Candidates     []Candidate        `json:"candidates,omitempty"`
+	Preview        []Candidate        `json:"preview,omitempty"` // Stories a dry run would have sent to generation
+	Error          string             `json:"error,omitempty"`
+	ActiveRuns     []RunRecord        `json:"active_runs,omitempty"` // Summaries of all in-flight runs
+	Schedules      []FeedSchedule     `json:"schedules,omitempty"`   // Per-feed schedules with next run times
 }
@@ -0,0 +1,146 @@
+package types
+
+import (
+	"slices"
+	"time"
+)
+
+// RunTrigger identifies what started a workflow run
+type RunTrigger string
+
+const (
+	TriggerCron    RunTrigger = "cron"
+	TriggerManual  RunTrigger = "manual"
+	TriggerRefresh RunTrigger = "refresh"
+)
+
+// StateTransition records when a run entered a state
+type StateTransition struct {
+	State State     `json:"state"`
+	At    time.Time `json:"at"`
+}
+
+// RunArticleResult is the per-article deduplication outcome kept in run history
+type RunArticleResult struct {
+	ArticleID        string  `json:"article_id"`
+	Title            string  `json:"title"`
+	URL              string  `json:"url"`
+	Feed             string  `json:"feed,omitempty"`
+	Status           string  `json:"status"` // "new", "duplicate", "failed", "error"
+	IsExactDuplicate bool    `json:"is_exact_duplicate,omitempty"`
+	MatchingID       string  `json:"matching_id,omitempty"`
+	SimilarityScore  float32 `json:"similarity_score,omitempty"`
+	Error            string  `json:"error,omitempty"`
+}
+
+// GenerationRecord tracks one generation request (one video) within a run
+type GenerationRecord struct {
+	UUID           string          `json:"uuid"`
+	PreviousUUIDs  []string        `json:"previous_uuids,omitempty"` // Other attempts (watchdog retries); the first result for any of them is accepted
+	ArticleID      string          `json:"article_id"`
+	Title          string          `json:"title"`
+	URL            string          `json:"url"`
+	Feed           string          `json:"feed,omitempty"`
+	SourceCount    int             `json:"source_count"` // Articles in this run that cover the same story
+	State          State           `json:"state"`        // "waiting", "complete" or "failed"
+	Attempts       int             `json:"attempts"`
+	Error          string          `json:"error,omitempty"`
+	WebhookPayload *WebhookPayload `json:"webhook_payload,omitempty"`
+	Video          *VideoResult    `json:"video,omitempty"` // The creation service's outcome, once it reports one
+	SentAt         time.Time       `json:"sent_at"`
+	FinishedAt     *time.Time      `json:"finished_at,omitempty"`
+}
+
+// RunRecord is a single workflow run as persisted in the orchestrator's run history
+type RunRecord struct {
+	ID             string             `json:"id"`
+	Trigger        RunTrigger         `json:"trigger"`
+	Feeds          []string           `json:"feeds,omitempty"`
+	Namespace      string             `json:"namespace,omitempty"`
+	DryRun         bool               `json:"dry_run,omitempty"` // Checked dedup only; nothing was written or sent
+	State          State              `json:"state"`
+	ArticleCount   int                `json:"article_count"`
+	SkippedFeeds   []string           `json:"skipped_feeds,omitempty"` // Feeds that couldn't be fetched; the run went on without them
+	NewCount       int                `json:"new_count"`
+	DuplicateCount int                `json:"duplicate_count"`
+	Articles       []RunArticleResult `json:"articles,omitempty"`
+	Candidates     []Candidate        `json:"candidates,omitempty"`
+	Preview        []Candidate        `json:"preview,omitempty"` // Stories a dry run would have sent to generation
+	Generations    []GenerationRecord `json:"generations,omitempty"`
+	GenerationUUID string             `json:"generation_uuid,omitempty"` // Most recently sent generation
+	WebhookPayload *WebhookPayload    `json:"webhook_payload,omitempty"` // Most recently received result
+	Error          string             `json:"error,omitempty"`
+	Transitions    []StateTransition  `json:"transitions,omitempty"`
+	StartedAt      time.Time          `json:"started_at"`
+	UpdatedAt      time.Time          `json:"updated_at"`
+	FinishedAt     *time.Time         `json:"finished_at,omitempty"`
+}
+
+// Summary returns a copy of the record without the per-article and payload details,
+// suitable for listings.
+func (r RunRecord) Summary() RunRecord {
+	r.Articles = nil
+	r.Candidates = nil
+	r.Preview = nil
+	r.WebhookPayload = nil
+	r.Transitions = nil
+
+	if r.Generations != nil {
+		generations := make([]GenerationRecord, len(r.Generations))
+		for i, g := range r.Generations {
+			g.WebhookPayload = nil
+			generations[i] = g
+		}
+		r.Generations = generations
+	}
+	return r
+}
+
+// Clone returns a deep copy of the record that stays valid while the run keeps
+// changing. Webhook payloads and video results are never modified once
+// recorded, so they are shared.
+func (r RunRecord) Clone() RunRecord {
+	r.Feeds = slices.Clone(r.Feeds)
+	r.SkippedFeeds = slices.Clone(r.SkippedFeeds)
+	r.Articles = slices.Clone(r.Articles)
+	r.Candidates = CloneCandidates(r.Candidates)
+	r.Preview = CloneCandidates(r.Preview)
+	r.Transitions = slices.Clone(r.Transitions)
+	r.FinishedAt = cloneTime(r.FinishedAt)
+
+	r.Generations = CloneGenerations(r.Generations)
+	return r
+}
+
+// CloneGenerations deep-copies a list of generation records, keeping nil as nil
+func CloneGenerations(generations []GenerationRecord) []GenerationRecord {
+	if generations == nil {
+		return nil
+	}
+	copied := make([]GenerationRecord, len(generations))
+	for i, g := range generations {
+		copied[i] = g.Clone()
+	}
+	return copied
+}
+
+// Clone returns a deep copy of the generation record
+func (g GenerationRecord) Clone() GenerationRecord {
+	g.PreviousUUIDs = slices.Clone(g.PreviousUUIDs)
+	g.FinishedAt = cloneTime(g.FinishedAt)
+	return g
+}
+
+// cloneTime copies an optional timestamp
+func cloneTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	copied := *t
+	return &copied
+}
+
+// IsTerminal reports whether a run in this state has finished
+func (s State) IsTerminal() bool {
+	return s == StateComplete || s == StateFailed || s == StateError
+}
@@ -0,0 +1,69 @@
+package types
+
+import (
+	"fmt"
+	"time"
+)
+
+// QuietHours is a daily window in which scheduled runs are skipped. Start and
+// End are "HH:MM"; a window whose End is before its Start wraps past midnight.
+type QuietHours struct {
+	Start    string `json:"start"`
+	End      string `json:"end"`
+	Timezone string `json:"timezone,omitempty"` // IANA name; empty uses the orchestrator's local time
+}
+
+// Contains reports whether t falls inside the quiet window
+func (q QuietHours) Contains(t time.Time) (bool, error) {
+	start, err := parseClock(q.Start)
+	if err != nil {
+		return false, fmt.Errorf("quiet hours start: %w", err)
+	}
+	end, err := parseClock(q.End)
+	if err != nil {
+		return false, fmt.Errorf("quiet hours end: %w", err)
+	}
+	if q.Timezone != "" {
+		loc, err := time.LoadLocation(q.Timezone)
+		if err != nil {
+			return false, fmt.Errorf("quiet hours timezone: %w", err)
+		}
+		t = t.In(loc)
+	}
+
+	now := t.Hour()*60 + t.Minute()
+	if start <= end {
+		return now >= start && now < end, nil
+	}
+	return now >= start || now < end, nil
+}
+
+// Validate checks the window's times and timezone
+func (q QuietHours) Validate() error {
+	_, err := q.Contains(time.Now())
+	return err
+}
+
+// parseClock converts "HH:MM" to minutes after midnight
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q (use HH:MM)", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// FeedSchedule controls when the orchestrator starts runs for one feed
+type FeedSchedule struct {
+	Feed          string      `json:"feed"`
+	Cron          string      `json:"cron"` // Standard 5-field cron expression
+	Enabled       bool        `json:"enabled"`
+	QuietHours    *QuietHours `json:"quiet_hours,omitempty"`
+	JitterSeconds int         `json:"jitter_seconds,omitempty"` // Random delay of up to this long before each run
+	NextRun       *time.Time  `json:"next_run,omitempty"`       // Next run outside quiet hours; set by the orchestrator
+}
+
+// SchedulesResponse is the JSON body of GET and PUT /api/schedules
+type SchedulesResponse struct {
+	Schedules []FeedSchedule `json:"schedules"`
+}
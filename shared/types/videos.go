@@ -0,0 +1,41 @@
+package types
+
+import "time"
+
+// TopicVideoResults carries the creation service's VideoResult events, keyed by generation UUID
+const TopicVideoResults = "video-results"
+
+// VideoResultType is the envelope type of video results on Kafka
+const VideoResultType = "video.result"
+
+// VideoResultVersion is the current VideoResult schema version
+const VideoResultVersion = 1
+
+// VideoStatus is how a video's creation ended
+type VideoStatus string
+
+const (
+	VideoUploaded VideoStatus = "uploaded" // Rendered and published; VideoID and URL are set
+	VideoRendered VideoStatus = "rendered" // Rendered only, because uploads are disabled
+	VideoFailed   VideoStatus = "failed"   // Error says why
+)
+
+// VideoResult is the creation service's outcome for one generation result
+type VideoResult struct {
+	UUID       string      `json:"uuid"` // Generation UUID the video was rendered from
+	Status     VideoStatus `json:"status"`
+	VideoID    string      `json:"video_id,omitempty"`
+	URL        string      `json:"url,omitempty"`
+	OutputPath string      `json:"output_path,omitempty"`
+	Error      string      `json:"error,omitempty"`
+	Attempts   int         `json:"attempts"`
+	FinishedAt time.Time   `json:"finished_at"`
+}
+
+// YouTubeURL returns the public link of an uploaded Short
+func YouTubeURL(videoID string) string {
+	if videoID == "" {
+		return ""
+	}
+	return "https://youtube.com/shorts/" + videoID
+}